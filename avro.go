@@ -0,0 +1,1025 @@
+package jsonschema
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/buger/jsonparser"
+)
+
+// AvroWarning describes something ToAvro or FromAvro couldn't represent in the other format
+type AvroWarning struct {
+	// Pointer is the JSON Pointer to what couldn't be converted, in the schema being converted
+	Pointer string
+	Message string
+}
+
+func (w AvroWarning) String() string {
+	return fmt.Sprintf("#%s: %s", w.Pointer, w.Message)
+}
+
+// reAvroName matches valid Avro names, i.e. names of records, enums, fixed, fields and enum symbols
+var reAvroName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// avroPrimitives maps the primitive Avro types to JSON Schema types
+var avroPrimitives = map[string]string{
+	"null": "null", "boolean": "boolean", "int": "integer", "long": "integer",
+	"float": "number", "double": "number", "bytes": "string", "string": "string",
+}
+
+func newArrayNode(items ...*node) *node {
+	return &node{typ: jsonparser.Array, items: append([]*node{}, items...)}
+}
+
+func newNumberNode(n int64) *node {
+	return &node{typ: jsonparser.Number, raw: []byte(strconv.FormatInt(n, 10))}
+}
+
+// avroWriter converts a schema to Avro
+type avroWriter struct {
+	// names holds the names of the records and enums declared so far
+	names    map[*Schema]string
+	used     map[string]bool
+	warnings []AvroWarning
+}
+
+// ToAvro converts a schema to an Avro schema.
+// Objects with properties become records, where properties, that aren't required, are unions
+// with null, defaulting to null. Objects with only additionalProperties become maps, string enums
+// become enums, oneOf and anyOf become unions, and the properties of objects in allOf are merged.
+// The formats date, date-time, time, uuid and decimal become logical types.
+// The precision and scale of decimals are read from the precision and scale keywords.
+// Anything, that can't be represented in Avro, e.g. minLength or pattern, is returned as warnings.
+func ToAvro(schema *Schema) ([]byte, []AvroWarning, error) {
+	if schema == nil {
+		return nil, nil, errors.New("no schema supplied")
+	}
+
+	w := &avroWriter{names: map[*Schema]string{}, used: map[string]bool{}}
+
+	name := "Root"
+	if schema.Title != nil {
+		name = *schema.Title
+	}
+	n, err := w.convert(schema, "", name)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	out, err := n.MarshalJSON()
+	return out, w.warnings, err
+}
+
+func (w *avroWriter) warn(ptr, format string, args ...interface{}) {
+	w.warnings = append(w.warnings, AvroWarning{Pointer: ptr, Message: fmt.Sprintf(format, args...)})
+}
+
+// name returns a unique Avro name based on hint
+func (w *avroWriter) name(hint string) string {
+	words := strings.FieldsFunc(hint, func(r rune) bool {
+		return r > unicode.MaxASCII || !(unicode.IsLetter(r) || unicode.IsDigit(r))
+	})
+	for i, word := range words {
+		words[i] = strings.ToUpper(word[:1]) + word[1:]
+	}
+	name := strings.Join(words, "")
+	if name == "" {
+		name = "Type"
+	} else if name[0] >= '0' && name[0] <= '9' {
+		name = "N" + name
+	}
+
+	unique := name
+	for i := 2; w.used[unique]; i++ {
+		unique = name + strconv.Itoa(i)
+	}
+	w.used[unique] = true
+	return unique
+}
+
+func (w *avroWriter) convert(s *Schema, ptr, nameHint string) (*node, error) {
+	if s == nil {
+		w.warn(ptr, "missing schema, using string")
+		return newStringNode("string"), nil
+	}
+
+	if s.Ref != nil {
+		target, err := resolveSchema(s)
+		if err != nil {
+			return nil, err
+		}
+		if ref := *s.Ref.String; strings.Contains(ref, "/") {
+			nameHint = ref[strings.LastIndex(ref, "/")+1:]
+		}
+		s = target
+	}
+
+	// Records and enums are declared once, and referenced by name after that
+	if name, ok := w.names[s]; ok {
+		return newStringNode(name), nil
+	}
+
+	if s.boolean != nil {
+		if *s.boolean {
+			w.warn(ptr, "a schema accepting any value can't be represented, using string")
+			return newStringNode("string"), nil
+		}
+		w.warn(ptr, "a schema accepting no value can't be represented, using null")
+		return newStringNode("null"), nil
+	}
+
+	w.warnUnsupported(s, ptr)
+
+	for _, combinator := range []struct {
+		keyword string
+		schemas *Schemas
+	}{
+		{"oneOf", s.OneOf},
+		{"anyOf", s.AnyOf},
+	} {
+		if combinator.schemas == nil {
+			continue
+		}
+		if s.Type != nil || s.Properties != nil {
+			w.warn(ptr, "keywords next to %s are ignored", combinator.keyword)
+		}
+		types := []*node{}
+		for i, sub := range *combinator.schemas {
+			subPtr := fmt.Sprintf("%s/%s/%d", ptr, combinator.keyword, i)
+			typ, err := w.convert(sub, subPtr, fmt.Sprintf("%sOption%d", nameHint, i+1))
+			if err != nil {
+				return nil, err
+			}
+			types = append(types, typ)
+		}
+		return w.union(types, ptr+"/"+combinator.keyword), nil
+	}
+
+	types := jsonTypes(s)
+	nullable := false
+	avroTypes := []*node{}
+	for _, typ := range types {
+		if typ == "null" {
+			nullable = true
+			continue
+		}
+		avroType, err := w.convertType(s, typ, ptr, nameHint)
+		if err != nil {
+			return nil, err
+		}
+		avroTypes = append(avroTypes, avroType)
+	}
+
+	if len(avroTypes) == 0 {
+		if nullable {
+			return newStringNode("null"), nil
+		}
+		w.warn(ptr, "a schema without a type can't be represented, using string")
+		return newStringNode("string"), nil
+	}
+
+	if nullable {
+		avroTypes = append([]*node{newStringNode("null")}, avroTypes...)
+	}
+	return w.union(avroTypes, ptr), nil
+}
+
+// jsonTypes returns the types of s, from type or inferred from the keywords used
+func jsonTypes(s *Schema) []string {
+	if s.Type != nil && s.Type.String != nil {
+		return []string{*s.Type.String}
+	}
+	if s.Type != nil && s.Type.Strings != nil {
+		types := []string{}
+		for _, typ := range *s.Type.Strings {
+			types = append(types, *typ)
+		}
+		return types
+	}
+
+	switch {
+	case s.Properties != nil || s.AdditionalProperties != nil:
+		return []string{"object"}
+	case s.Items != nil:
+		return []string{"array"}
+	case s.Format != nil:
+		return []string{"string"}
+	}
+
+	// The types of the values of enum and const
+	values := []*Value{}
+	if s.Const != nil {
+		values = append(values, s.Const)
+	}
+	if s.Enum != nil {
+		values = append(values, *s.Enum...)
+	}
+	types := []string{}
+	seen := map[string]bool{}
+	for _, val := range values {
+		typ := val.valueType.String()
+		if val.valueType == Number && val.Number != nil && val.Number.IsInt() {
+			typ = "integer"
+		}
+		if !seen[typ] {
+			seen[typ] = true
+			types = append(types, typ)
+		}
+	}
+	if len(types) > 0 {
+		return types
+	}
+
+	// Objects merged with allOf
+	if s.AllOf != nil {
+		for _, sub := range *s.AllOf {
+			if target, err := resolveSchema(sub); err == nil && target.boolean == nil {
+				if t := jsonTypes(target); len(t) == 1 && t[0] == "object" {
+					return t
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// resolveSchema follows $refs until it finds a schema without one
+func resolveSchema(s *Schema) (*Schema, error) {
+	for i := 0; s != nil && s.Ref != nil; i++ {
+		if i > 100 {
+			return nil, errors.New("too many nested $refs")
+		}
+		target, err := s.ResolveRef(s.Ref)
+		if err != nil {
+			return nil, err
+		}
+		s = target
+	}
+	if s == nil {
+		return nil, errors.New("missing schema")
+	}
+	return s, nil
+}
+
+func (w *avroWriter) convertType(s *Schema, typ, ptr, nameHint string) (*node, error) {
+	switch typ {
+	case "boolean":
+		return newStringNode("boolean"), nil
+	case "integer":
+		return newStringNode("long"), nil
+	case "number":
+		return newStringNode("double"), nil
+	case "string":
+		return w.convertString(s, ptr, nameHint), nil
+	case "array":
+		return w.convertArray(s, ptr, nameHint)
+	case "object":
+		return w.convertObject(s, ptr, nameHint)
+	}
+	return nil, fmt.Errorf("unknown type at #%s: %s", ptr, typ)
+}
+
+func (w *avroWriter) convertString(s *Schema, ptr, nameHint string) *node {
+	symbols := []string{}
+	if s.Const != nil && s.Const.String != nil {
+		symbols = append(symbols, *s.Const.String)
+	} else if s.Enum != nil {
+		for _, val := range *s.Enum {
+			if val.String != nil {
+				symbols = append(symbols, *val.String)
+			}
+		}
+	}
+	if len(symbols) > 0 {
+		return w.convertEnum(s, symbols, ptr, nameHint)
+	}
+
+	if s.Format == nil {
+		return newStringNode("string")
+	}
+
+	logical := func(typ, logicalType string) *node {
+		n := newObjectNode()
+		n.set("type", newStringNode(typ))
+		n.set("logicalType", newStringNode(logicalType))
+		return n
+	}
+
+	switch *s.Format {
+	case "date":
+		return logical("int", "date")
+	case "date-time":
+		return logical("long", "timestamp-millis")
+	case "time":
+		return logical("int", "time-millis")
+	case "uuid":
+		return logical("string", "uuid")
+	case "decimal":
+		precision, errPrecision := s.GetUnknown("precision")
+		if errPrecision != nil || precision.Number == nil || !precision.Number.IsInt() {
+			w.warn(ptr+"/format", "decimal needs an integer precision keyword, using string")
+			return newStringNode("string")
+		}
+		n := logical("bytes", "decimal")
+		p, _ := precision.Number.Int64()
+		n.set("precision", newNumberNode(p))
+		if scale, err := s.GetUnknown("scale"); err == nil && scale.Number != nil && scale.Number.IsInt() {
+			sc, _ := scale.Number.Int64()
+			n.set("scale", newNumberNode(sc))
+		}
+		return n
+	}
+
+	w.warn(ptr+"/format", "the format %s can't be represented, using string", *s.Format)
+	return newStringNode("string")
+}
+
+func (w *avroWriter) convertEnum(s *Schema, symbols []string, ptr, nameHint string) *node {
+	for _, symbol := range symbols {
+		if !reAvroName.MatchString(symbol) {
+			w.warn(ptr+"/enum", "%q isn't a valid Avro enum symbol, using string", symbol)
+			return newStringNode("string")
+		}
+	}
+	if s.Enum != nil && len(symbols) != len(*s.Enum) {
+		w.warn(ptr+"/enum", "only the string values of the enum are kept")
+	}
+
+	name := w.name(nameHint)
+	w.names[s] = name
+
+	n := newObjectNode()
+	n.set("type", newStringNode("enum"))
+	n.set("name", newStringNode(name))
+	if s.Description != nil {
+		n.set("doc", newStringNode(*s.Description))
+	}
+	items := []*node{}
+	for _, symbol := range symbols {
+		items = append(items, newStringNode(symbol))
+	}
+	n.set("symbols", newArrayNode(items...))
+	return n
+}
+
+func (w *avroWriter) convertArray(s *Schema, ptr, nameHint string) (*node, error) {
+	n := newObjectNode()
+	n.set("type", newStringNode("array"))
+
+	switch {
+	case s.Items != nil && s.Items.Schema != nil:
+		items, err := w.convert(s.Items.Schema, ptr+"/items", nameHint+"Item")
+		if err != nil {
+			return nil, err
+		}
+		n.set("items", items)
+
+	case s.Items != nil && s.Items.Schemas != nil:
+		w.warn(ptr+"/items", "tuples can't be represented, using a union of the item types")
+		types := []*node{}
+		for i, item := range *s.Items.Schemas {
+			typ, err := w.convert(item, fmt.Sprintf("%s/items/%d", ptr, i), fmt.Sprintf("%sItem%d", nameHint, i+1))
+			if err != nil {
+				return nil, err
+			}
+			types = append(types, typ)
+		}
+		n.set("items", w.union(types, ptr+"/items"))
+
+	default:
+		w.warn(ptr, "an array without items can't be represented, using an array of strings")
+		n.set("items", newStringNode("string"))
+	}
+
+	return n, nil
+}
+
+func (w *avroWriter) convertObject(s *Schema, ptr, nameHint string) (*node, error) {
+	// Properties of objects in allOf are merged into the record
+	type source struct {
+		schema *Schema
+		ptr    string
+	}
+	sources := []source{{schema: s, ptr: ptr}}
+	if s.AllOf != nil {
+		for i, sub := range *s.AllOf {
+			subPtr := fmt.Sprintf("%s/allOf/%d", ptr, i)
+			target, err := resolveSchema(sub)
+			if err != nil {
+				return nil, err
+			}
+			if t := jsonTypes(target); target.boolean != nil || len(t) != 1 || t[0] != "object" {
+				w.warn(subPtr, "only objects can be merged from allOf")
+				continue
+			}
+			w.warnUnsupported(target, subPtr)
+			sources = append(sources, source{schema: target, ptr: subPtr})
+		}
+	}
+
+	hasProperties := false
+	for _, src := range sources {
+		hasProperties = hasProperties || src.schema.Properties != nil
+	}
+
+	if !hasProperties {
+		n := newObjectNode()
+		n.set("type", newStringNode("map"))
+		if s.AdditionalProperties != nil && s.AdditionalProperties.boolean == nil {
+			values, err := w.convert(s.AdditionalProperties, ptr+"/additionalProperties", nameHint+"Value")
+			if err != nil {
+				return nil, err
+			}
+			n.set("values", values)
+		} else {
+			w.warn(ptr, "an object without properties or additionalProperties can't be represented, using a map of strings")
+			n.set("values", newStringNode("string"))
+		}
+		return n, nil
+	}
+
+	name := w.name(nameHint)
+	w.names[s] = name
+
+	n := newObjectNode()
+	n.set("type", newStringNode("record"))
+	n.set("name", newStringNode(name))
+	if s.Description != nil {
+		n.set("doc", newStringNode(*s.Description))
+	}
+
+	required := map[string]bool{}
+	for _, src := range sources {
+		if src.schema.Required != nil {
+			for _, req := range *src.schema.Required {
+				required[*req] = true
+			}
+		}
+	}
+
+	fields := newArrayNode()
+	seen := map[string]bool{}
+	for _, src := range sources {
+		if src.schema.AdditionalProperties != nil && !isFalseSchema(src.schema.AdditionalProperties) {
+			w.warn(src.ptr+"/additionalProperties", "records can't have additional properties")
+		}
+		if src.schema.Properties == nil {
+			continue
+		}
+
+		for _, prop := range *src.schema.Properties {
+			if seen[prop.Name] {
+				continue
+			}
+			seen[prop.Name] = true
+
+			fieldPtr := src.ptr + "/properties/" + escapePointerToken(prop.Name)
+			field, err := w.convertField(prop, required[prop.Name], fieldPtr, name)
+			if err != nil {
+				return nil, err
+			}
+			fields.items = append(fields.items, field)
+		}
+	}
+	n.set("fields", fields)
+
+	return n, nil
+}
+
+func (w *avroWriter) convertField(prop *NamedProperty, required bool, ptr, recordName string) (*node, error) {
+	fieldName := prop.Name
+	if !reAvroName.MatchString(fieldName) {
+		fieldName = strings.Map(func(r rune) rune {
+			if r > unicode.MaxASCII || !(unicode.IsLetter(r) || unicode.IsDigit(r)) {
+				return '_'
+			}
+			return r
+		}, fieldName)
+		if fieldName == "" || (fieldName[0] >= '0' && fieldName[0] <= '9') {
+			fieldName = "_" + fieldName
+		}
+		w.warn(ptr, "%q isn't a valid Avro field name, renamed to %s", prop.Name, fieldName)
+	}
+
+	typ, err := w.convert(prop.Property, ptr, recordName+fieldHint(prop.Name))
+	if err != nil {
+		return nil, err
+	}
+
+	field := newObjectNode()
+	field.set("name", newStringNode(fieldName))
+	if prop.Property != nil && prop.Property.Description != nil {
+		field.set("doc", newStringNode(*prop.Property.Description))
+	}
+
+	var def *node
+	if prop.Property != nil && prop.Property.Default != nil {
+		b, err := prop.Property.Default.MarshalJSON()
+		if err == nil {
+			def, _ = parseNode(b)
+		}
+	}
+
+	// Optional properties are unions with null, with null as default.
+	// A default of another value must match the first type of the union, so null goes last.
+	if !required {
+		if def == nil || def.typ == jsonparser.Null {
+			typ = nullableUnion(typ, true)
+			def = &node{typ: jsonparser.Null, raw: []byte("null")}
+		} else {
+			typ = nullableUnion(typ, false)
+		}
+	}
+
+	field.set("type", typ)
+	if def != nil {
+		field.set("default", def)
+	}
+	return field, nil
+}
+
+// fieldHint returns a name hint for the type of a field
+func fieldHint(name string) string {
+	if name == "" {
+		return ""
+	}
+	return strings.ToUpper(name[:1]) + name[1:]
+}
+
+// nullableUnion adds null to a type, as the first or last type of a union
+func nullableUnion(typ *node, nullFirst bool) *node {
+	types := []*node{typ}
+	if typ.typ == jsonparser.Array {
+		types = []*node{}
+		for _, t := range typ.items {
+			if name, ok := t.str(); !ok || name != "null" {
+				types = append(types, t)
+			}
+		}
+	}
+	if nullFirst {
+		return newArrayNode(append([]*node{newStringNode("null")}, types...)...)
+	}
+	return newArrayNode(append(types, newStringNode("null"))...)
+}
+
+// union returns a union of the types, or the type itself, if there's only one.
+// Avro unions can't be nested or hold more than one type of each kind, except for named types.
+func (w *avroWriter) union(types []*node, ptr string) *node {
+	flat := []*node{}
+	for _, typ := range types {
+		if typ.typ == jsonparser.Array {
+			flat = append(flat, typ.items...)
+		} else {
+			flat = append(flat, typ)
+		}
+	}
+
+	kinds := map[string]*node{}
+	unique := []*node{}
+	for _, typ := range flat {
+		kind := avroKind(typ)
+		if existing, ok := kinds[kind]; ok {
+			a, _ := existing.MarshalJSON()
+			b, _ := typ.MarshalJSON()
+			if !bytes.Equal(a, b) {
+				w.warn(ptr, "a union can only hold one %s, %s is dropped", kind, b)
+			}
+			continue
+		}
+		kinds[kind] = typ
+		unique = append(unique, typ)
+	}
+
+	if len(unique) == 1 {
+		return unique[0]
+	}
+	return newArrayNode(unique...)
+}
+
+// avroKind returns the name of a named type, or the kind of an unnamed type
+func avroKind(typ *node) string {
+	if name, ok := typ.str(); ok {
+		return name
+	}
+	kind, _ := typ.get("type").str()
+	if kind == "record" || kind == "enum" || kind == "fixed" {
+		name, _ := typ.get("name").str()
+		return name
+	}
+	return kind
+}
+
+// isFalseSchema reports whether s is the false schema
+func isFalseSchema(s *Schema) bool {
+	return s.boolean != nil && !*s.boolean
+}
+
+// warnUnsupported warns about the keywords of s, that have no equivalent in Avro
+func (w *avroWriter) warnUnsupported(s *Schema, ptr string) {
+	for _, kw := range []struct {
+		keyword string
+		set     bool
+	}{
+		{"minLength", s.MinLength != nil},
+		{"maxLength", s.MaxLength != nil},
+		{"pattern", s.Pattern != nil},
+		{"minimum", s.Minimum != nil},
+		{"maximum", s.Maximum != nil},
+		{"exclusiveMinimum", s.ExclusiveMinimum != nil},
+		{"exclusiveMaximum", s.ExclusiveMaximum != nil},
+		{"multipleOf", s.MultipleOf != nil},
+		{"minItems", s.MinItems != nil},
+		{"maxItems", s.MaxItems != nil},
+		{"uniqueItems", s.UniqueItems != nil},
+		{"contains", s.Contains != nil},
+		{"minProperties", s.MinProperties != nil},
+		{"maxProperties", s.MaxProperties != nil},
+		{"patternProperties", s.PatternProperties != nil},
+		{"propertyNames", s.PropertyNames != nil},
+		{"dependencies", s.Dependencies != nil},
+		{"not", s.Not != nil},
+		{"if", s.If != nil},
+	} {
+		if kw.set {
+			w.warn(ptr+"/"+kw.keyword, "%s can't be represented and is dropped", kw.keyword)
+		}
+	}
+}
+
+// avroReader converts an Avro schema to a schema
+type avroReader struct {
+	// refs holds the $ref of each named type by its full name
+	refs     map[string]string
+	defs     *node
+	warnings []AvroWarning
+}
+
+// FromAvro converts an Avro schema to a (draft-07) schema.
+// Records become objects with required properties for fields without a default and no additional
+// properties, enums become string enums, maps become objects with additionalProperties and unions
+// become oneOf, or a list of types for unions of null and a primitive type.
+// The logical types date, timestamp-millis/micros, time-millis/micros, uuid and decimal become the
+// formats date, date-time, time, uuid and decimal, where the precision and scale of decimals are
+// kept as keywords of the same names. Named types, other than the root, become definitions.
+// Anything, that can't be represented in JSON Schema, e.g. aliases, is returned as warnings.
+// The returned schema has the decimal format registered, so decimal strings are validated.
+func FromAvro(avro []byte) (*Schema, []AvroWarning, error) {
+	root, err := parseNode(avro)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	r := &avroReader{refs: map[string]string{}, defs: newObjectNode()}
+	n, err := r.convert(root, "", "", true)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	out := newObjectNode()
+	out.set("$schema", newStringNode(Draft07.URI()))
+	for _, m := range n.members {
+		out.set(m.key, m.value)
+	}
+	if len(r.defs.members) > 0 {
+		out.set("definitions", r.defs)
+	}
+
+	b, err := out.MarshalJSON()
+	if err != nil {
+		return nil, nil, err
+	}
+	schema, err := New(b)
+	if err != nil {
+		return nil, r.warnings, err
+	}
+	schema.RegisterFormat("decimal", checkDecimal)
+	return schema, r.warnings, nil
+}
+
+var reDecimal = regexp.MustCompile(`^-?(?:0|[1-9][0-9]*)(?:\.[0-9]+)?$`)
+
+// checkDecimal checks a decimal number written as a string, e.g. 12.50, as used for Avro decimals
+func checkDecimal(value string) error {
+	if reDecimal.MatchString(value) {
+		return nil
+	}
+	return errors.New("value is not a valid decimal")
+}
+
+func (r *avroReader) warn(ptr, format string, args ...interface{}) {
+	r.warnings = append(r.warnings, AvroWarning{Pointer: ptr, Message: fmt.Sprintf(format, args...)})
+}
+
+// typeNode returns a schema with a single type
+func typeNode(typ string) *node {
+	n := newObjectNode()
+	n.set("type", newStringNode(typ))
+	return n
+}
+
+func (r *avroReader) convert(n *node, ptr, namespace string, root bool) (*node, error) {
+	switch n.typ {
+	case jsonparser.String:
+		name, _ := n.str()
+		return r.convertName(name, ptr, namespace)
+	case jsonparser.Array:
+		return r.convertUnion(n, ptr, namespace)
+	case jsonparser.Object:
+		return r.convertObject(n, ptr, namespace, root)
+	}
+	return nil, fmt.Errorf("invalid Avro schema at #%s", ptr)
+}
+
+// convertName converts a primitive type, or a reference to a named type
+func (r *avroReader) convertName(name, ptr, namespace string) (*node, error) {
+	if typ, ok := avroPrimitives[name]; ok {
+		n := typeNode(typ)
+		if name == "int" {
+			n.set("minimum", newNumberNode(-1<<31))
+			n.set("maximum", newNumberNode(1<<31-1))
+		}
+		return n, nil
+	}
+
+	fullName := name
+	if !strings.Contains(name, ".") && namespace != "" {
+		fullName = namespace + "." + name
+	}
+	for _, candidate := range []string{fullName, name} {
+		if ref, ok := r.refs[candidate]; ok {
+			n := newObjectNode()
+			n.set("$ref", newStringNode(ref))
+			return n, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown Avro type at #%s: %s", ptr, name)
+}
+
+func (r *avroReader) convertUnion(n *node, ptr, namespace string) (*node, error) {
+	types := []*node{}
+	nullable := false
+	for i, item := range n.items {
+		if name, ok := item.str(); ok && name == "null" {
+			nullable = true
+			continue
+		}
+		typ, err := r.convert(item, fmt.Sprintf("%s/%d", ptr, i), namespace, false)
+		if err != nil {
+			return nil, err
+		}
+		types = append(types, typ)
+	}
+
+	switch {
+	case len(types) == 0:
+		return typeNode("null"), nil
+
+	case len(types) == 1 && nullable && !types[0].has("$ref"):
+		// Null is added to the type of the other schema, and to its values, if it's an enum
+		typ := types[0]
+		if name, ok := typ.get("type").str(); ok {
+			typ.set("type", newArrayNode(newStringNode(name), newStringNode("null")))
+		}
+		if enum := typ.get("enum"); enum != nil {
+			enum.items = append(enum.items, &node{typ: jsonparser.Null, raw: []byte("null")})
+		}
+		return typ, nil
+
+	case len(types) == 1 && !nullable:
+		return types[0], nil
+	}
+
+	if nullable {
+		types = append([]*node{typeNode("null")}, types...)
+	}
+	union := newObjectNode()
+	union.set("oneOf", newArrayNode(types...))
+	return union, nil
+}
+
+func (r *avroReader) convertObject(n *node, ptr, namespace string, root bool) (*node, error) {
+	typ := n.get("type")
+	if typ == nil {
+		return nil, fmt.Errorf("missing type at #%s", ptr)
+	}
+
+	var schema *node
+	var err error
+	if logicalType, ok := n.get("logicalType").str(); ok {
+		schema = r.convertLogical(n, logicalType, ptr)
+	}
+
+	kind, _ := typ.str()
+	if schema == nil {
+		switch kind {
+		case "record", "error":
+			return r.convertRecord(n, ptr, namespace, root)
+		case "enum":
+			schema = r.convertEnum(n, ptr)
+		case "fixed":
+			schema = typeNode("string")
+			if size, err := jsonparser.ParseInt(n.get("size").raw); err == nil {
+				schema.set("minLength", newNumberNode(size))
+				schema.set("maxLength", newNumberNode(size))
+			}
+		case "array":
+			items := n.get("items")
+			if items == nil {
+				return nil, fmt.Errorf("missing items at #%s", ptr)
+			}
+			schema = typeNode("array")
+			itemSchema, err := r.convert(items, ptr+"/items", namespace, false)
+			if err != nil {
+				return nil, err
+			}
+			schema.set("items", itemSchema)
+		case "map":
+			values := n.get("values")
+			if values == nil {
+				return nil, fmt.Errorf("missing values at #%s", ptr)
+			}
+			schema = typeNode("object")
+			valueSchema, err := r.convert(values, ptr+"/values", namespace, false)
+			if err != nil {
+				return nil, err
+			}
+			schema.set("additionalProperties", valueSchema)
+		default:
+			// A primitive or named type, or a type written as a schema, e.g. {"type": {"type": "array", ...}}
+			schema, err = r.convert(typ, ptr+"/type", namespace, false)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if doc, ok := n.get("doc").str(); ok && !schema.has("$ref") {
+		schema.set("description", newStringNode(doc))
+	}
+
+	// Enums and fixed types are named, so they can be referenced after their declaration
+	if kind == "enum" || kind == "fixed" {
+		fullName, _, err := avroFullName(n, namespace, ptr)
+		if err != nil {
+			return nil, err
+		}
+		if aliases := n.get("aliases"); aliases != nil {
+			r.warn(ptr+"/aliases", "aliases can't be represented and are dropped")
+		}
+		return r.declare(fullName, schema, root), nil
+	}
+
+	return schema, nil
+}
+
+// declare adds a named type to the definitions and returns a $ref to it, unless it's the root
+func (r *avroReader) declare(fullName string, schema *node, root bool) *node {
+	if root {
+		r.refs[fullName] = "#"
+		return schema
+	}
+	r.refs[fullName] = "#/definitions/" + escapePointerToken(fullName)
+	r.defs.set(fullName, schema)
+	ref := newObjectNode()
+	ref.set("$ref", newStringNode(r.refs[fullName]))
+	return ref
+}
+
+// avroFullName returns the full name of a named type, and the namespace of the types in it
+func avroFullName(n *node, namespace, ptr string) (string, string, error) {
+	name, ok := n.get("name").str()
+	if !ok || name == "" {
+		return "", "", fmt.Errorf("missing name at #%s", ptr)
+	}
+	if ns, ok := n.get("namespace").str(); ok {
+		namespace = ns
+	}
+	if idx := strings.LastIndex(name, "."); idx >= 0 {
+		return name, name[:idx], nil
+	}
+	if namespace == "" {
+		return name, "", nil
+	}
+	return namespace + "." + name, namespace, nil
+}
+
+func (r *avroReader) convertRecord(n *node, ptr, namespace string, root bool) (*node, error) {
+	fullName, namespace, err := avroFullName(n, namespace, ptr)
+	if err != nil {
+		return nil, err
+	}
+	name, _ := n.get("name").str()
+	if idx := strings.LastIndex(name, "."); idx >= 0 {
+		name = name[idx+1:]
+	}
+
+	schema := typeNode("object")
+	schema.set("title", newStringNode(name))
+	if doc, ok := n.get("doc").str(); ok {
+		schema.set("description", newStringNode(doc))
+	}
+	if aliases := n.get("aliases"); aliases != nil {
+		r.warn(ptr+"/aliases", "aliases can't be represented and are dropped")
+	}
+
+	// The record is declared before its fields, as they may reference it
+	ref := r.declare(fullName, schema, root)
+
+	props := newObjectNode()
+	required := newArrayNode()
+	fields := n.get("fields")
+	if fields == nil || fields.typ != jsonparser.Array {
+		return nil, fmt.Errorf("missing fields at #%s", ptr)
+	}
+	for i, field := range fields.items {
+		fieldPtr := fmt.Sprintf("%s/fields/%d", ptr, i)
+		fieldName, ok := field.get("name").str()
+		if !ok {
+			return nil, fmt.Errorf("missing name at #%s", fieldPtr)
+		}
+		typ := field.get("type")
+		if typ == nil {
+			return nil, fmt.Errorf("missing type at #%s", fieldPtr)
+		}
+
+		prop, err := r.convert(typ, fieldPtr+"/type", namespace, false)
+		if err != nil {
+			return nil, err
+		}
+		if doc, ok := field.get("doc").str(); ok {
+			prop.set("description", newStringNode(doc))
+		}
+		if def := field.get("default"); def != nil {
+			prop.set("default", def)
+		} else {
+			required.items = append(required.items, newStringNode(fieldName))
+		}
+		if field.has("order") {
+			r.warn(fieldPtr+"/order", "the sort order of fields can't be represented and is dropped")
+		}
+		if field.has("aliases") {
+			r.warn(fieldPtr+"/aliases", "aliases can't be represented and are dropped")
+		}
+
+		props.set(fieldName, prop)
+	}
+
+	schema.set("properties", props)
+	if len(required.items) > 0 {
+		schema.set("required", required)
+	}
+	schema.set("additionalProperties", newBoolNode(false))
+
+	return ref, nil
+}
+
+func (r *avroReader) convertEnum(n *node, ptr string) *node {
+	schema := typeNode("string")
+	symbols := n.get("symbols")
+	if symbols != nil && symbols.typ == jsonparser.Array {
+		schema.set("enum", newArrayNode(symbols.items...))
+	}
+	if n.has("default") {
+		r.warn(ptr+"/default", "the default symbol of enums can't be represented and is dropped")
+	}
+	return schema
+}
+
+// convertLogical returns the schema of a logical type, or nil if the underlying type should be used
+func (r *avroReader) convertLogical(n *node, logicalType, ptr string) *node {
+	withFormat := func(format string) *node {
+		schema := typeNode("string")
+		schema.set("format", newStringNode(format))
+		return schema
+	}
+
+	switch logicalType {
+	case "date":
+		return withFormat("date")
+	case "timestamp-millis", "timestamp-micros":
+		return withFormat("date-time")
+	case "time-millis", "time-micros":
+		return withFormat("time")
+	case "uuid":
+		return withFormat("uuid")
+	case "decimal":
+		schema := withFormat("decimal")
+		if precision := n.get("precision"); precision != nil {
+			schema.set("precision", precision)
+		}
+		if scale := n.get("scale"); scale != nil {
+			schema.set("scale", scale)
+		}
+		return schema
+	}
+
+	r.warn(ptr+"/logicalType", "the logical type %s can't be represented, using the underlying type", logicalType)
+	return nil
+}
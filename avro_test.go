@@ -0,0 +1,306 @@
+package jsonschema
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestToAvro(t *testing.T) {
+	schema, err := NewFromString(`{
+		"title": "user",
+		"description": "A user",
+		"type": "object",
+		"required": ["id", "name", "role"],
+		"properties": {
+			"id": {"type": "string", "format": "uuid"},
+			"name": {"type": "string", "description": "Full name"},
+			"age": {"type": "integer"},
+			"role": {"type": "string", "enum": ["admin", "member"]},
+			"active": {"type": "boolean", "default": true},
+			"born": {"type": "string", "format": "date"},
+			"balance": {"type": "string", "format": "decimal", "precision": 10, "scale": 2},
+			"tags": {"type": "array", "items": {"type": "string"}},
+			"scores": {"type": "object", "additionalProperties": {"type": "number"}},
+			"address": {"$ref": "#/definitions/address"},
+			"nickname": {"type": ["string", "null"]}
+		},
+		"definitions": {
+			"address": {"type": "object", "required": ["street"], "properties": {"street": {"type": "string"}}}
+		}
+	}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out, warnings, err := ToAvro(schema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(warnings) > 0 {
+		t.Errorf("expected no warnings, got: %v", warnings)
+	}
+
+	expected := `{"type":"record","name":"User","doc":"A user","fields":[` +
+		`{"name":"id","type":{"type":"string","logicalType":"uuid"}},` +
+		`{"name":"name","doc":"Full name","type":"string"},` +
+		`{"name":"age","type":["null","long"],"default":null},` +
+		`{"name":"role","type":{"type":"enum","name":"UserRole","symbols":["admin","member"]}},` +
+		`{"name":"active","type":["boolean","null"],"default":true},` +
+		`{"name":"born","type":["null",{"type":"int","logicalType":"date"}],"default":null},` +
+		`{"name":"balance","type":["null",{"type":"bytes","logicalType":"decimal","precision":10,"scale":2}],"default":null},` +
+		`{"name":"tags","type":["null",{"type":"array","items":"string"}],"default":null},` +
+		`{"name":"scores","type":["null",{"type":"map","values":"double"}],"default":null},` +
+		`{"name":"address","type":["null",{"type":"record","name":"Address","fields":[{"name":"street","type":"string"}]}],"default":null},` +
+		`{"name":"nickname","type":["null","string"],"default":null}]}`
+	if string(out) != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, out)
+	}
+}
+
+func TestToAvroRecursive(t *testing.T) {
+	schema, err := NewFromString(`{
+		"title": "node",
+		"type": "object",
+		"properties": {"children": {"type": "array", "items": {"$ref": "#"}}}
+	}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out, _, err := ToAvro(schema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `{"type":"record","name":"Node","fields":[{"name":"children","type":["null",{"type":"array","items":"Node"}],"default":null}]}`
+	if string(out) != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, out)
+	}
+}
+
+func TestToAvroUnions(t *testing.T) {
+	schema, err := NewFromString(`{"oneOf": [{"type": "string"}, {"type": "integer"}, {"type": "null"}, {"type": "string", "maxLength": 3}]}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out, warnings, err := ToAvro(schema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := `["string","long","null"]`; string(out) != expected {
+		t.Errorf("expected: %s, got: %s", expected, out)
+	}
+	expectedWarnings := []string{"#/oneOf/3/maxLength: maxLength can't be represented and is dropped"}
+	if got := avroWarningStrings(warnings); strings.Join(got, "\n") != strings.Join(expectedWarnings, "\n") {
+		t.Errorf("expected warnings:\n%s\ngot:\n%s", strings.Join(expectedWarnings, "\n"), strings.Join(got, "\n"))
+	}
+}
+
+func TestToAvroWarnings(t *testing.T) {
+	schema, err := NewFromString(`{
+		"type": "object",
+		"properties": {
+			"code": {"type": "string", "pattern": "^[A-Z]+$"},
+			"first-name": {"type": "string"},
+			"email": {"type": "string", "format": "email"},
+			"level": {"enum": ["a b", "c"]},
+			"anything": true,
+			"pair": {"type": "array", "items": [{"type": "string"}, {"type": "integer"}]}
+		},
+		"additionalProperties": {"type": "string"}
+	}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, warnings, err := ToAvro(schema)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{
+		`#/additionalProperties: records can't have additional properties`,
+		`#/properties/code/pattern: pattern can't be represented and is dropped`,
+		`#/properties/first-name: "first-name" isn't a valid Avro field name, renamed to first_name`,
+		`#/properties/email/format: the format email can't be represented, using string`,
+		`#/properties/level/enum: "a b" isn't a valid Avro enum symbol, using string`,
+		`#/properties/anything: a schema accepting any value can't be represented, using string`,
+		`#/properties/pair/items: tuples can't be represented, using a union of the item types`,
+	}
+	if got := avroWarningStrings(warnings); strings.Join(got, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("expected warnings:\n%s\ngot:\n%s", strings.Join(expected, "\n"), strings.Join(got, "\n"))
+	}
+}
+
+func TestFromAvro(t *testing.T) {
+	schema, warnings, err := FromAvro([]byte(`{
+		"type": "record",
+		"name": "User",
+		"namespace": "com.example",
+		"doc": "A user",
+		"fields": [
+			{"name": "id", "type": {"type": "string", "logicalType": "uuid"}},
+			{"name": "age", "type": "int", "doc": "In years"},
+			{"name": "nickname", "type": ["null", "string"], "default": null},
+			{"name": "role", "type": {"type": "enum", "name": "Role", "symbols": ["ADMIN", "MEMBER"]}},
+			{"name": "previousRole", "type": ["null", "Role"], "default": null},
+			{"name": "created", "type": {"type": "long", "logicalType": "timestamp-millis"}},
+			{"name": "balance", "type": {"type": "bytes", "logicalType": "decimal", "precision": 10, "scale": 2}},
+			{"name": "tags", "type": {"type": "array", "items": "string"}},
+			{"name": "scores", "type": {"type": "map", "values": "double"}},
+			{"name": "friends", "type": {"type": "array", "items": "User"}}
+		]
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(warnings) > 0 {
+		t.Errorf("expected no warnings, got: %v", warnings)
+	}
+
+	expected := `{"$schema":"http://json-schema.org/draft-07/schema#","type":"object","title":"User","description":"A user",` +
+		`"properties":{` +
+		`"id":{"type":"string","format":"uuid"},` +
+		`"age":{"type":"integer","minimum":-2147483648,"maximum":2147483647,"description":"In years"},` +
+		`"nickname":{"type":["string","null"],"default":null},` +
+		`"role":{"$ref":"#/definitions/com.example.Role"},` +
+		`"previousRole":{"oneOf":[{"type":"null"},{"$ref":"#/definitions/com.example.Role"}],"default":null},` +
+		`"created":{"type":"string","format":"date-time"},` +
+		`"balance":{"type":"string","format":"decimal","precision":10,"scale":2},` +
+		`"tags":{"type":"array","items":{"type":"string"}},` +
+		`"scores":{"type":"object","additionalProperties":{"type":"number"}},` +
+		`"friends":{"type":"array","items":{"$ref":"#"}}},` +
+		`"required":["id","age","role","created","balance","tags","scores","friends"],"additionalProperties":false,` +
+		`"definitions":{"com.example.Role":{"type":"string","enum":["ADMIN","MEMBER"]}}}`
+	out, err := schema.MarshalWithOptions(MarshalOptions{Lossless: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(out) != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, out)
+	}
+
+	tests := []struct {
+		doc   string
+		valid bool
+	}{
+		{doc: `{"id":"6ba7b810-9dad-11d1-80b4-00c04fd430c8","age":30,"role":"ADMIN","created":"2020-01-01T00:00:00Z",` +
+			`"balance":"12.50","tags":[],"scores":{"a":1.5},"friends":[]}`, valid: true},
+		{doc: `{"id":"6ba7b810-9dad-11d1-80b4-00c04fd430c8","age":30,"role":"OWNER","created":"2020-01-01T00:00:00Z",` +
+			`"balance":"12.50","tags":[],"scores":{},"friends":[]}`},
+		{doc: `{"id":"6ba7b810-9dad-11d1-80b4-00c04fd430c8","age":30,"role":"ADMIN","created":"2020-01-01T00:00:00Z",` +
+			`"balance":"twelve","tags":[],"scores":{},"friends":[]}`},
+		{doc: `{"id":"6ba7b810-9dad-11d1-80b4-00c04fd430c8","age":3000000000,"role":"ADMIN","created":"2020-01-01T00:00:00Z",` +
+			`"balance":"12.50","tags":[],"scores":{},"friends":[]}`},
+	}
+	for _, test := range tests {
+		if valid, err := schema.Validate([]byte(test.doc)); valid != test.valid {
+			t.Errorf("expected %s to be valid: %t, got: %t (%v)", test.doc, test.valid, valid, err)
+		}
+	}
+}
+
+func TestFromAvroWarnings(t *testing.T) {
+	_, warnings, err := FromAvro([]byte(`{
+		"type": "record",
+		"name": "Event",
+		"aliases": ["OldEvent"],
+		"fields": [
+			{"name": "at", "type": {"type": "long", "logicalType": "local-timestamp-millis"}},
+			{"name": "key", "type": "string", "order": "descending"}
+		]
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{
+		`#/aliases: aliases can't be represented and are dropped`,
+		`#/fields/0/type/logicalType: the logical type local-timestamp-millis can't be represented, using the underlying type`,
+		`#/fields/1/order: the sort order of fields can't be represented and is dropped`,
+	}
+	if got := avroWarningStrings(warnings); strings.Join(got, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("expected warnings:\n%s\ngot:\n%s", strings.Join(expected, "\n"), strings.Join(got, "\n"))
+	}
+}
+
+func TestAvroRoundTrip(t *testing.T) {
+	avro := `{"type":"record","name":"Order","fields":[` +
+		`{"name":"id","type":"long"},` +
+		`{"name":"note","type":["null","string"],"default":null},` +
+		`{"name":"status","type":{"type":"enum","name":"OrderStatus","symbols":["OPEN","CLOSED"]}},` +
+		`{"name":"lines","type":{"type":"array","items":{"type":"record","name":"OrderLine","fields":[{"name":"sku","type":"string"}]}}}]}`
+
+	schema, _, err := FromAvro([]byte(avro))
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, warnings, err := ToAvro(schema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(warnings) > 0 {
+		t.Errorf("expected no warnings, got: %v", warnings)
+	}
+	if string(out) != avro {
+		t.Errorf("expected:\n%s\ngot:\n%s", avro, out)
+	}
+}
+
+func TestFromAvroInvalid(t *testing.T) {
+	tests := []string{
+		`{"type": "record", "fields": []}`,
+		`{"type": "record", "name": "A"}`,
+		`{"type": "array"}`,
+		`"Unknown"`,
+		`1`,
+	}
+	for _, test := range tests {
+		if _, _, err := FromAvro([]byte(test)); err == nil {
+			t.Errorf("expected %s to fail", test)
+		}
+	}
+}
+
+func TestDecimalFormat(t *testing.T) {
+	// Without registering the format, decimal is unknown, like any other format outside JSON Schema
+	schema, err := NewFromString(`{"type": "string", "format": "decimal"}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if valid, _ := schema.Validate([]byte(`"12.50"`)); valid {
+		t.Error("expected decimal to be an unknown format")
+	}
+
+	schema.RegisterFormat("decimal", checkDecimal)
+	fromAvro, _, err := FromAvro([]byte(`{"type": "bytes", "logicalType": "decimal", "precision": 4, "scale": 2}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]bool{
+		`"12.50"`: true,
+		`"-0.5"`:  true,
+		`"10"`:    true,
+		`"01.5"`:  false,
+		`"12."`:   false,
+		`"1e3"`:   false,
+		`"+1"`:    false,
+	}
+	for doc, expected := range tests {
+		if valid, _ := schema.Validate([]byte(doc)); valid != expected {
+			t.Errorf("expected %s to be valid: %t, got: %t", doc, expected, valid)
+		}
+		if valid, _ := fromAvro.Validate([]byte(doc)); valid != expected {
+			t.Errorf("expected %s to be valid from Avro: %t, got: %t", doc, expected, valid)
+		}
+	}
+}
+
+func avroWarningStrings(warnings []AvroWarning) []string {
+	strs := []string{}
+	for _, warning := range warnings {
+		strs = append(strs, warning.String())
+	}
+	return strs
+}
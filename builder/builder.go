@@ -0,0 +1,522 @@
+// Package builder constructs JSON Schemas in Go, without filling the pointer fields of
+// jsonschema.Schema by hand:
+//
+//	schema, err := builder.Object().
+//		Prop("id", builder.String().Format("uuid")).
+//		Prop("tags", builder.Array().Items(builder.String()).UniqueItems()).
+//		Required("id").
+//		Build()
+//
+// Build parses the schema with jsonschema.New, so it validates, resolves $refs and marshals
+// like any other parsed schema.
+package builder
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/flowstack/go-jsonschema"
+)
+
+// Builder builds a schema. Keywords are kept in the order they are set.
+// The methods change and return the builder, so calls can be chained.
+type Builder struct {
+	keywords []*keyword
+	boolean  *bool
+	err      error
+}
+
+type keyword struct {
+	name  string
+	value interface{}
+}
+
+// member is a named sub schema, e.g. a property or a definition
+type member struct {
+	name   string
+	schema *Builder
+}
+
+// members marshals into an object, keeping the order the members were added in
+type members []*member
+
+// New returns a builder of an empty schema, which accepts everything
+func New() *Builder {
+	return &Builder{}
+}
+
+// True returns a builder of the true schema, which accepts everything
+func True() *Builder {
+	b := true
+	return &Builder{boolean: &b}
+}
+
+// False returns a builder of the false schema, which accepts nothing
+func False() *Builder {
+	b := false
+	return &Builder{boolean: &b}
+}
+
+// Object returns a builder of a schema with type object
+func Object() *Builder {
+	return New().Type("object")
+}
+
+// String returns a builder of a schema with type string
+func String() *Builder {
+	return New().Type("string")
+}
+
+// Integer returns a builder of a schema with type integer
+func Integer() *Builder {
+	return New().Type("integer")
+}
+
+// Number returns a builder of a schema with type number
+func Number() *Builder {
+	return New().Type("number")
+}
+
+// Boolean returns a builder of a schema with type boolean
+func Boolean() *Builder {
+	return New().Type("boolean")
+}
+
+// Null returns a builder of a schema with type null
+func Null() *Builder {
+	return New().Type("null")
+}
+
+// Array returns a builder of a schema with type array
+func Array() *Builder {
+	return New().Type("array")
+}
+
+// Ref returns a builder of a schema, that references another schema
+func Ref(ref string) *Builder {
+	return New().Ref(ref)
+}
+
+// AllOf returns a builder of a schema, that must match all of the schemas
+func AllOf(schemas ...*Builder) *Builder {
+	return New().AllOf(schemas...)
+}
+
+// AnyOf returns a builder of a schema, that must match at least one of the schemas
+func AnyOf(schemas ...*Builder) *Builder {
+	return New().AnyOf(schemas...)
+}
+
+// OneOf returns a builder of a schema, that must match exactly one of the schemas
+func OneOf(schemas ...*Builder) *Builder {
+	return New().OneOf(schemas...)
+}
+
+// Not returns a builder of a schema, that must not match the schema
+func Not(schema *Builder) *Builder {
+	return New().Not(schema)
+}
+
+// Build returns the parsed schema, or the first error found while building it
+func (b *Builder) Build() (*jsonschema.Schema, error) {
+	data, err := b.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+	return jsonschema.New(data)
+}
+
+// MustBuild is like Build, but panics if the schema can't be built
+func (b *Builder) MustBuild() *jsonschema.Schema {
+	schema, err := b.Build()
+	if err != nil {
+		panic(err)
+	}
+	return schema
+}
+
+// MarshalJSON returns the JSON of the schema, with the keywords in the order they were set
+func (b *Builder) MarshalJSON() ([]byte, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+	if b.boolean != nil {
+		return json.Marshal(*b.boolean)
+	}
+
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, kw := range b.keywords {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		if err := writeMember(&buf, kw.name, kw.value); err != nil {
+			return nil, fmt.Errorf("%s: %w", kw.name, err)
+		}
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// MarshalJSON marshals the members into an object
+func (m members) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, mem := range m {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		if err := writeMember(&buf, mem.name, mem.schema); err != nil {
+			return nil, fmt.Errorf("%s: %w", mem.name, err)
+		}
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+func writeMember(buf *bytes.Buffer, name string, value interface{}) error {
+	key, err := json.Marshal(name)
+	if err != nil {
+		return err
+	}
+	val, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	buf.Write(key)
+	buf.WriteByte(':')
+	buf.Write(val)
+	return nil
+}
+
+// Set sets any keyword to a value, that encoding/json can marshal.
+// It is meant for keywords without a method of their own, e.g. custom keywords.
+func (b *Builder) Set(name string, value interface{}) *Builder {
+	if b.boolean != nil {
+		b.fail(fmt.Errorf("unable to set %s on a boolean schema", name))
+		return b
+	}
+	for _, kw := range b.keywords {
+		if kw.name == name {
+			kw.value = value
+			return b
+		}
+	}
+	b.keywords = append(b.keywords, &keyword{name: name, value: value})
+	return b
+}
+
+// get returns the value of a keyword, or nil if it isn't set
+func (b *Builder) get(name string) interface{} {
+	for _, kw := range b.keywords {
+		if kw.name == name {
+			return kw.value
+		}
+	}
+	return nil
+}
+
+// fail keeps the first error, which is returned by Build
+func (b *Builder) fail(err error) {
+	if b.err == nil {
+		b.err = err
+	}
+}
+
+// schema sets a keyword, that takes a single sub schema
+func (b *Builder) schema(name string, schema *Builder) *Builder {
+	if schema == nil {
+		b.fail(fmt.Errorf("%s has no schema", name))
+		return b
+	}
+	return b.Set(name, schema)
+}
+
+// schemas sets a keyword, that takes an array of sub schemas
+func (b *Builder) schemas(name string, schemas []*Builder) *Builder {
+	for i, schema := range schemas {
+		if schema == nil {
+			b.fail(fmt.Errorf("%s/%d has no schema", name, i))
+			return b
+		}
+	}
+	return b.Set(name, append([]*Builder{}, schemas...))
+}
+
+// member adds or replaces a named sub schema in a keyword, e.g. a property
+func (b *Builder) member(name, key string, schema *Builder) *Builder {
+	if schema == nil {
+		b.fail(fmt.Errorf("%s/%s has no schema", name, key))
+		return b
+	}
+	m, _ := b.get(name).(members)
+	for _, mem := range m {
+		if mem.name == key {
+			mem.schema = schema
+			return b
+		}
+	}
+	return b.Set(name, append(m, &member{name: key, schema: schema}))
+}
+
+// Type sets the type, or types, of the schema, e.g. Type("string", "null")
+func (b *Builder) Type(types ...string) *Builder {
+	if len(types) == 1 {
+		return b.Set("type", types[0])
+	}
+	return b.Set("type", types)
+}
+
+// Nullable adds null to the types of the schema
+func (b *Builder) Nullable() *Builder {
+	switch types := b.get("type").(type) {
+	case string:
+		if types != "null" {
+			return b.Set("type", []string{types, "null"})
+		}
+	case []string:
+		for _, t := range types {
+			if t == "null" {
+				return b
+			}
+		}
+		return b.Set("type", append(append([]string{}, types...), "null"))
+	}
+	return b
+}
+
+// Draft sets $schema to the meta-schema of the draft
+func (b *Builder) Draft(draft jsonschema.Draft) *Builder {
+	return b.Set("$schema", draft.URI())
+}
+
+// ID sets $id
+func (b *Builder) ID(id string) *Builder {
+	return b.Set("$id", id)
+}
+
+// Ref sets $ref
+func (b *Builder) Ref(ref string) *Builder {
+	return b.Set("$ref", ref)
+}
+
+// Comment sets $comment
+func (b *Builder) Comment(comment string) *Builder {
+	return b.Set("$comment", comment)
+}
+
+// Title sets title
+func (b *Builder) Title(title string) *Builder {
+	return b.Set("title", title)
+}
+
+// Description sets description
+func (b *Builder) Description(description string) *Builder {
+	return b.Set("description", description)
+}
+
+// Default sets default
+func (b *Builder) Default(value interface{}) *Builder {
+	return b.Set("default", value)
+}
+
+// Examples sets examples
+func (b *Builder) Examples(values ...interface{}) *Builder {
+	return b.Set("examples", values)
+}
+
+// ReadOnly sets readOnly to true
+func (b *Builder) ReadOnly() *Builder {
+	return b.Set("readOnly", true)
+}
+
+// WriteOnly sets writeOnly to true
+func (b *Builder) WriteOnly() *Builder {
+	return b.Set("writeOnly", true)
+}
+
+// Enum sets enum
+func (b *Builder) Enum(values ...interface{}) *Builder {
+	return b.Set("enum", values)
+}
+
+// Const sets const
+func (b *Builder) Const(value interface{}) *Builder {
+	return b.Set("const", value)
+}
+
+// Format sets format, e.g. date-time or uuid
+func (b *Builder) Format(format string) *Builder {
+	return b.Set("format", format)
+}
+
+// Pattern sets pattern
+func (b *Builder) Pattern(pattern string) *Builder {
+	return b.Set("pattern", pattern)
+}
+
+// MinLength sets minLength
+func (b *Builder) MinLength(n int64) *Builder {
+	return b.Set("minLength", n)
+}
+
+// MaxLength sets maxLength
+func (b *Builder) MaxLength(n int64) *Builder {
+	return b.Set("maxLength", n)
+}
+
+// Minimum sets minimum. Numbers, that can't be represented by a float64, can be set
+// with Set("minimum", json.Number("...")).
+func (b *Builder) Minimum(n float64) *Builder {
+	return b.Set("minimum", n)
+}
+
+// Maximum sets maximum
+func (b *Builder) Maximum(n float64) *Builder {
+	return b.Set("maximum", n)
+}
+
+// ExclusiveMinimum sets exclusiveMinimum
+func (b *Builder) ExclusiveMinimum(n float64) *Builder {
+	return b.Set("exclusiveMinimum", n)
+}
+
+// ExclusiveMaximum sets exclusiveMaximum
+func (b *Builder) ExclusiveMaximum(n float64) *Builder {
+	return b.Set("exclusiveMaximum", n)
+}
+
+// MultipleOf sets multipleOf
+func (b *Builder) MultipleOf(n float64) *Builder {
+	return b.Set("multipleOf", n)
+}
+
+// Items sets the schema of every item
+func (b *Builder) Items(schema *Builder) *Builder {
+	return b.schema("items", schema)
+}
+
+// TupleItems sets a schema for each position, i.e. items as an array
+func (b *Builder) TupleItems(schemas ...*Builder) *Builder {
+	return b.schemas("items", schemas)
+}
+
+// AdditionalItems sets the schema of the items after the TupleItems
+func (b *Builder) AdditionalItems(schema *Builder) *Builder {
+	return b.schema("additionalItems", schema)
+}
+
+// Contains sets contains
+func (b *Builder) Contains(schema *Builder) *Builder {
+	return b.schema("contains", schema)
+}
+
+// MinItems sets minItems
+func (b *Builder) MinItems(n int64) *Builder {
+	return b.Set("minItems", n)
+}
+
+// MaxItems sets maxItems
+func (b *Builder) MaxItems(n int64) *Builder {
+	return b.Set("maxItems", n)
+}
+
+// UniqueItems sets uniqueItems to true
+func (b *Builder) UniqueItems() *Builder {
+	return b.Set("uniqueItems", true)
+}
+
+// Prop adds a property, or replaces a property with the same name
+func (b *Builder) Prop(name string, schema *Builder) *Builder {
+	return b.member("properties", name, schema)
+}
+
+// PatternProp adds a schema for properties with names matching pattern
+func (b *Builder) PatternProp(pattern string, schema *Builder) *Builder {
+	return b.member("patternProperties", pattern, schema)
+}
+
+// AdditionalProperties sets the schema of properties, that aren't matched by Prop or PatternProp.
+// Use False() to disallow other properties.
+func (b *Builder) AdditionalProperties(schema *Builder) *Builder {
+	return b.schema("additionalProperties", schema)
+}
+
+// PropertyNames sets the schema, that every property name must match
+func (b *Builder) PropertyNames(schema *Builder) *Builder {
+	return b.schema("propertyNames", schema)
+}
+
+// Required adds required properties
+func (b *Builder) Required(names ...string) *Builder {
+	required, _ := b.get("required").([]string)
+	for _, name := range names {
+		found := false
+		for _, r := range required {
+			if r == name {
+				found = true
+				break
+			}
+		}
+		if !found {
+			required = append(required, name)
+		}
+	}
+	return b.Set("required", required)
+}
+
+// MinProperties sets minProperties
+func (b *Builder) MinProperties(n int64) *Builder {
+	return b.Set("minProperties", n)
+}
+
+// MaxProperties sets maxProperties
+func (b *Builder) MaxProperties(n int64) *Builder {
+	return b.Set("maxProperties", n)
+}
+
+// Dependency adds a schema, that the object must match, when it has the property name
+func (b *Builder) Dependency(name string, schema *Builder) *Builder {
+	return b.member("dependencies", name, schema)
+}
+
+// Definition adds a schema to definitions, which can be referenced with Ref("#/definitions/<name>")
+func (b *Builder) Definition(name string, schema *Builder) *Builder {
+	return b.member("definitions", name, schema)
+}
+
+// AllOf sets allOf
+func (b *Builder) AllOf(schemas ...*Builder) *Builder {
+	return b.schemas("allOf", schemas)
+}
+
+// AnyOf sets anyOf
+func (b *Builder) AnyOf(schemas ...*Builder) *Builder {
+	return b.schemas("anyOf", schemas)
+}
+
+// OneOf sets oneOf
+func (b *Builder) OneOf(schemas ...*Builder) *Builder {
+	return b.schemas("oneOf", schemas)
+}
+
+// Not sets not
+func (b *Builder) Not(schema *Builder) *Builder {
+	return b.schema("not", schema)
+}
+
+// If sets if
+func (b *Builder) If(schema *Builder) *Builder {
+	return b.schema("if", schema)
+}
+
+// Then sets then
+func (b *Builder) Then(schema *Builder) *Builder {
+	return b.schema("then", schema)
+}
+
+// Else sets else
+func (b *Builder) Else(schema *Builder) *Builder {
+	return b.schema("else", schema)
+}
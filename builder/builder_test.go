@@ -0,0 +1,107 @@
+package builder
+
+import (
+	"testing"
+
+	"github.com/flowstack/go-jsonschema"
+)
+
+func TestBuild(t *testing.T) {
+	schema, err := Object().
+		Title("User").
+		Prop("id", String().Format("uuid")).
+		Prop("age", Integer().Minimum(0).Maximum(150)).
+		Prop("tags", Array().Items(String().MinLength(1)).UniqueItems()).
+		Prop("role", Ref("#/definitions/role")).
+		Prop("nickname", String().Nullable()).
+		Definition("role", New().Enum("admin", "user")).
+		Required("id", "age").
+		Required("id").
+		AdditionalProperties(False()).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `{"type":"object","title":"User","properties":{"id":{"type":"string","format":"uuid"},` +
+		`"age":{"type":"integer","minimum":0,"maximum":150},` +
+		`"tags":{"type":"array","items":{"type":"string","minLength":1},"uniqueItems":true},` +
+		`"role":{"$ref":"#/definitions/role"},"nickname":{"type":["string","null"]}},` +
+		`"definitions":{"role":{"enum":["admin","user"]}},"required":["id","age"],"additionalProperties":false}`
+	got, err := Object().
+		Title("User").
+		Prop("id", String().Format("uuid")).
+		Prop("age", Integer().Minimum(0).Maximum(150)).
+		Prop("tags", Array().Items(String().MinLength(1)).UniqueItems()).
+		Prop("role", Ref("#/definitions/role")).
+		Prop("nickname", String().Nullable().Nullable()).
+		Definition("role", New().Enum("admin", "user")).
+		Required("id", "age").
+		AdditionalProperties(False()).
+		MarshalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != expected {
+		t.Fatalf("expected:\n%s\ngot:\n%s", expected, got)
+	}
+
+	tests := []struct {
+		doc   string
+		valid bool
+	}{
+		{doc: `{"id": "6a2f41a3-c54c-fce8-32d2-0324e1c32e22", "age": 30, "tags": ["a"], "role": "admin"}`, valid: true},
+		{doc: `{"id": "6a2f41a3-c54c-fce8-32d2-0324e1c32e22", "age": 30, "nickname": null}`, valid: true},
+		{doc: `{"id": "6a2f41a3-c54c-fce8-32d2-0324e1c32e22"}`},
+		{doc: `{"id": "not a uuid", "age": 30}`},
+		{doc: `{"id": "6a2f41a3-c54c-fce8-32d2-0324e1c32e22", "age": -1}`},
+		{doc: `{"id": "6a2f41a3-c54c-fce8-32d2-0324e1c32e22", "age": 30, "tags": ["a", "a"]}`},
+		{doc: `{"id": "6a2f41a3-c54c-fce8-32d2-0324e1c32e22", "age": 30, "role": "root"}`},
+		{doc: `{"id": "6a2f41a3-c54c-fce8-32d2-0324e1c32e22", "age": 30, "other": 1}`},
+	}
+	for _, test := range tests {
+		valid, err := schema.Validate([]byte(test.doc))
+		if valid != test.valid {
+			t.Errorf("expected %s to be valid: %t, got: %t (%v)", test.doc, test.valid, valid, err)
+		}
+	}
+}
+
+func TestBuildCombinators(t *testing.T) {
+	schema := OneOf(
+		Object().Prop("kind", New().Const("circle")).Prop("radius", Number().ExclusiveMinimum(0)).Required("kind", "radius"),
+		Object().Prop("kind", New().Const("square")).Prop("side", Number().MultipleOf(0.5)).Required("kind", "side"),
+	).Draft(jsonschema.Draft07).MustBuild()
+
+	if !schema.IsDraft7() {
+		t.Errorf("expected a draft 7 schema")
+	}
+
+	tests := map[string]bool{
+		`{"kind": "circle", "radius": 1}`:  true,
+		`{"kind": "circle", "radius": 0}`:  false,
+		`{"kind": "square", "side": 1.5}`:  true,
+		`{"kind": "square", "side": 1.25}`: false,
+		`{"kind": "triangle"}`:             false,
+	}
+	for doc, expected := range tests {
+		if valid, err := schema.Validate([]byte(doc)); valid != expected {
+			t.Errorf("expected %s to be valid: %t, got: %t (%v)", doc, expected, valid, err)
+		}
+	}
+}
+
+func TestBuildErrors(t *testing.T) {
+	if _, err := Object().Prop("a", nil).Build(); err == nil {
+		t.Errorf("expected a property without a schema to fail")
+	}
+	if _, err := AllOf(String(), nil).Build(); err == nil {
+		t.Errorf("expected allOf with a nil schema to fail")
+	}
+	if _, err := True().Title("x").Build(); err == nil {
+		t.Errorf("expected keywords on a boolean schema to fail")
+	}
+	if _, err := Object().Prop("a", String().Default(func() {})).Build(); err == nil {
+		t.Errorf("expected a default, that can't be marshalled, to fail")
+	}
+}
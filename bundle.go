@@ -0,0 +1,236 @@
+package jsonschema
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/buger/jsonparser"
+)
+
+var reNonNameChars = regexp.MustCompile(`[^A-Za-z0-9_.-]+`)
+
+type bundledResource struct {
+	uri  string
+	name string
+	doc  *node
+	// id is the $id added by the bundler, if the schema didn't have one
+	id *node
+}
+
+type bundler struct {
+	// known holds the URIs of every schema resource, that is already part of the bundle
+	known     map[string]struct{}
+	resources []*bundledResource
+}
+
+// Bundle loads the schema at rootURI, using Loader, together with every schema it references
+// transitively, and returns a single compound schema document.
+// Each referenced schema is embedded in the root's $defs (definitions for draft-04 to draft-07),
+// with its original $id, so the existing $refs keep resolving to the embedded schemas.
+// If a schema has no $id, its URI is set as $id, so relative $refs keep working.
+// rootURI can be a URL or a path on the file system. The $ids of files are relative to the root
+// file, so the bundle doesn't contain paths from the local file system.
+func Bundle(rootURI string) ([]byte, error) {
+	uri, err := toURI(rootURI)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := Loader(uri)
+	if err != nil {
+		return nil, err
+	}
+
+	root, err := parseNode(data)
+	if err != nil {
+		return nil, err
+	}
+	if root.typ != jsonparser.Object {
+		// Boolean schemas can't reference anything
+		return data, nil
+	}
+
+	rootDraft := DraftUnknown
+	if str, ok := root.get("$schema").str(); ok {
+		rootDraft = DraftFromURI(str)
+	}
+
+	b := &bundler{known: map[string]struct{}{}}
+
+	if err := b.add(uri, root, rootDraft); err != nil {
+		return nil, err
+	}
+
+	if err := b.relativeFileIDs(uri); err != nil {
+		return nil, err
+	}
+
+	// The root itself is not embedded
+	b.resources = b.resources[1:]
+	if len(b.resources) == 0 {
+		return data, nil
+	}
+
+	defsKey := "$defs"
+	if rootDraft != DraftUnknown && rootDraft <= Draft07 {
+		defsKey = "definitions"
+	}
+
+	defs := root.get(defsKey)
+	if defs == nil {
+		defs = newObjectNode()
+		root.set(defsKey, defs)
+	} else if defs.typ != jsonparser.Object {
+		return nil, fmt.Errorf("expected %s to be an object, got: %s", defsKey, defs.typ.String())
+	}
+
+	for _, res := range b.resources {
+		name := res.name
+		for i := 2; defs.has(name); i++ {
+			name = fmt.Sprintf("%s_%d", res.name, i)
+		}
+		defs.set(name, res.doc)
+	}
+
+	return root.MarshalJSON()
+}
+
+// add makes sure doc has an $id, and adds it and everything it references to the bundle
+func (b *bundler) add(uri string, doc *node, draft Draft) error {
+	if docSchema, ok := doc.get("$schema").str(); ok {
+		draft = DraftFromURI(docSchema)
+	}
+
+	idKey := "$id"
+	if draft == Draft04 {
+		idKey = "id"
+	}
+
+	switch doc.typ {
+	case jsonparser.Boolean:
+		// Boolean schemas can't have an $id, so they're converted to the equivalent object
+		if isTrue, _ := doc.boolean(); isTrue {
+			*doc = *newObjectNode()
+		} else {
+			*doc = *newObjectNode()
+			doc.set("not", newObjectNode())
+		}
+	case jsonparser.Object:
+	default:
+		return fmt.Errorf("expected schema at %s to be an object or boolean, got: %s", uri, doc.typ.String())
+	}
+
+	var idNode *node
+	if id, ok := doc.get(idKey).str(); !ok || id == "" || strings.HasPrefix(id, "#") {
+		idNode = newStringNode(uri)
+		if doc.has(idKey) {
+			doc.set(idKey, idNode)
+		} else {
+			doc.members = append([]*member{{key: idKey, value: idNode}}, doc.members...)
+		}
+	}
+
+	raw, err := doc.MarshalJSON()
+	if err != nil {
+		return err
+	}
+
+	schema, err := New(raw)
+	if err != nil {
+		return fmt.Errorf("unable to parse %s: %w", uri, err)
+	}
+
+	b.resources = append(b.resources, &bundledResource{uri: uri, name: resourceName(uri), doc: doc, id: idNode})
+	b.known[uri] = struct{}{}
+	if schema.baseURI != nil {
+		b.known[schema.baseURI.String()] = struct{}{}
+	}
+	if schema.pointers != nil {
+		for key := range *schema.pointers {
+			if strings.Contains(key, ":") {
+				b.known[key] = struct{}{}
+			}
+		}
+	}
+
+	for _, ref := range *schema.refs {
+		if ref.String == nil || strings.HasPrefix(*ref.String, "#") {
+			continue
+		}
+
+		refURI, err := ref.parent.ExpandURI(*ref.String)
+		if err != nil {
+			return err
+		}
+		refURI.Fragment = ""
+		key := refURI.String()
+
+		// The meta-schemas are built in
+		if DraftFromURI(key) != DraftUnknown {
+			continue
+		}
+
+		if _, ok := b.known[key]; ok {
+			continue
+		}
+		if !refURI.IsAbs() {
+			return fmt.Errorf("unable to resolve $ref %s in %s without a base URI", *ref.String, uri)
+		}
+
+		data, err := Loader(key)
+		if err != nil {
+			return err
+		}
+
+		refDoc, err := parseNode(data)
+		if err != nil {
+			return fmt.Errorf("unable to parse %s: %w", key, err)
+		}
+
+		if err := b.add(key, refDoc, draft); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// relativeFileIDs makes the $ids, that were added to files, relative to the directory of the root file
+func (b *bundler) relativeFileIDs(rootURI string) error {
+	root, err := url.Parse(rootURI)
+	if err != nil || root.Scheme != "file" {
+		return err
+	}
+	rootDir := path.Dir(root.Path)
+
+	for _, res := range b.resources {
+		u, err := url.Parse(res.uri)
+		if err != nil {
+			return err
+		}
+		if res.id == nil || u.Scheme != "file" {
+			continue
+		}
+		rel, err := filepath.Rel(filepath.FromSlash(rootDir), filepath.FromSlash(u.Path))
+		if err != nil {
+			return err
+		}
+		*res.id = *newStringNode(filepath.ToSlash(rel))
+	}
+	return nil
+}
+
+// resourceName creates a readable name from the last part of the URI, e.g. "item" for http://example.com/item.json
+func resourceName(uri string) string {
+	name := path.Base(strings.TrimRight(uri, "/"))
+	name = strings.TrimSuffix(name, path.Ext(name))
+	name = reNonNameChars.ReplaceAllString(name, "_")
+	if name == "" || name == "." || name == "_" {
+		return "schema"
+	}
+	return name
+}
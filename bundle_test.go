@@ -0,0 +1,117 @@
+package jsonschema
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var bundleTestSchemas = map[string]string{
+	"http://example.com/root.json":        `{"$schema":"https://json-schema.org/draft/2020-12/schema","properties":{"item":{"$ref":"item.json"},"count":{"$ref":"http://example.com/defs/common.json#/$defs/count"}}}`,
+	"http://example.com/item.json":        `{"properties":{"id":{"$ref":"defs/common.json#/$defs/id"},"parent":{"$ref":"#"}}}`,
+	"http://example.com/defs/common.json": `{"$id":"http://example.com/defs/common.json","$defs":{"id":{"type":"string","minLength":1},"count":{"type":"integer","minimum":0}}}`,
+}
+
+func TestBundle(t *testing.T) {
+	defaultLoader := Loader
+	defer func() { Loader = defaultLoader }()
+
+	loaded := map[string]int{}
+	Loader = func(uri string) ([]byte, error) {
+		loaded[uri]++
+		if schema, ok := bundleTestSchemas[uri]; ok {
+			return []byte(schema), nil
+		}
+		return nil, errors.New("unknown schema: " + uri)
+	}
+
+	bundled, err := Bundle("http://example.com/root.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `{"$id":"http://example.com/root.json","$schema":"https://json-schema.org/draft/2020-12/schema","properties":{"item":{"$ref":"item.json"},"count":{"$ref":"http://example.com/defs/common.json#/$defs/count"}},"$defs":{"item":{"$id":"http://example.com/item.json","properties":{"id":{"$ref":"defs/common.json#/$defs/id"},"parent":{"$ref":"#"}}},"common":{"$id":"http://example.com/defs/common.json","$defs":{"id":{"type":"string","minLength":1},"count":{"type":"integer","minimum":0}}}}}`
+	if string(bundled) != expected {
+		t.Fatalf("expected bundle to match:\n%s\ngot:\n%s\n", expected, string(bundled))
+	}
+
+	for uri, count := range loaded {
+		if count != 1 {
+			t.Fatalf("expected %s to be loaded once, got: %d", uri, count)
+		}
+	}
+
+	// The bundle must be usable without loading anything
+	Loader = func(uri string) ([]byte, error) {
+		return nil, errors.New("unexpected load of: " + uri)
+	}
+
+	schema, err := New(bundled)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	docs := []struct {
+		doc   string
+		valid bool
+	}{
+		{`{"item":{"id":"a","parent":{"id":"b"}},"count":1}`, true},
+		{`{"item":{"id":""}}`, false},
+		{`{"item":{"parent":{"id":1}}}`, false},
+		{`{"count":-1}`, false},
+	}
+
+	for _, tt := range docs {
+		valid, err := schema.Validate([]byte(tt.doc))
+		if valid != tt.valid {
+			t.Fatalf("expected %s to be valid: %t, got: %t (%v)", tt.doc, tt.valid, valid, err)
+		}
+	}
+}
+
+func TestBundleFiles(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "defs"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{
+		"root.json":        `{"$id":"","properties":{"item":{"$ref":"defs/item.json"}}}`,
+		"defs/item.json":   `{"properties":{"id":{"$ref":"common.json#/definitions/id"}}}`,
+		"defs/common.json": `{"definitions":{"id":{"type":"string","minLength":1}}}`,
+	}
+	for name, schema := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(schema), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	bundled, err := Bundle(filepath.Join(dir, "root.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The empty $id is replaced, and no $id contains the local path
+	expected := `{"$id":"root.json","properties":{"item":{"$ref":"defs/item.json"}},"$defs":{"item":{"$id":"defs/item.json","properties":{"id":{"$ref":"common.json#/definitions/id"}}},"common":{"$id":"defs/common.json","definitions":{"id":{"type":"string","minLength":1}}}}}`
+	if string(bundled) != expected {
+		t.Fatalf("expected bundle to match:\n%s\ngot:\n%s\n", expected, string(bundled))
+	}
+
+	defaultLoader := Loader
+	defer func() { Loader = defaultLoader }()
+	Loader = func(uri string) ([]byte, error) {
+		return nil, errors.New("unexpected load of: " + uri)
+	}
+
+	schema, err := New(bundled)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if valid, _ := schema.Validate([]byte(`{"item":{"id":"a"}}`)); !valid {
+		t.Errorf("expected a valid document to be valid")
+	}
+	if valid, _ := schema.Validate([]byte(`{"item":{"id":""}}`)); valid {
+		t.Errorf("expected an invalid document to be invalid")
+	}
+}
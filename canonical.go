@@ -0,0 +1,306 @@
+package jsonschema
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/buger/jsonparser"
+)
+
+// Canonicalize returns data in the canonical form of RFC 8785 (JSON Canonicalization Scheme),
+// so documents are equal by JSON Schema semantics exactly when their canonical forms are equal.
+// Objects have their keys sorted recursively, there's no whitespace and strings are escaped minimally.
+//
+// Unlike RFC 8785 numbers aren't rounded to float64, so numbers that are too close to be told apart
+// as float64 are still different, as JSON Schema requires. Numbers are written like RFC 8785 writes
+// them, e.g. 1.0 as 1 and 1e21 as 1e+21.
+func Canonicalize(data []byte) ([]byte, error) {
+	value, dataType, _, err := jsonparser.Get(data)
+	if err != nil {
+		return nil, err
+	}
+	return canonicalJSON(value, dataType)
+}
+
+// CanonicalEqual tells whether two JSON documents are equal by JSON Schema semantics,
+// e.g. {"a":1,"b":2} and {"b":2.0,"a":1} are equal
+func CanonicalEqual(a, b []byte) (bool, error) {
+	canonicalA, err := Canonicalize(a)
+	if err != nil {
+		return false, err
+	}
+	canonicalB, err := Canonicalize(b)
+	if err != nil {
+		return false, err
+	}
+	return bytes.Equal(canonicalA, canonicalB), nil
+}
+
+// CanonicalHash returns a hash of the canonical form of data, so equal documents have equal hashes
+func CanonicalHash(data []byte) (uint64, error) {
+	canonical, err := Canonicalize(data)
+	if err != nil {
+		return 0, err
+	}
+	return hashCanonical(canonical), nil
+}
+
+// canonicalJSON returns the canonical form of a value as returned by jsonparser,
+// i.e. strings are escaped and without quotes
+func canonicalJSON(value []byte, dataType jsonparser.ValueType) ([]byte, error) {
+	buf := &bytes.Buffer{}
+	if err := writeCanonical(buf, value, dataType); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// canonicalValue is canonicalJSON for the values passed to validators, where strings are unescaped
+func canonicalValue(value []byte, vt ValueType) ([]byte, error) {
+	if vt == String {
+		buf := &bytes.Buffer{}
+		writeCanonicalString(buf, value)
+		return buf.Bytes(), nil
+	}
+	return canonicalJSON(value, vt.ParserValueType())
+}
+
+func writeCanonical(buf *bytes.Buffer, value []byte, dataType jsonparser.ValueType) error {
+	switch dataType {
+	case jsonparser.String:
+		str, err := jsonparser.Unescape(value, nil)
+		if err != nil {
+			return err
+		}
+		writeCanonicalString(buf, str)
+
+	case jsonparser.Number:
+		return writeCanonicalNumber(buf, value)
+
+	case jsonparser.Boolean, jsonparser.Null:
+		buf.Write(value)
+
+	case jsonparser.Array:
+		buf.WriteByte('[')
+		first := true
+		var errs error
+		_, err := jsonparser.ArrayEach(value, func(value []byte, dataType jsonparser.ValueType, offset int, err error) {
+			if err != nil || errs != nil {
+				errs = addError(err, errs)
+				return
+			}
+			if !first {
+				buf.WriteByte(',')
+			}
+			first = false
+			errs = writeCanonical(buf, value, dataType)
+		})
+		if err != nil {
+			return err
+		}
+		if errs != nil {
+			return errs
+		}
+		buf.WriteByte(']')
+
+	case jsonparser.Object:
+		type member struct {
+			key      []uint16
+			rawKey   []byte
+			value    []byte
+			dataType jsonparser.ValueType
+		}
+		members := []member{}
+		err := jsonparser.ObjectEach(value, func(key, value []byte, dataType jsonparser.ValueType, offset int) error {
+			// Keys are already unescaped and are sorted by their UTF-16 code units
+			members = append(members, member{
+				key:      utf16.Encode([]rune(string(key))),
+				rawKey:   key,
+				value:    value,
+				dataType: dataType,
+			})
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		sort.SliceStable(members, func(i, j int) bool {
+			return lessUTF16(members[i].key, members[j].key)
+		})
+
+		buf.WriteByte('{')
+		for i, m := range members {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			writeCanonicalString(buf, m.rawKey)
+			buf.WriteByte(':')
+			if err := writeCanonical(buf, m.value, m.dataType); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte('}')
+
+	default:
+		return fmt.Errorf("unexpected JSON value: %s", value)
+	}
+
+	return nil
+}
+
+func lessUTF16(a, b []uint16) bool {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if a[i] != b[i] {
+			return a[i] < b[i]
+		}
+	}
+	return len(a) < len(b)
+}
+
+// writeCanonicalString writes an unescaped string with the minimal escaping of RFC 8785
+func writeCanonicalString(buf *bytes.Buffer, str []byte) {
+	const hex = "0123456789abcdef"
+
+	buf.WriteByte('"')
+	for _, c := range str {
+		switch c {
+		case '"', '\\':
+			buf.WriteByte('\\')
+			buf.WriteByte(c)
+		case '\b':
+			buf.WriteString(`\b`)
+		case '\f':
+			buf.WriteString(`\f`)
+		case '\n':
+			buf.WriteString(`\n`)
+		case '\r':
+			buf.WriteString(`\r`)
+		case '\t':
+			buf.WriteString(`\t`)
+		default:
+			if c < 0x20 {
+				buf.WriteString(`\u00`)
+				buf.WriteByte(hex[c>>4])
+				buf.WriteByte(hex[c&0xf])
+			} else {
+				buf.WriteByte(c)
+			}
+		}
+	}
+	buf.WriteByte('"')
+}
+
+// maxCanonicalExponent limits exponents, so they can't make the canonical form huge
+const maxCanonicalExponent = 1 << 30
+
+// writeCanonicalNumber writes a number exactly, in the format of ECMAScript's Number.prototype.toString
+func writeCanonicalNumber(buf *bytes.Buffer, num []byte) error {
+	str := string(num)
+	if !isJSONNumber(str) {
+		return fmt.Errorf("invalid number: %s", num)
+	}
+
+	negative := strings.HasPrefix(str, "-")
+	str = strings.TrimPrefix(str, "-")
+
+	exponent := 0
+	if idx := strings.IndexAny(str, "eE"); idx >= 0 {
+		e, err := strconv.Atoi(str[idx+1:])
+		if err != nil || e > maxCanonicalExponent || e < -maxCanonicalExponent {
+			return errors.New("number exponent is out of range")
+		}
+		exponent = e
+		str = str[:idx]
+	}
+
+	// The number is digits * 10^exponent, with no leading or trailing zeros in digits
+	digits := str
+	if idx := strings.IndexByte(str, '.'); idx >= 0 {
+		digits = str[:idx] + str[idx+1:]
+		exponent -= len(str) - idx - 1
+	}
+	digits = strings.TrimLeft(digits, "0")
+	trimmed := strings.TrimRight(digits, "0")
+	exponent += len(digits) - len(trimmed)
+	digits = trimmed
+
+	if digits == "" {
+		// -0 is 0
+		buf.WriteByte('0')
+		return nil
+	}
+	if negative {
+		buf.WriteByte('-')
+	}
+
+	// The number is 0.digits * 10^n
+	k := len(digits)
+	n := k + exponent
+	switch {
+	case k <= n && n <= 21:
+		buf.WriteString(digits)
+		buf.WriteString(strings.Repeat("0", n-k))
+	case 0 < n && n <= 21:
+		buf.WriteString(digits[:n])
+		buf.WriteByte('.')
+		buf.WriteString(digits[n:])
+	case -6 < n && n <= 0:
+		buf.WriteString("0.")
+		buf.WriteString(strings.Repeat("0", -n))
+		buf.WriteString(digits)
+	default:
+		buf.WriteByte(digits[0])
+		if k > 1 {
+			buf.WriteByte('.')
+			buf.WriteString(digits[1:])
+		}
+		buf.WriteByte('e')
+		if n-1 >= 0 {
+			buf.WriteByte('+')
+		}
+		buf.WriteString(strconv.Itoa(n - 1))
+	}
+
+	return nil
+}
+
+// hashCanonical is the 64-bit FNV-1a hash of a canonical form
+func hashCanonical(canonical []byte) uint64 {
+	const (
+		offset64 = 14695981039346656037
+		prime64  = 1099511628211
+	)
+	hash := uint64(offset64)
+	for _, c := range canonical {
+		hash ^= uint64(c)
+		hash *= prime64
+	}
+	return hash
+}
+
+// canonicalSet is a set of canonical forms, bucketed by their hashes
+type canonicalSet map[uint64][][]byte
+
+// add adds the canonical form to the set and tells whether it was already there
+func (s canonicalSet) add(canonical []byte) bool {
+	if s.contains(canonical) {
+		return true
+	}
+	hash := hashCanonical(canonical)
+	s[hash] = append(s[hash], canonical)
+	return false
+}
+
+func (s canonicalSet) contains(canonical []byte) bool {
+	for _, c := range s[hashCanonical(canonical)] {
+		if bytes.Equal(c, canonical) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,90 @@
+package jsonschema
+
+import (
+	"testing"
+)
+
+var canonicalizeTests = []struct {
+	doc      string
+	expected string
+}{
+	{doc: ` { "b" : [1.0, 2e1, -0], "a" : null } `, expected: `{"a":null,"b":[1,20,0]}`},
+	{doc: `{"z":{"y":1,"x":{"b":true,"a":false}},"a":"A\/\"\u001fæ"}`, expected: `{"a":"A/\"\u001fæ","z":{"x":{"a":false,"b":true},"y":1}}`},
+	{doc: `[1e21, 1e20, 123e-9, 0.000001, 1.5E+3, 0.10]`, expected: `[1e+21,100000000000000000000,1.23e-7,0.000001,1500,0.1]`},
+	{doc: `9007199254740993`, expected: `9007199254740993`},
+	{doc: `{"！":1,"😀":2,"\r":3,"1":4,"€":5}`, expected: `{"\r":3,"1":4,"€":5,"😀":2,"！":1}`},
+	{doc: `"tab\there"`, expected: `"tab\there"`},
+}
+
+func TestCanonicalize(t *testing.T) {
+	for i, tt := range canonicalizeTests {
+		canonical, err := Canonicalize([]byte(tt.doc))
+		if err != nil {
+			t.Fatalf("test #%d: %s", i+1, err)
+		}
+		if string(canonical) != tt.expected {
+			t.Fatalf("test #%d: expected:\n%s\ngot:\n%s", i+1, tt.expected, canonical)
+		}
+	}
+}
+
+var canonicalEqualTests = []struct {
+	a, b  string
+	equal bool
+}{
+	{a: `{"a":1}`, b: `{"a":1.0}`, equal: true},
+	{a: `{"a":[1,{"c":2,"b":1}]}`, b: `{"a":[1.0,{"b":1,"c":2}]}`, equal: true},
+	{a: `"a\"b"`, b: `"a\u0022b"`, equal: true},
+	{a: `9007199254740993`, b: `9007199254740992`},
+	{a: `[1,2]`, b: `[2,1]`},
+	{a: `{}`, b: `[]`},
+	{a: `0`, b: `false`},
+}
+
+func TestCanonicalEqual(t *testing.T) {
+	for i, tt := range canonicalEqualTests {
+		equal, err := CanonicalEqual([]byte(tt.a), []byte(tt.b))
+		if err != nil {
+			t.Fatalf("test #%d: %s", i+1, err)
+		}
+		if equal != tt.equal {
+			t.Fatalf("test #%d: expected %s and %s to be equal: %t", i+1, tt.a, tt.b, tt.equal)
+		}
+
+		hashA, _ := CanonicalHash([]byte(tt.a))
+		hashB, _ := CanonicalHash([]byte(tt.b))
+		if tt.equal && hashA != hashB {
+			t.Fatalf("test #%d: expected equal hashes, got: %d and %d", i+1, hashA, hashB)
+		}
+	}
+}
+
+var canonicalValidationTests = []struct {
+	schema string
+	doc    string
+	valid  bool
+}{
+	{schema: `{"enum": [{"a": 1}, "x"]}`, doc: `{"a": 1.0}`, valid: true},
+	{schema: `{"enum": [{"a": [1, {"b": 2, "c": 3}]}]}`, doc: `{"a": [1, {"c": 3, "b": 2}]}`, valid: true},
+	{schema: `{"enum": ["a\"b"]}`, doc: `"a\u0022b"`, valid: true},
+	{schema: `{"enum": [9007199254740993]}`, doc: `9007199254740992`},
+	{schema: `{"const": {"a": {"c": 1, "b": 2}}}`, doc: `{"a": {"b": 2.0, "c": 1}}`, valid: true},
+	{schema: `{"const": 1}`, doc: `true`},
+	{schema: `{"uniqueItems": true}`, doc: `[[1, 1], [2]]`, valid: true},
+	{schema: `{"uniqueItems": true}`, doc: `[{"a": "x\"y"}, {"a": "x\u0022y"}]`},
+	{schema: `{"uniqueItems": true}`, doc: `[{"a": 1, "b": 2}, {"b": 2, "a": 1.0}]`},
+	{schema: `{"uniqueItems": true}`, doc: `[1, "1", [1], {"1": 1}]`, valid: true},
+}
+
+func TestCanonicalValidation(t *testing.T) {
+	for i, tt := range canonicalValidationTests {
+		schema, err := NewFromString(tt.schema)
+		if err != nil {
+			t.Fatalf("test #%d: %s", i+1, err)
+		}
+		valid, err := schema.Validate([]byte(tt.doc))
+		if valid != tt.valid {
+			t.Fatalf("test #%d: expected %s to be valid against %s: %t, got: %t (%v)", i+1, tt.doc, tt.schema, tt.valid, valid, err)
+		}
+	}
+}
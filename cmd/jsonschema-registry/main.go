@@ -0,0 +1,43 @@
+// Command jsonschema-registry is a schema registry, that keeps versions of schemas in a directory.
+//
+// Usage:
+//
+//	jsonschema-registry [-addr :8080] [-dir schemas] [-compatibility backward]
+//
+// New versions are added with PUT /subjects/{subject} and must be valid against their
+// meta-schema, as well as compatible with the latest version of the subject.
+// Schemas can be fetched by subject and version, by $id or by the path of their $id,
+// so the registry can serve $refs, e.g. as the host of http://localhost:8080/person.json.
+// See the registry package for the routes.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+
+	"github.com/flowstack/go-jsonschema/registry"
+)
+
+func main() {
+	addr := flag.String("addr", ":8080", "the address to listen on")
+	dir := flag.String("dir", "schemas", "the directory to store schemas in")
+	compatibility := flag.String("compatibility", "backward", "the compatibility new versions must have: none, backward, forward or full")
+	flag.Parse()
+
+	mode, err := registry.ParseCompatibility(*compatibility)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	reg, err := registry.Open(*dir, mode)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	log.Printf("serving schemas from %s on %s", *dir, *addr)
+	log.Fatal(http.ListenAndServe(*addr, reg))
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/flowstack/go-jsonschema"
+	"github.com/flowstack/go-jsonschema/codegen"
+)
+
+func runGen(args []string) int {
+	flags := flag.NewFlagSet("gen", flag.ContinueOnError)
+	lang := flags.String("lang", "go", "the language to generate: go or typescript")
+	pkg := flags.String("package", "models", "the package name of the generated Go file")
+	rootType := flags.String("type", "", "the name of the root type (defaults to the schema title)")
+	output := flags.String("o", "", "the file to write to, instead of stdout")
+	flags.Usage = func() {
+		fmt.Fprint(flags.Output(), "Usage: jsonschema gen [-lang go|typescript] [-package models] [-type Root] [-o file.go] schema.json\n\n"+
+			"Generates Go or TypeScript types for the schema. Use it with go generate like this:\n\n"+
+			"\t//go:generate go run github.com/flowstack/go-jsonschema/cmd/jsonschema gen -package models -o models.go schema.json\n\n")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return exitUsage
+	}
+
+	// Bundling makes sure $refs to other files can be resolved
+	data, err := jsonschema.Bundle(flags.Arg(0))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitFailure
+	}
+
+	schema, err := jsonschema.New(data)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %s\n", flags.Arg(0), err)
+		return exitFailure
+	}
+	if err := schema.DeRef(); err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %s\n", flags.Arg(0), err)
+		return exitFailure
+	}
+
+	var src []byte
+	switch *lang {
+	case "go":
+		src, err = codegen.Go(schema, codegen.GoOptions{Package: *pkg, RootType: *rootType})
+	case "typescript", "ts":
+		src, err = codegen.TypeScript(schema, codegen.TypeScriptOptions{RootType: *rootType})
+	default:
+		fmt.Fprintf(os.Stderr, "unknown language: %s\n", *lang)
+		return exitUsage
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %s\n", flags.Arg(0), err)
+		return exitFailure
+	}
+
+	if *output == "" {
+		os.Stdout.Write(src)
+		return exitOK
+	}
+
+	if err := ioutil.WriteFile(*output, src, 0644); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitFailure
+	}
+	return exitOK
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"go/parser"
+	"go/token"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGen(t *testing.T) {
+	dir := t.TempDir()
+	schemaFile := filepath.Join(dir, "schema.json")
+	schema := `{"title":"Node","required":["next"],"properties":{"name":{"$ref":"defs.json#/definitions/name"},"next":{"$ref":"#"}}}`
+	if err := ioutil.WriteFile(schemaFile, []byte(schema), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "defs.json"), []byte(`{"definitions":{"name":{"type":"string"}}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		args     []string
+		code     int
+		goSource bool
+		expected []string
+	}{
+		{
+			args:     []string{"-package", "api"},
+			code:     exitOK,
+			goSource: true,
+			expected: []string{"package api", "type Node struct", "Next *Node"},
+		},
+		{
+			args:     []string{"-lang", "ts", "-type", "Item"},
+			code:     exitOK,
+			expected: []string{"export interface Item {", "next: Item;"},
+		},
+		{args: []string{"-lang", "cobol"}, code: exitUsage},
+	}
+
+	for _, tt := range tests {
+		output := filepath.Join(dir, "out")
+		args := append(append([]string{"-o", output}, tt.args...), schemaFile)
+		if code := runGen(args); code != tt.code {
+			t.Fatalf("%v: expected exit code %d, got: %d", tt.args, tt.code, code)
+		}
+		if tt.code != exitOK {
+			continue
+		}
+
+		src, err := ioutil.ReadFile(output)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, exp := range tt.expected {
+			if !strings.Contains(string(src), exp) {
+				t.Errorf("%v: expected output to contain %q, got:\n%s", tt.args, exp, src)
+			}
+		}
+		if tt.goSource {
+			if _, err := parser.ParseFile(token.NewFileSet(), "models.go", src, 0); err != nil {
+				t.Errorf("generated code doesn't parse: %s", err)
+			}
+		}
+	}
+}
+
+func TestGenErrors(t *testing.T) {
+	if code := runGen(nil); code != exitUsage {
+		t.Errorf("expected exit code %d without a schema, got: %d", exitUsage, code)
+	}
+	if code := runGen([]string{filepath.Join(t.TempDir(), "missing.json")}); code != exitFailure {
+		t.Errorf("expected exit code %d for a missing schema, got: %d", exitFailure, code)
+	}
+}
@@ -0,0 +1,72 @@
+// Command jsonschema exposes go-jsonschema on the command line.
+//
+// Usage:
+//
+//	jsonschema <command> [arguments]
+//
+// The commands are:
+//
+//	gen        generate Go or TypeScript types from a schema
+//	migrate    upgrade schemas to a newer draft
+//	validate   validate documents against a schema
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+)
+
+const (
+	exitOK      = 0
+	exitFailure = 1
+	exitUsage   = 2
+
+	// exitBadSchema is used when the schema itself can't be loaded or is invalid
+	exitBadSchema = 3
+
+	// exitUnreadable is used when a document can't be read
+	exitUnreadable = 4
+)
+
+type command struct {
+	run   func(args []string) int
+	short string
+}
+
+var commands = map[string]command{
+	"gen":      {run: runGen, short: "generate Go or TypeScript types from a schema"},
+	"migrate":  {run: runMigrate, short: "upgrade schemas to a newer draft"},
+	"validate": {run: runValidate, short: "validate documents against a schema"},
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(exitUsage)
+	}
+
+	cmd, ok := commands[os.Args[1]]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "jsonschema: unknown command %q\n", os.Args[1])
+		usage()
+		os.Exit(exitUsage)
+	}
+
+	os.Exit(cmd.run(os.Args[2:]))
+}
+
+func usage() {
+	fmt.Fprint(os.Stderr, "Usage:\n\n\tjsonschema <command> [arguments]\n\nThe commands are:\n\n")
+
+	names := []string{}
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		fmt.Fprintf(os.Stderr, "\t%-10s %s\n", name, commands[name].short)
+	}
+	fmt.Fprintln(os.Stderr)
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/flowstack/go-jsonschema"
+)
+
+func runMigrate(args []string) int {
+	flags := flag.NewFlagSet("migrate", flag.ContinueOnError)
+	draftName := flags.String("draft", jsonschema.LatestDraft.String(), "the draft to migrate to")
+	write := flags.Bool("w", false, "write the result back to the source files, instead of stdout")
+	flags.Usage = func() {
+		fmt.Fprint(flags.Output(), "Usage: jsonschema migrate [-draft 2020-12] [-w] [file ...]\n\nReads from stdin when no files are given.\n\n")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+
+	target, err := jsonschema.ParseDraft(*draftName)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitUsage
+	}
+
+	if flags.NArg() == 0 {
+		if *write {
+			fmt.Fprintln(os.Stderr, "jsonschema migrate: -w can't be used with stdin")
+			return exitUsage
+		}
+		data, err := ioutil.ReadAll(os.Stdin)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return exitFailure
+		}
+		return migrateFile("<stdin>", data, target, false)
+	}
+
+	status := exitOK
+	for _, filename := range flags.Args() {
+		data, err := ioutil.ReadFile(filename)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			status = exitFailure
+			continue
+		}
+		if s := migrateFile(filename, data, target, *write); s != exitOK {
+			status = s
+		}
+	}
+
+	return status
+}
+
+func migrateFile(filename string, data []byte, target jsonschema.Draft, write bool) int {
+	migrated, issues, err := jsonschema.Migrate(data, target)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %s\n", filename, err)
+		return exitFailure
+	}
+
+	for _, issue := range issues {
+		fmt.Fprintf(os.Stderr, "%s: %s\n", filename, issue)
+	}
+
+	out := &bytes.Buffer{}
+	if err := json.Indent(out, migrated, "", "  "); err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %s\n", filename, err)
+		return exitFailure
+	}
+	out.WriteByte('\n')
+
+	if write {
+		if err := ioutil.WriteFile(filename, out.Bytes(), 0644); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return exitFailure
+		}
+		return exitOK
+	}
+
+	os.Stdout.Write(out.Bytes())
+	return exitOK
+}
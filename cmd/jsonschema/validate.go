@@ -0,0 +1,249 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/buger/jsonparser"
+	"github.com/flowstack/go-jsonschema"
+)
+
+type validationResult struct {
+	File  string `json:"file"`
+	Valid bool   `json:"valid"`
+	Error string `json:"error,omitempty"`
+}
+
+func runValidate(args []string) int {
+	flags := flag.NewFlagSet("validate", flag.ContinueOnError)
+	schemaFile := flags.String("schema", "", "the schema to validate against (required)")
+	draftName := flags.String("draft", "", "the draft to use, if the schema has no $schema")
+	refDir := flags.String("ref-dir", "", "resolve remote $refs from this directory, instead of the network")
+	output := flags.String("output", "text", "the output format: text or json")
+	flags.Usage = func() {
+		fmt.Fprint(flags.Output(), "Usage: jsonschema validate -schema schema.json [-draft 2020-12] [-ref-dir dir] [-output text|json] [file|dir|glob ...]\n\n"+
+			"Validates JSON documents against a schema. Directories are searched for *.json files.\n"+
+			"Reads from stdin when no files are given, or the file is -.\n\n"+
+			"Exit codes: 0 all documents are valid, 1 a document is invalid, 2 usage error, 3 invalid schema,\n"+
+			"4 a document can't be read.\n\n")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+	if *schemaFile == "" {
+		fmt.Fprintln(os.Stderr, "jsonschema validate: -schema is required")
+		flags.Usage()
+		return exitUsage
+	}
+	if *output != "text" && *output != "json" {
+		fmt.Fprintf(os.Stderr, "jsonschema validate: unknown output format: %s\n", *output)
+		return exitUsage
+	}
+
+	var draft jsonschema.Draft
+	if *draftName != "" {
+		var err error
+		draft, err = jsonschema.ParseDraft(*draftName)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return exitUsage
+		}
+	}
+
+	if *refDir != "" {
+		jsonschema.Loader = dirLoader(*refDir)
+	}
+
+	schema, err := loadSchema(*schemaFile, draft)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %s\n", *schemaFile, err)
+		return exitBadSchema
+	}
+
+	files, err := expandFiles(flags.Args())
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitUnreadable
+	}
+
+	status := exitOK
+	results := []*validationResult{}
+	for _, file := range files {
+		res := &validationResult{File: file}
+		results = append(results, res)
+
+		var data []byte
+		if file == "-" {
+			res.File = "<stdin>"
+			data, err = ioutil.ReadAll(os.Stdin)
+		} else {
+			data, err = ioutil.ReadFile(file)
+		}
+		if err != nil {
+			// Unreadable documents take precedence over invalid ones
+			res.Error = err.Error()
+			status = exitUnreadable
+			if *output == "text" {
+				fmt.Printf("%s: unreadable: %s\n", res.File, res.Error)
+			}
+			continue
+		}
+
+		res.Valid, err = schema.Validate(data)
+		if err != nil {
+			res.Error = err.Error()
+			if status == exitOK {
+				status = exitFailure
+			}
+		}
+
+		if *output == "text" {
+			if res.Valid {
+				fmt.Printf("%s: valid\n", res.File)
+			} else {
+				fmt.Printf("%s: invalid: %s\n", res.File, res.Error)
+			}
+		}
+	}
+
+	if *output == "json" {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetEscapeHTML(false)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(results); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return exitFailure
+		}
+	}
+
+	return status
+}
+
+// loadSchema bundles the schema with any schemas it references, and sets $schema to draft,
+// if it's not set already
+func loadSchema(filename string, draft jsonschema.Draft) (*jsonschema.Schema, error) {
+	data, err := jsonschema.Bundle(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	if draft != jsonschema.DraftUnknown && jsonschema.DetectJSONType(data) == jsonschema.Object {
+		if _, _, _, err := jsonparser.Get(data, "$schema"); err == jsonparser.KeyPathNotFoundError {
+			data, err = jsonparser.Set(data, []byte(`"`+draft.URI()+`"`), "$schema")
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	schema, err := jsonschema.New(data)
+	if err != nil {
+		return nil, err
+	}
+	meta, err := jsonschema.MetaSchema(schema.Draft())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %s, so the schema isn't checked against it\n", filename, err)
+	} else if _, err := meta.Validate(data); err != nil {
+		return nil, fmt.Errorf("schema is not valid against its meta-schema: %w", err)
+	}
+
+	// Resolve every $ref up front, so a broken $ref is reported as a bad schema
+	if err := schema.DeRef(); err != nil {
+		return nil, err
+	}
+
+	return schema, nil
+}
+
+// expandFiles expands directories and glob patterns into the files they contain
+func expandFiles(args []string) ([]string, error) {
+	if len(args) == 0 {
+		return []string{"-"}, nil
+	}
+
+	files := []string{}
+	for _, arg := range args {
+		if arg == "-" {
+			files = append(files, arg)
+			continue
+		}
+
+		matches := []string{arg}
+		if strings.ContainsAny(arg, "*?[") {
+			var err error
+			matches, err = filepath.Glob(arg)
+			if err != nil {
+				return nil, err
+			}
+			if len(matches) == 0 {
+				return nil, fmt.Errorf("no files matching %s", arg)
+			}
+		}
+
+		for _, match := range matches {
+			info, err := os.Stat(match)
+			if err != nil {
+				return nil, err
+			}
+			if !info.IsDir() {
+				files = append(files, match)
+				continue
+			}
+
+			err = filepath.Walk(match, func(p string, info os.FileInfo, err error) error {
+				if err != nil {
+					return err
+				}
+				if !info.IsDir() && strings.EqualFold(filepath.Ext(p), ".json") {
+					files = append(files, p)
+				}
+				return nil
+			})
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return files, nil
+}
+
+// dirLoader loads remote schemas from dir, so $refs can be resolved offline.
+// The URI http://example.com/schemas/a.json is looked up as dir/example.com/schemas/a.json,
+// then dir/schemas/a.json and finally dir/a.json.
+func dirLoader(dir string) jsonschema.LoaderFunc {
+	return func(uri string) ([]byte, error) {
+		u, err := url.Parse(uri)
+		if err != nil {
+			return nil, err
+		}
+		if u.Scheme != "http" && u.Scheme != "https" {
+			return jsonschema.DefaultLoader(uri)
+		}
+
+		candidates := []string{
+			path.Join(u.Host, u.Path),
+			u.Path,
+			path.Base(u.Path),
+		}
+		for _, candidate := range candidates {
+			data, err := ioutil.ReadFile(filepath.Join(dir, filepath.FromSlash(candidate)))
+			if err == nil {
+				return data, nil
+			}
+			if !os.IsNotExist(err) {
+				return nil, err
+			}
+		}
+
+		return nil, fmt.Errorf("unable to find %s in %s", uri, dir)
+	}
+}
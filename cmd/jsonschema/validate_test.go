@@ -0,0 +1,130 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/flowstack/go-jsonschema"
+)
+
+// writeFiles writes the files to dir, creating directories as needed
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		file := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(file, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestValidateExitCodes(t *testing.T) {
+	defaultLoader := jsonschema.Loader
+	defer func() { jsonschema.Loader = defaultLoader }()
+
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"schema.json":                     `{"properties":{"name":{"$ref":"http://example.com/defs/name.json"}}}`,
+		"bad-schema.json":                 `{"type":12}`,
+		"new-schema.json":                 `{"$schema":"https://json-schema.org/draft/2020-12/schema","type":"object"}`,
+		"refs/example.com/defs/name.json": `{"type":"string"}`,
+		"docs/valid.json":                 `{"name":"a"}`,
+		"docs/invalid.json":               `{"name":1}`,
+	})
+	path := func(name string) string { return filepath.Join(dir, filepath.FromSlash(name)) }
+
+	tests := []struct {
+		args []string
+		code int
+	}{
+		{args: []string{"-schema", path("schema.json"), "-ref-dir", path("refs"), path("docs/valid.json")}, code: exitOK},
+		{args: []string{"-schema", path("schema.json"), "-ref-dir", path("refs"), path("docs")}, code: exitFailure},
+		{args: []string{"-schema", path("schema.json"), "-ref-dir", path("refs"), path("docs/valid.json"), path("docs/missing.json")}, code: exitUnreadable},
+		{args: []string{"-schema", path("schema.json"), "-ref-dir", path("refs"), path("docs/*.txt")}, code: exitUnreadable},
+		{args: []string{"-schema", path("bad-schema.json"), path("docs/valid.json")}, code: exitBadSchema},
+		{args: []string{"-schema", path("new-schema.json"), path("docs/valid.json")}, code: exitOK},
+		{args: []string{"-schema", path("schema.json"), "-ref-dir", path("missing"), path("docs/valid.json")}, code: exitBadSchema},
+		{args: []string{"-schema", path("schema.json"), "-output", "xml"}, code: exitUsage},
+		{args: []string{path("docs/valid.json")}, code: exitUsage},
+	}
+
+	for _, tt := range tests {
+		jsonschema.Loader = defaultLoader
+		if code := runValidate(tt.args); code != tt.code {
+			t.Errorf("%v: expected exit code %d, got: %d", tt.args, tt.code, code)
+		}
+	}
+}
+
+func TestExpandFiles(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"a.json":       `{}`,
+		"b.txt":        ``,
+		"sub/c.json":   `{}`,
+		"sub/d/e.JSON": `{}`,
+	})
+
+	files, err := expandFiles([]string{filepath.Join(dir, "*.json"), filepath.Join(dir, "sub"), "-"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{
+		filepath.Join(dir, "a.json"),
+		filepath.Join(dir, "sub", "c.json"),
+		filepath.Join(dir, "sub", "d", "e.JSON"),
+		"-",
+	}
+	if !reflect.DeepEqual(files, expected) {
+		t.Errorf("expected: %v, got: %v", expected, files)
+	}
+
+	if files, _ := expandFiles(nil); !reflect.DeepEqual(files, []string{"-"}) {
+		t.Errorf("expected stdin without arguments, got: %v", files)
+	}
+	if _, err := expandFiles([]string{filepath.Join(dir, "*.yaml")}); err == nil {
+		t.Errorf("expected an error for a glob without matches")
+	}
+	if _, err := expandFiles([]string{filepath.Join(dir, "missing.json")}); err == nil {
+		t.Errorf("expected an error for a missing file")
+	}
+}
+
+func TestDirLoader(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"example.com/schemas/a.json": `"host"`,
+		"schemas/b.json":             `"path"`,
+		"c.json":                     `"base"`,
+	})
+	load := dirLoader(dir)
+
+	tests := map[string]string{
+		"http://example.com/schemas/a.json": `"host"`,
+		"https://other.com/schemas/b.json":  `"path"`,
+		"http://other.com/deep/path/c.json": `"base"`,
+	}
+	for uri, expected := range tests {
+		data, err := load(uri)
+		if err != nil {
+			t.Errorf("%s: %s", uri, err)
+		} else if string(data) != expected {
+			t.Errorf("%s: expected %s, got: %s", uri, expected, data)
+		}
+	}
+
+	if _, err := load("http://example.com/missing.json"); err == nil {
+		t.Errorf("expected an error for a missing schema")
+	}
+
+	// Anything but http(s) is loaded as usual
+	if data, err := load("file://" + filepath.ToSlash(filepath.Join(dir, "c.json"))); err != nil || string(data) != `"base"` {
+		t.Errorf("expected a file to be loaded, got: %s (%v)", data, err)
+	}
+}
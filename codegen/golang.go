@@ -0,0 +1,537 @@
+// Package codegen generates source code from parsed JSON Schemas.
+package codegen
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"sort"
+	"strings"
+
+	"github.com/flowstack/go-jsonschema"
+)
+
+// GoOptions controls the output of Go
+type GoOptions struct {
+	// Package is the name of the generated package. Defaults to "models".
+	Package string
+
+	// RootType is the name of the type generated for the root schema.
+	// Defaults to the title of the schema, or "Root" if there is no title.
+	RootType string
+}
+
+// sealedType is a oneOf with a discriminator, which becomes an interface, implemented by each branch
+type sealedType struct {
+	property string
+	field    string
+	branches []sealedBranch
+}
+
+type sealedBranch struct {
+	value    string
+	typeName string
+}
+
+type goField struct {
+	name     string
+	jsonName string
+	typ      string
+	optional bool
+	doc      string
+
+	// sealed is the name of the sealed interface, if the field is, or is a slice of, one
+	sealed string
+	slice  bool
+}
+
+type goGenerator struct {
+	opts GoOptions
+
+	names   *typeNames
+	imports map[string]struct{}
+	sealed  map[*jsonschema.Schema]*sealedType
+
+	// queue holds named schemas, that still needs to be declared
+	queue []*jsonschema.Schema
+	done  map[*jsonschema.Schema]struct{}
+	decls []string
+}
+
+// Go generates Go types for the schema and its definitions.
+// Objects become structs, with pointer fields for optional properties, enums become typed
+// constants, oneOf with a discriminator becomes a sealed interface, and definitions become
+// named types.
+// The schema should have its $refs resolved (see Schema.DeRef), if they point to remote schemas.
+func Go(schema *jsonschema.Schema, opts GoOptions) ([]byte, error) {
+	if schema == nil {
+		return nil, fmt.Errorf("no schema supplied")
+	}
+	if opts.Package == "" {
+		opts.Package = "models"
+	}
+
+	g := &goGenerator{
+		opts:    opts,
+		names:   newTypeNames(),
+		imports: map[string]struct{}{},
+		sealed:  map[*jsonschema.Schema]*sealedType{},
+		done:    map[*jsonschema.Schema]struct{}{},
+	}
+
+	rootName := opts.RootType
+	if rootName == "" && schema.Title != nil {
+		rootName = exportedName(*schema.Title)
+	}
+	if rootName == "" {
+		rootName = "Root"
+	}
+	g.names.register(schema, rootName)
+	g.queue = append(g.queue, schema)
+
+	for _, def := range definitions(schema) {
+		g.names.register(def.Property, exportedName(def.Name))
+		g.queue = append(g.queue, def.Property)
+	}
+
+	for len(g.queue) > 0 {
+		s := g.queue[0]
+		g.queue = g.queue[1:]
+		if _, ok := g.done[s]; ok {
+			continue
+		}
+		g.done[s] = struct{}{}
+
+		if err := g.declare(s); err != nil {
+			return nil, err
+		}
+	}
+
+	buf := &bytes.Buffer{}
+	fmt.Fprintf(buf, "// Code generated by jsonschema gen; DO NOT EDIT.\n\npackage %s\n\n", opts.Package)
+
+	if len(g.imports) > 0 {
+		imports := []string{}
+		for imp := range g.imports {
+			imports = append(imports, imp)
+		}
+		sort.Strings(imports)
+		fmt.Fprintf(buf, "import (\n")
+		for _, imp := range imports {
+			fmt.Fprintf(buf, "\t%q\n", imp)
+		}
+		fmt.Fprintf(buf, ")\n\n")
+	}
+
+	buf.WriteString(strings.Join(g.decls, "\n"))
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("generated invalid Go code: %w\n%s", err, buf.String())
+	}
+	return src, nil
+}
+
+// declare writes the named type for s
+func (g *goGenerator) declare(s *jsonschema.Schema) error {
+	name := g.names.get(s)
+	buf := &bytes.Buffer{}
+	writeGoDoc(buf, "", s.Description)
+
+	if sealed := g.sealedType(s); sealed != nil {
+		g.declareSealed(buf, name, sealed)
+		g.decls = append(g.decls, buf.String())
+		return nil
+	}
+
+	if values, typ := enumValues(s); values != nil {
+		fmt.Fprintf(buf, "type %s %s\n\nconst (\n", name, typ)
+		for _, val := range values {
+			constName := name + exportedName(strings.Trim(val, `"`))
+			if strings.Trim(val, `"`) == "" {
+				constName = name + "Empty"
+			}
+			fmt.Fprintf(buf, "\t%s %s = %s\n", g.names.unique(constName), name, val)
+		}
+		fmt.Fprintf(buf, ")\n")
+		g.decls = append(g.decls, buf.String())
+		return nil
+	}
+
+	if isStruct(s) {
+		fields, err := g.fields(s, name)
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintf(buf, "type %s struct {\n", name)
+		for _, f := range fields {
+			writeGoDoc(buf, "\t", stringPtr(f.doc))
+			tag := f.jsonName
+			if f.optional {
+				tag += ",omitempty"
+			}
+			fmt.Fprintf(buf, "\t%s %s `json:%q`\n", f.name, f.typ, tag)
+		}
+		fmt.Fprintf(buf, "}\n")
+
+		g.declareUnmarshalSealed(buf, name, fields)
+		g.decls = append(g.decls, buf.String())
+		return nil
+	}
+
+	typ, err := g.goType(s, name+"Value", true)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(buf, "type %s %s\n", name, typ)
+	g.decls = append(g.decls, buf.String())
+
+	return nil
+}
+
+func (g *goGenerator) fields(s *jsonschema.Schema, name string) ([]*goField, error) {
+	required := map[string]bool{}
+	fields := []*goField{}
+	seen := map[string]bool{}
+	used := map[string]bool{}
+
+	// Properties from allOf are merged into the struct
+	schemas := []*jsonschema.Schema{s}
+	if s.AllOf != nil {
+		for _, sub := range *s.AllOf {
+			sub, err := resolve(sub)
+			if err != nil {
+				return nil, err
+			}
+			schemas = append(schemas, sub)
+		}
+	}
+
+	for _, sub := range schemas {
+		if sub.Required != nil {
+			for _, req := range *sub.Required {
+				required[*req] = true
+			}
+		}
+	}
+
+	for _, sub := range schemas {
+		if sub.Properties == nil {
+			continue
+		}
+		for _, prop := range *sub.Properties {
+			if seen[prop.Name] {
+				continue
+			}
+			seen[prop.Name] = true
+
+			fieldName := exportedName(prop.Name)
+			for i := 2; used[fieldName]; i++ {
+				fieldName = fmt.Sprintf("%s%d", exportedName(prop.Name), i)
+			}
+			used[fieldName] = true
+
+			propSchema, err := resolve(prop.Property)
+			if err != nil {
+				return nil, err
+			}
+
+			typ, err := g.goType(prop.Property, name+fieldName, false)
+			if err != nil {
+				return nil, err
+			}
+
+			f := &goField{
+				name:     fieldName,
+				jsonName: prop.Name,
+				typ:      typ,
+				optional: !required[prop.Name],
+			}
+			if propSchema != nil && propSchema.Description != nil {
+				f.doc = *propSchema.Description
+			} else if prop.Property.Description != nil {
+				f.doc = *prop.Property.Description
+			}
+
+			if g.sealedType(propSchema) != nil {
+				f.sealed = g.names.get(propSchema)
+			} else if isArray(propSchema) && propSchema.Items != nil && propSchema.Items.Schema != nil {
+				items, err := resolve(propSchema.Items.Schema)
+				if err != nil {
+					return nil, err
+				}
+				if g.sealedType(items) != nil {
+					f.sealed = g.names.get(items)
+					f.slice = true
+				}
+			}
+
+			// Sealed interfaces are already nilable
+			if f.optional && !isNullable(f.typ) && (f.sealed == "" || f.slice) {
+				f.typ = "*" + f.typ
+			}
+
+			// A struct can't contain itself, so a required field, that leads back to the struct, is a pointer
+			if !f.optional && !isNullable(f.typ) && isStruct(propSchema) &&
+				(propSchema == s || embeds(propSchema, s, map[*jsonschema.Schema]bool{})) {
+				f.typ = "*" + f.typ
+			}
+
+			fields = append(fields, f)
+		}
+	}
+
+	return fields, nil
+}
+
+// embeds tells whether the struct for s contains the struct for target by value,
+// i.e. through required struct fields, which aren't pointers, slices or maps
+func embeds(s, target *jsonschema.Schema, seen map[*jsonschema.Schema]bool) bool {
+	if seen[s] {
+		return false
+	}
+	seen[s] = true
+
+	schemas := []*jsonschema.Schema{s}
+	if s.AllOf != nil {
+		for _, sub := range *s.AllOf {
+			if sub, err := resolve(sub); err == nil && sub != nil {
+				schemas = append(schemas, sub)
+			}
+		}
+	}
+
+	required := map[string]bool{}
+	for _, sub := range schemas {
+		if sub.Required != nil {
+			for _, req := range *sub.Required {
+				required[*req] = true
+			}
+		}
+	}
+
+	for _, sub := range schemas {
+		if sub.Properties == nil {
+			continue
+		}
+		for _, prop := range *sub.Properties {
+			if !required[prop.Name] {
+				continue
+			}
+			propSchema, err := resolve(prop.Property)
+			if err != nil || !isStruct(propSchema) {
+				continue
+			}
+			if propSchema == target || embeds(propSchema, target, seen) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// goType returns the Go type for s.
+// Objects, enums and discriminated oneOfs gets a named type, based on nameHint.
+// If declaring is true, s is being declared as nameHint, so only the underlying type is returned.
+func (g *goGenerator) goType(s *jsonschema.Schema, nameHint string, declaring bool) (string, error) {
+	if s == nil {
+		return "interface{}", nil
+	}
+
+	if s.Ref != nil {
+		target, err := resolve(s)
+		if err != nil {
+			return "", err
+		}
+		if name := g.names.get(target); name != "" {
+			return name, nil
+		}
+		if name := refName(s); name != "" {
+			nameHint = name
+		}
+		s = target
+	}
+
+	if !declaring {
+		if name := g.names.get(s); name != "" {
+			return name, nil
+		}
+
+		if values, _ := enumValues(s); values != nil || isStruct(s) || g.sealedType(s) != nil {
+			g.names.register(s, nameHint)
+			g.queue = append(g.queue, s)
+			return g.names.get(s), nil
+		}
+	}
+
+	switch typeName(s) {
+	case "string":
+		if s.Format != nil && *s.Format == "date-time" {
+			g.imports["time"] = struct{}{}
+			return "time.Time", nil
+		}
+		return "string", nil
+	case "integer":
+		return "int64", nil
+	case "number":
+		return "float64", nil
+	case "boolean":
+		return "bool", nil
+	case "array":
+		if s.Items == nil || s.Items.Schema == nil {
+			return "[]interface{}", nil
+		}
+		itemType, err := g.goType(s.Items.Schema, nameHint+"Item", false)
+		if err != nil {
+			return "", err
+		}
+		return "[]" + itemType, nil
+	case "object":
+		if s.AdditionalProperties != nil && !s.AdditionalProperties.IsEmpty() && s.AdditionalProperties.String() != "false" {
+			valueType, err := g.goType(s.AdditionalProperties, nameHint+"Value", false)
+			if err != nil {
+				return "", err
+			}
+			return "map[string]" + valueType, nil
+		}
+		return "map[string]interface{}", nil
+	}
+
+	return "interface{}", nil
+}
+
+// sealedType returns the sealed interface for a oneOf with a discriminator, or nil
+func (g *goGenerator) sealedType(s *jsonschema.Schema) *sealedType {
+	if sealed, ok := g.sealed[s]; ok {
+		return sealed
+	}
+	g.sealed[s] = nil
+
+	if s == nil || s.OneOf == nil || len(*s.OneOf) == 0 {
+		return nil
+	}
+
+	property := discriminatorProperty(s)
+	if property == "" {
+		return nil
+	}
+
+	sealed := &sealedType{property: property, field: exportedName(property)}
+	for i, branch := range *s.OneOf {
+		target, err := resolve(branch)
+		if err != nil || !isStruct(target) {
+			return nil
+		}
+
+		value := constValue(target, property)
+		if value == "" {
+			return nil
+		}
+
+		name := g.names.get(target)
+		if name == "" {
+			hint := refName(branch)
+			if hint == "" {
+				hint = exportedName(value)
+			}
+			if hint == "" {
+				hint = fmt.Sprintf("Variant%d", i+1)
+			}
+			g.names.register(target, hint)
+			name = g.names.get(target)
+		}
+		g.queue = append(g.queue, target)
+
+		sealed.branches = append(sealed.branches, sealedBranch{value: value, typeName: name})
+	}
+
+	g.sealed[s] = sealed
+	return sealed
+}
+
+func (g *goGenerator) declareSealed(buf *bytes.Buffer, name string, sealed *sealedType) {
+	g.imports["encoding/json"] = struct{}{}
+	g.imports["fmt"] = struct{}{}
+
+	typeNames := []string{}
+	for _, branch := range sealed.branches {
+		typeNames = append(typeNames, branch.typeName)
+	}
+
+	if buf.Len() == 0 {
+		fmt.Fprintf(buf, "// %s is one of: %s\n", name, strings.Join(typeNames, ", "))
+	}
+	fmt.Fprintf(buf, "type %s interface {\n\tis%s()\n}\n\n", name, name)
+	for _, typeName := range typeNames {
+		fmt.Fprintf(buf, "func (%s) is%s() {}\n\n", typeName, name)
+	}
+
+	fmt.Fprintf(buf, "// Unmarshal%s decodes data into the %s matching the %q property\n", name, name, sealed.property)
+	fmt.Fprintf(buf, "func Unmarshal%s(data []byte) (%s, error) {\n", name, name)
+	fmt.Fprintf(buf, "\tvar probe struct {\n\t\t%s string `json:%q`\n\t}\n", sealed.field, sealed.property)
+	fmt.Fprintf(buf, "\tif err := json.Unmarshal(data, &probe); err != nil {\n\t\treturn nil, err\n\t}\n\n")
+	fmt.Fprintf(buf, "\tswitch probe.%s {\n", sealed.field)
+	for _, branch := range sealed.branches {
+		fmt.Fprintf(buf, "\tcase %q:\n\t\tvar v %s\n\t\terr := json.Unmarshal(data, &v)\n\t\treturn v, err\n", branch.value, branch.typeName)
+	}
+	fmt.Fprintf(buf, "\t}\n\n\treturn nil, fmt.Errorf(\"unknown %s: %%q\", probe.%s)\n}\n", sealed.property, sealed.field)
+}
+
+// declareUnmarshalSealed adds an UnmarshalJSON method to structs with sealed interface fields,
+// as encoding/json can't decode into interfaces by itself
+func (g *goGenerator) declareUnmarshalSealed(buf *bytes.Buffer, name string, fields []*goField) {
+	sealedFields := []*goField{}
+	for _, f := range fields {
+		if f.sealed != "" {
+			sealedFields = append(sealedFields, f)
+		}
+	}
+	if len(sealedFields) == 0 {
+		return
+	}
+
+	g.imports["encoding/json"] = struct{}{}
+
+	fmt.Fprintf(buf, "\n// UnmarshalJSON decodes the sealed interface fields of %s\n", name)
+	fmt.Fprintf(buf, "func (t *%s) UnmarshalJSON(data []byte) error {\n\ttype plain %s\n\tvar aux struct {\n\t\t*plain\n", name, name)
+	for _, f := range sealedFields {
+		typ := "json.RawMessage"
+		if f.slice {
+			typ = "[]json.RawMessage"
+		}
+		fmt.Fprintf(buf, "\t\t%s %s `json:%q`\n", f.name, typ, f.jsonName+",omitempty")
+	}
+	fmt.Fprintf(buf, "\t}\n\taux.plain = (*plain)(t)\n\tif err := json.Unmarshal(data, &aux); err != nil {\n\t\treturn err\n\t}\n")
+
+	for _, f := range sealedFields {
+		sealedName := f.sealed
+		if f.slice {
+			fmt.Fprintf(buf, "\tt.%s = nil\n\tfor _, raw := range aux.%s {\n\t\tv, err := Unmarshal%s(raw)\n\t\tif err != nil {\n\t\t\treturn err\n\t\t}\n\t\tt.%s = append(t.%s, v)\n\t}\n",
+				f.name, f.name, sealedName, f.name, f.name)
+		} else {
+			fmt.Fprintf(buf, "\tif aux.%s != nil {\n\t\tv, err := Unmarshal%s(aux.%s)\n\t\tif err != nil {\n\t\t\treturn err\n\t\t}\n\t\tt.%s = v\n\t}\n",
+				f.name, sealedName, f.name, f.name)
+		}
+	}
+	fmt.Fprintf(buf, "\treturn nil\n}\n")
+}
+
+// isNullable reports whether the zero value of typ is nil
+func isNullable(typ string) bool {
+	return strings.HasPrefix(typ, "[]") || strings.HasPrefix(typ, "map[") || strings.HasPrefix(typ, "*") || typ == "interface{}"
+}
+
+func writeGoDoc(buf *bytes.Buffer, indent string, doc *string) {
+	if doc == nil || *doc == "" {
+		return
+	}
+	for _, line := range strings.Split(strings.TrimSpace(*doc), "\n") {
+		fmt.Fprintf(buf, "%s// %s\n", indent, strings.TrimRight(line, " \t\r"))
+	}
+}
+
+func stringPtr(str string) *string {
+	return &str
+}
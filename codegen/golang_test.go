@@ -0,0 +1,114 @@
+package codegen
+
+import (
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"strings"
+	"testing"
+
+	"github.com/flowstack/go-jsonschema"
+)
+
+var goTests = []struct {
+	schema   string
+	opts     GoOptions
+	expected []string
+}{
+	{
+		schema: `{"title":"user","description":"A user","type":"object","required":["id"],"properties":{"id":{"type":"string"},"home_url":{"type":"string","description":"Where they live"},"created":{"type":"string","format":"date-time"},"tags":{"type":"array","items":{"type":"string"}}}}`,
+		expected: []string{
+			"package models",
+			"\"time\"",
+			"// A user\ntype User struct {",
+			"ID string `json:\"id\"`",
+			"// Where they live\n\tHomeURL *string `json:\"home_url,omitempty\"`",
+			"Created *time.Time `json:\"created,omitempty\"`",
+			"Tags []string `json:\"tags,omitempty\"`",
+		},
+	},
+	{
+		schema: `{"type":"object","properties":{"status":{"$ref":"#/$defs/status"},"count":{"type":"integer"}},"$defs":{"status":{"enum":["on","off"]}}}`,
+		opts:   GoOptions{Package: "api", RootType: "Device"},
+		expected: []string{
+			"package api",
+			"type Device struct {",
+			"Status *Status `json:\"status,omitempty\"`",
+			"Count *int64 `json:\"count,omitempty\"`",
+			"type Status string",
+			"StatusOn  Status = \"on\"",
+			"StatusOff Status = \"off\"",
+		},
+	},
+	{
+		schema: `{"type":"object","required":["shape"],"properties":{"shape":{"$ref":"#/definitions/shape"}},"definitions":{"shape":{"oneOf":[{"$ref":"#/definitions/circle"},{"$ref":"#/definitions/square"}]},"circle":{"properties":{"kind":{"const":"circle"},"radius":{"type":"number"}}},"square":{"properties":{"kind":{"const":"square"},"side":{"type":"number"}}}}}`,
+		expected: []string{
+			"type Root struct {",
+			"Shape Shape `json:\"shape\"`",
+			"func (t *Root) UnmarshalJSON(data []byte) error {",
+			"type Shape interface {\n\tisShape()\n}",
+			"func (Circle) isShape() {}",
+			"func UnmarshalShape(data []byte) (Shape, error) {",
+			"case \"square\":\n\t\tvar v Square",
+			"type Circle struct {",
+			"Radius *float64 `json:\"radius,omitempty\"`",
+		},
+	},
+	{
+		schema: `{"title":"Node","required":["value","next"],"properties":{"value":{"type":"integer"},"next":{"$ref":"#"}}}`,
+		expected: []string{
+			"Value int64 `json:\"value\"`",
+			"Next *Node `json:\"next\"`",
+		},
+	},
+	{
+		schema: `{"$ref":"#/definitions/a","definitions":{"a":{"required":["b"],"properties":{"b":{"$ref":"#/definitions/b"}}},"b":{"required":["a","c"],"properties":{"a":{"$ref":"#/definitions/a"},"c":{"$ref":"#/definitions/c"}}},"c":{"required":["name"],"properties":{"name":{"type":"string"}}}}}`,
+		expected: []string{
+			"B *B `json:\"b\"`",
+			"A *A `json:\"a\"`",
+			"C C `json:\"c\"`",
+		},
+	},
+}
+
+func TestGo(t *testing.T) {
+	for _, tt := range goTests {
+		s, err := jsonschema.New([]byte(tt.schema))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		src, err := Go(s, tt.opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		typeCheck(t, src)
+
+		// Compare without the alignment gofmt adds
+		normalized := strings.Join(strings.Fields(string(src)), " ")
+		for _, exp := range tt.expected {
+			if !strings.Contains(normalized, strings.Join(strings.Fields(exp), " ")) {
+				t.Fatalf("expected generated code to contain:\n%s\ngot:\n%s", exp, src)
+			}
+		}
+	}
+}
+
+// typeCheck fails the test, if src isn't valid Go, e.g. because of an invalid recursive type
+func typeCheck(t *testing.T, src []byte) {
+	t.Helper()
+
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "models.go", src, 0)
+	if err != nil {
+		t.Fatalf("generated code doesn't parse: %s\n%s", err, src)
+	}
+
+	conf := types.Config{Importer: importer.Default()}
+	if _, err := conf.Check("models", fset, []*ast.File{file}, nil); err != nil {
+		t.Fatalf("generated code doesn't type check: %s\n%s", err, src)
+	}
+}
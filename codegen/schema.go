@@ -0,0 +1,279 @@
+package codegen
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/flowstack/go-jsonschema"
+)
+
+// Common initialisms, that are written in upper case in identifiers
+var commonInitialisms = map[string]struct{}{
+	"API": {}, "CSS": {}, "DNS": {}, "HTML": {}, "HTTP": {}, "HTTPS": {}, "ID": {}, "IP": {},
+	"JSON": {}, "SQL": {}, "TCP": {}, "TLS": {}, "UDP": {}, "UI": {}, "URI": {}, "URL": {},
+	"UUID": {}, "XML": {},
+}
+
+// typeNames keeps track of the names given to schemas, and ensures they're unique
+type typeNames struct {
+	bySchema map[*jsonschema.Schema]string
+	used     map[string]struct{}
+}
+
+func newTypeNames() *typeNames {
+	return &typeNames{bySchema: map[*jsonschema.Schema]string{}, used: map[string]struct{}{}}
+}
+
+func (t *typeNames) register(s *jsonschema.Schema, name string) {
+	if _, ok := t.bySchema[s]; ok {
+		return
+	}
+	t.bySchema[s] = t.unique(name)
+}
+
+func (t *typeNames) get(s *jsonschema.Schema) string {
+	return t.bySchema[s]
+}
+
+// unique returns name, or name with a number appended, if name is already used
+func (t *typeNames) unique(name string) string {
+	if name == "" {
+		name = "Type"
+	}
+	unique := name
+	for i := 2; ; i++ {
+		if _, ok := t.used[unique]; !ok {
+			break
+		}
+		unique = name + strconv.Itoa(i)
+	}
+	t.used[unique] = struct{}{}
+	return unique
+}
+
+// definitions returns the named schemas of definitions and $defs
+func definitions(s *jsonschema.Schema) []*jsonschema.NamedProperty {
+	defs := []*jsonschema.NamedProperty{}
+	if s.Definitions != nil {
+		defs = append(defs, *s.Definitions...)
+	}
+	if s.Defs != nil {
+		defs = append(defs, *s.Defs...)
+	}
+	return defs
+}
+
+// resolve follows $refs until it finds a schema without one
+func resolve(s *jsonschema.Schema) (*jsonschema.Schema, error) {
+	for i := 0; s != nil && s.Ref != nil; i++ {
+		if i > 100 {
+			return nil, errors.New("too many nested $refs")
+		}
+
+		target := s.Ref.Schema
+		if target == nil {
+			var err error
+			target, err = s.ResolveRef(s.Ref)
+			if err != nil {
+				return nil, err
+			}
+		}
+		s = target
+	}
+	return s, nil
+}
+
+// refName returns a name for the target of a $ref, e.g. "Item" for #/definitions/item
+func refName(s *jsonschema.Schema) string {
+	if s == nil || s.Ref == nil || s.Ref.String == nil {
+		return ""
+	}
+
+	ref := *s.Ref.String
+	idx := strings.LastIndex(ref, "/")
+	if idx < 0 || idx == len(ref)-1 {
+		return ""
+	}
+	if !strings.Contains(ref, "/definitions/") && !strings.Contains(ref, "/$defs/") {
+		return ""
+	}
+
+	name := strings.ReplaceAll(ref[idx+1:], "~1", "/")
+	return exportedName(strings.ReplaceAll(name, "~0", "~"))
+}
+
+// typeName returns the (first non-null) type of s, or infers it from the keywords used
+func typeName(s *jsonschema.Schema) string {
+	if s == nil {
+		return ""
+	}
+
+	if s.Type != nil {
+		if s.Type.String != nil {
+			return *s.Type.String
+		}
+		if s.Type.Strings != nil {
+			for _, typ := range *s.Type.Strings {
+				if *typ != "null" {
+					return *typ
+				}
+			}
+		}
+	}
+
+	switch {
+	case s.Properties != nil || s.AdditionalProperties != nil:
+		return "object"
+	case s.Items != nil:
+		return "array"
+	case s.Const != nil && s.Const.String != nil:
+		return "string"
+	}
+
+	return ""
+}
+
+// isNullableType reports whether s allows null, besides its other type
+func isNullableType(s *jsonschema.Schema) bool {
+	if s == nil || s.Type == nil || s.Type.Strings == nil {
+		return false
+	}
+	for _, typ := range *s.Type.Strings {
+		if *typ == "null" {
+			return true
+		}
+	}
+	return false
+}
+
+func isStruct(s *jsonschema.Schema) bool {
+	if s == nil {
+		return false
+	}
+	typ := typeName(s)
+	if typ != "object" && typ != "" {
+		return false
+	}
+	if s.Properties != nil {
+		return true
+	}
+	if s.AllOf != nil {
+		for _, sub := range *s.AllOf {
+			if sub, err := resolve(sub); err == nil && isStruct(sub) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func isArray(s *jsonschema.Schema) bool {
+	return typeName(s) == "array"
+}
+
+// enumValues returns the values of a string or integer enum as Go literals, and the underlying type
+func enumValues(s *jsonschema.Schema) ([]string, string) {
+	if s == nil || s.Enum == nil || len(*s.Enum) == 0 {
+		return nil, ""
+	}
+
+	values := []string{}
+	typ := ""
+	for _, val := range *s.Enum {
+		switch {
+		case val.String != nil && (typ == "" || typ == "string"):
+			typ = "string"
+			values = append(values, strconv.Quote(*val.String))
+		case val.Number != nil && val.Number.IsInt() && (typ == "" || typ == "int64"):
+			typ = "int64"
+			values = append(values, val.Number.Text('f', 0))
+		default:
+			return nil, ""
+		}
+	}
+
+	return values, typ
+}
+
+// discriminatorProperty returns the property deciding which oneOf branch applies.
+// It is either set explicitly with the OpenAPI discriminator keyword,
+// or found as a property, that has a const value in every branch.
+func discriminatorProperty(s *jsonschema.Schema) string {
+	if disc, err := s.GetUnknown("discriminator"); err == nil && disc.Object != nil {
+		if name, ok := (*disc.Object)["propertyName"]; ok && name.String != nil {
+			return *name.String
+		}
+	}
+
+	first, err := resolve((*s.OneOf)[0])
+	if err != nil || first == nil || first.Properties == nil {
+		return ""
+	}
+
+candidates:
+	for _, prop := range *first.Properties {
+		for _, branch := range *s.OneOf {
+			branch, err := resolve(branch)
+			if err != nil || constValue(branch, prop.Name) == "" {
+				continue candidates
+			}
+		}
+		return prop.Name
+	}
+
+	return ""
+}
+
+// constValue returns the string value a property must have, if it's limited to a single value
+func constValue(s *jsonschema.Schema, property string) string {
+	if s == nil || s.Properties == nil {
+		return ""
+	}
+
+	prop, ok := s.Properties.GetProperty(property)
+	if !ok {
+		return ""
+	}
+
+	propSchema, err := resolve(prop.Property)
+	if err != nil || propSchema == nil {
+		return ""
+	}
+
+	if propSchema.Const != nil && propSchema.Const.String != nil {
+		return *propSchema.Const.String
+	}
+	if propSchema.Enum != nil && len(*propSchema.Enum) == 1 && (*propSchema.Enum)[0].String != nil {
+		return *(*propSchema.Enum)[0].String
+	}
+
+	return ""
+}
+
+// exportedName converts a name like "user_id" or "first-name" to "UserID" and "FirstName"
+func exportedName(name string) string {
+	words := strings.FieldsFunc(name, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	var b strings.Builder
+	for _, word := range words {
+		upper := strings.ToUpper(word)
+		if _, ok := commonInitialisms[upper]; ok {
+			b.WriteString(upper)
+			continue
+		}
+		runes := []rune(word)
+		runes[0] = unicode.ToUpper(runes[0])
+		b.WriteString(string(runes))
+	}
+
+	exported := b.String()
+	if exported != "" && unicode.IsDigit([]rune(exported)[0]) {
+		exported = "N" + exported
+	}
+
+	return exported
+}
@@ -0,0 +1,529 @@
+package codegen
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/flowstack/go-jsonschema"
+)
+
+// TypeScriptOptions controls the output of TypeScript
+type TypeScriptOptions struct {
+	// RootType is the name of the type generated for the root schema.
+	// Defaults to the title of the schema, or "Root" if there is no title.
+	RootType string
+}
+
+// anyObject is the type of objects without constraints on their properties
+const anyObject = "{ [key: string]: unknown }"
+
+// reIdentifier matches property names, that don't need quotes in TypeScript
+var reIdentifier = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*$`)
+
+type tsGenerator struct {
+	names *typeNames
+
+	// queue holds named schemas, that still needs to be declared
+	queue []*jsonschema.Schema
+	done  map[*jsonschema.Schema]struct{}
+	decls []string
+}
+
+// TypeScript generates exported TypeScript declarations for the schema and its definitions.
+// Objects with properties become interfaces, with optional (?) members for properties, that
+// aren't required, and additionalProperties become index signatures. enum and const become
+// literal types, oneOf and anyOf become unions, allOf becomes an intersection, items as an array
+// becomes a tuple and definitions become named types. Descriptions are kept as JSDoc comments.
+// The output only depends on the schema, so it can be committed.
+// The schema should have its $refs resolved (see Schema.DeRef), if they point to remote schemas.
+func TypeScript(schema *jsonschema.Schema, opts TypeScriptOptions) ([]byte, error) {
+	if schema == nil {
+		return nil, fmt.Errorf("no schema supplied")
+	}
+
+	g := &tsGenerator{
+		names: newTypeNames(),
+		done:  map[*jsonschema.Schema]struct{}{},
+	}
+
+	rootName := opts.RootType
+	if rootName == "" && schema.Title != nil {
+		rootName = exportedName(*schema.Title)
+	}
+	if rootName == "" {
+		rootName = "Root"
+	}
+	g.names.register(schema, rootName)
+	g.queue = append(g.queue, schema)
+
+	for _, def := range definitions(schema) {
+		g.names.register(def.Property, exportedName(def.Name))
+		g.queue = append(g.queue, def.Property)
+	}
+
+	for len(g.queue) > 0 {
+		s := g.queue[0]
+		g.queue = g.queue[1:]
+		if _, ok := g.done[s]; ok {
+			continue
+		}
+		g.done[s] = struct{}{}
+
+		if err := g.declare(s); err != nil {
+			return nil, err
+		}
+	}
+
+	buf := &bytes.Buffer{}
+	buf.WriteString("// Code generated by jsonschema gen; DO NOT EDIT.\n\n")
+	buf.WriteString(strings.Join(g.decls, "\n"))
+	return buf.Bytes(), nil
+}
+
+// declare writes the named type for s
+func (g *tsGenerator) declare(s *jsonschema.Schema) error {
+	name := g.names.get(s)
+	buf := &bytes.Buffer{}
+	writeJSDoc(buf, "", s.Description)
+
+	if isInterface(s) {
+		body, err := g.objectBody(s, name)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(buf, "export interface %s %s\n", name, body)
+		g.decls = append(g.decls, buf.String())
+		return nil
+	}
+
+	typ, err := g.tsType(s, name, true)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(buf, "export type %s = %s;\n", name, typ)
+	g.decls = append(g.decls, buf.String())
+	return nil
+}
+
+// isInterface reports whether s can be declared as an interface, rather than a type alias
+func isInterface(s *jsonschema.Schema) bool {
+	typ := typeName(s)
+	return s.Ref == nil && s.Properties != nil && (typ == "object" || typ == "") && !isNullableType(s) &&
+		s.AllOf == nil && s.AnyOf == nil && s.OneOf == nil && s.Const == nil && s.Enum == nil
+}
+
+// tsType returns the TypeScript type for s.
+// Objects with properties gets a named type, based on nameHint.
+// If declaring is true, s is being declared as nameHint, so the type is written out.
+func (g *tsGenerator) tsType(s *jsonschema.Schema, nameHint string, declaring bool) (string, error) {
+	if s == nil {
+		return "unknown", nil
+	}
+	if isFalse(s) {
+		return "never", nil
+	}
+
+	if s.Ref != nil {
+		target, err := resolve(s)
+		if err != nil {
+			return "", err
+		}
+		if name := g.names.get(target); name != "" {
+			return name, nil
+		}
+		if name := refName(s); name != "" {
+			nameHint = name
+		}
+		s = target
+	}
+
+	if !declaring {
+		if name := g.names.get(s); name != "" {
+			return name, nil
+		}
+		if s.Properties != nil {
+			g.names.register(s, nameHint)
+			g.queue = append(g.queue, s)
+			return g.names.get(s), nil
+		}
+	}
+
+	base, err := g.baseType(s, nameHint)
+	if err != nil {
+		return "", err
+	}
+
+	// An unconstrained base type adds nothing to the types of allOf, anyOf and oneOf
+	parts := []string{}
+	if (base != "unknown" && base != anyObject) || (s.AllOf == nil && s.AnyOf == nil && s.OneOf == nil) {
+		parts = append(parts, base)
+	}
+
+	for _, combinator := range []struct {
+		name    string
+		schemas *jsonschema.Schemas
+	}{
+		{"OneOf", s.OneOf},
+		{"AnyOf", s.AnyOf},
+	} {
+		if combinator.schemas == nil {
+			continue
+		}
+		types := []string{}
+		for i, sub := range *combinator.schemas {
+			typ, err := g.tsType(sub, fmt.Sprintf("%s%s%d", nameHint, combinator.name, i+1), false)
+			if err != nil {
+				return "", err
+			}
+			types = append(types, typ)
+		}
+		parts = append(parts, union(types...))
+	}
+
+	if s.AllOf != nil {
+		for i, sub := range *s.AllOf {
+			typ, err := g.tsType(sub, fmt.Sprintf("%sAllOf%d", nameHint, i+1), false)
+			if err != nil {
+				return "", err
+			}
+			parts = append(parts, typ)
+		}
+	}
+
+	if len(parts) == 1 {
+		return parts[0], nil
+	}
+	for i, part := range parts {
+		parts[i] = parenthesize(part)
+	}
+	return strings.Join(parts, " & "), nil
+}
+
+// baseType returns the type of s from const, enum or type, ignoring allOf, anyOf and oneOf
+func (g *tsGenerator) baseType(s *jsonschema.Schema, nameHint string) (string, error) {
+	if s.Const != nil {
+		if lit, ok := tsLiteral(s.Const); ok {
+			return lit, nil
+		}
+	}
+
+	if s.Enum != nil && len(*s.Enum) > 0 {
+		literals := []string{}
+		for _, val := range *s.Enum {
+			lit, ok := tsLiteral(val)
+			if !ok {
+				literals = nil
+				break
+			}
+			literals = append(literals, lit)
+		}
+		if literals != nil {
+			return union(literals...), nil
+		}
+	}
+
+	types := []string{}
+	if s.Type != nil && s.Type.String != nil {
+		types = append(types, *s.Type.String)
+	} else if s.Type != nil && s.Type.Strings != nil {
+		for _, typ := range *s.Type.Strings {
+			types = append(types, *typ)
+		}
+	} else if typ := typeName(s); typ != "" {
+		types = append(types, typ)
+	}
+
+	tsTypes := []string{}
+	for _, typ := range types {
+		switch typ {
+		case "string":
+			tsTypes = append(tsTypes, "string")
+		case "integer", "number":
+			tsTypes = append(tsTypes, "number")
+		case "boolean":
+			tsTypes = append(tsTypes, "boolean")
+		case "null":
+			tsTypes = append(tsTypes, "null")
+		case "array":
+			arr, err := g.arrayType(s, nameHint)
+			if err != nil {
+				return "", err
+			}
+			tsTypes = append(tsTypes, arr)
+		case "object":
+			if s.Properties != nil {
+				body, err := g.objectBody(s, nameHint)
+				if err != nil {
+					return "", err
+				}
+				tsTypes = append(tsTypes, body)
+				continue
+			}
+			valueType, err := g.indexType(s, nameHint, nil)
+			if err != nil {
+				return "", err
+			}
+			if valueType == "" {
+				valueType = "unknown"
+			}
+			tsTypes = append(tsTypes, fmt.Sprintf("{ [key: string]: %s }", valueType))
+		}
+	}
+
+	if len(tsTypes) == 0 {
+		return "unknown", nil
+	}
+	return union(tsTypes...), nil
+}
+
+// arrayType returns the type of an array, or a tuple, if items is an array
+func (g *tsGenerator) arrayType(s *jsonschema.Schema, nameHint string) (string, error) {
+	if s.Items == nil || (s.Items.Boolean != nil && *s.Items.Boolean) {
+		return "unknown[]", nil
+	}
+	if s.Items.Boolean != nil {
+		return "[]", nil
+	}
+
+	if s.Items.Schema != nil {
+		itemType, err := g.tsType(s.Items.Schema, nameHint+"Item", false)
+		if err != nil {
+			return "", err
+		}
+		return parenthesize(itemType) + "[]", nil
+	}
+
+	minItems := 0
+	if s.MinItems != nil {
+		minItems = int(*s.MinItems)
+	}
+
+	elems := []string{}
+	if s.Items.Schemas != nil {
+		for i, item := range *s.Items.Schemas {
+			typ, err := g.tsType(item, fmt.Sprintf("%sItem%d", nameHint, i+1), false)
+			if err != nil {
+				return "", err
+			}
+			if i >= minItems {
+				typ = parenthesize(typ) + "?"
+			}
+			elems = append(elems, typ)
+		}
+	}
+
+	// Items after the tuple are allowed, unless additionalItems is false
+	if s.AdditionalItems == nil {
+		elems = append(elems, "...unknown[]")
+	} else if !(isFalse(s.AdditionalItems)) {
+		typ, err := g.tsType(s.AdditionalItems, nameHint+"Item", false)
+		if err != nil {
+			return "", err
+		}
+		elems = append(elems, "..."+parenthesize(typ)+"[]")
+	}
+
+	return "[" + strings.Join(elems, ", ") + "]", nil
+}
+
+// objectBody returns the members of an object type, e.g. for an interface
+func (g *tsGenerator) objectBody(s *jsonschema.Schema, name string) (string, error) {
+	required := map[string]bool{}
+	if s.Required != nil {
+		for _, req := range *s.Required {
+			required[*req] = true
+		}
+	}
+
+	buf := &bytes.Buffer{}
+	buf.WriteString("{\n")
+
+	propTypes := []string{}
+	seen := map[string]bool{}
+	if s.Properties != nil {
+		for _, prop := range *s.Properties {
+			if seen[prop.Name] {
+				continue
+			}
+			seen[prop.Name] = true
+
+			typ, err := g.tsType(prop.Property, name+exportedName(prop.Name), false)
+			if err != nil {
+				return "", err
+			}
+
+			// Named types have the description of their schema themselves
+			writeJSDoc(buf, "  ", prop.Property.Description)
+
+			optional := ""
+			if !required[prop.Name] {
+				optional = "?"
+				propTypes = append(propTypes, "undefined")
+			}
+			fmt.Fprintf(buf, "  %s%s: %s;\n", tsPropertyName(prop.Name), optional, typ)
+			propTypes = append(propTypes, typ)
+		}
+	}
+
+	indexType, err := g.indexType(s, name, propTypes)
+	if err != nil {
+		return "", err
+	}
+	if indexType != "" {
+		fmt.Fprintf(buf, "  [key: string]: %s;\n", indexType)
+	}
+
+	buf.WriteString("}")
+	return buf.String(), nil
+}
+
+// indexType returns the type of the index signature of an object, or "" if it has none.
+// The types of the properties must be part of it, as TypeScript checks them against it.
+func (g *tsGenerator) indexType(s *jsonschema.Schema, name string, propTypes []string) (string, error) {
+	types := []string{}
+
+	if s.PatternProperties != nil {
+		for i, prop := range *s.PatternProperties {
+			typ, err := g.tsType(prop.Property, fmt.Sprintf("%sPattern%d", name, i+1), false)
+			if err != nil {
+				return "", err
+			}
+			types = append(types, typ)
+		}
+	}
+
+	if s.AdditionalProperties != nil {
+		if isFalse(s.AdditionalProperties) {
+			if len(types) == 0 && s.Properties == nil {
+				return "never", nil
+			}
+		} else {
+			typ, err := g.tsType(s.AdditionalProperties, name+"Value", false)
+			if err != nil {
+				return "", err
+			}
+			types = append(types, typ)
+		}
+	}
+
+	if len(types) == 0 {
+		return "", nil
+	}
+	return union(append(types, propTypes...)...), nil
+}
+
+// isFalse reports whether s is the false schema
+func isFalse(s *jsonschema.Schema) bool {
+	return s != nil && s.String() == "false"
+}
+
+// union joins types with |, without duplicates. Anything in a union with unknown is unknown.
+func union(types ...string) string {
+	seen := map[string]bool{}
+	unique := []string{}
+	for _, typ := range types {
+		for _, member := range splitTopLevel(typ, '|') {
+			if member == "unknown" {
+				return "unknown"
+			}
+			if !seen[member] {
+				seen[member] = true
+				unique = append(unique, member)
+			}
+		}
+	}
+	if len(unique) == 1 {
+		return unique[0]
+	}
+	for i, typ := range unique {
+		if hasTopLevel(typ, '&') {
+			unique[i] = "(" + typ + ")"
+		}
+	}
+	return strings.Join(unique, " | ")
+}
+
+// parenthesize wraps unions and intersections in parentheses, for use in arrays and intersections
+func parenthesize(typ string) string {
+	if hasTopLevel(typ, '|') || hasTopLevel(typ, '&') {
+		return "(" + typ + ")"
+	}
+	return typ
+}
+
+// hasTopLevel reports whether op is used in typ, outside of brackets and string literals
+func hasTopLevel(typ string, op byte) bool {
+	return len(splitTopLevel(typ, op)) > 1
+}
+
+// splitTopLevel splits typ at op, where it's used outside of brackets and string literals,
+// e.g. the members of a union
+func splitTopLevel(typ string, op byte) []string {
+	parts := []string{}
+	depth, start := 0, 0
+	inString := false
+	for i := 0; i < len(typ); i++ {
+		c := typ[i]
+		switch {
+		case inString && c == '\\':
+			i++
+		case c == '"':
+			inString = !inString
+		case inString:
+		case c == '(' || c == '[' || c == '{' || c == '<':
+			depth++
+		case c == ')' || c == ']' || c == '}' || c == '>':
+			depth--
+		case c == op && depth == 0:
+			parts = append(parts, strings.TrimSpace(typ[start:i]))
+			start = i + 1
+		}
+	}
+	return append(parts, strings.TrimSpace(typ[start:]))
+}
+
+// tsLiteral returns a scalar value as a TypeScript literal type
+func tsLiteral(v *jsonschema.Value) (string, bool) {
+	switch {
+	case v == nil:
+		return "", false
+	case v.String != nil:
+		b, err := json.Marshal(*v.String)
+		return string(b), err == nil
+	case v.Number != nil:
+		return v.Number.Text('g', -1), true
+	case v.Boolean != nil:
+		return fmt.Sprint(*v.Boolean), true
+	case v.Null != nil:
+		return "null", true
+	}
+	return "", false
+}
+
+// tsPropertyName quotes property names, that aren't identifiers
+func tsPropertyName(name string) string {
+	if reIdentifier.MatchString(name) {
+		return name
+	}
+	b, _ := json.Marshal(name)
+	return string(b)
+}
+
+func writeJSDoc(buf *bytes.Buffer, indent string, doc *string) {
+	if doc == nil || strings.TrimSpace(*doc) == "" {
+		return
+	}
+	fmt.Fprintf(buf, "%s/**\n", indent)
+	for _, line := range strings.Split(strings.TrimSpace(*doc), "\n") {
+		line = strings.ReplaceAll(strings.TrimRight(line, " \t\r"), "*/", `*\/`)
+		if line == "" {
+			fmt.Fprintf(buf, "%s *\n", indent)
+			continue
+		}
+		fmt.Fprintf(buf, "%s * %s\n", indent, line)
+	}
+	fmt.Fprintf(buf, "%s */\n", indent)
+}
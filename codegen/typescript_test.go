@@ -0,0 +1,104 @@
+package codegen
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/flowstack/go-jsonschema"
+)
+
+var typeScriptTests = []struct {
+	schema   string
+	opts     TypeScriptOptions
+	expected []string
+}{
+	{
+		schema: `{"title":"user","description":"A user\n\nWith */ in it","type":"object","required":["id","role"],"properties":{
+			"id":{"type":"string","description":"The id"},
+			"home-url":{"type":["string","null"]},
+			"role":{"enum":["admin","user"]},
+			"level":{"const":3},
+			"address":{"type":"object","properties":{"city":{"type":"string"}}},
+			"tags":{"type":"array","items":{"anyOf":[{"type":"string"},{"type":"integer"}]}},
+			"point":{"type":"array","items":[{"type":"number"},{"type":"number"}],"minItems":1,"additionalItems":false},
+			"labels":{"type":"object","additionalProperties":{"type":"string"}}
+		},"additionalProperties":{"type":"boolean"}}`,
+		expected: []string{
+			"// Code generated by jsonschema gen; DO NOT EDIT.\n",
+			"/**\n * A user\n *\n * With *\\/ in it\n */\nexport interface User {\n",
+			"  /**\n   * The id\n   */\n  id: string;\n",
+			`  "home-url"?: string | null;` + "\n",
+			`  role: "admin" | "user";` + "\n",
+			"  level?: 3;\n",
+			"  address?: UserAddress;\n",
+			"  tags?: (string | number)[];\n",
+			"  point?: [number, number?];\n",
+			"  labels?: { [key: string]: string };\n",
+			`  [key: string]: boolean | string | undefined | null | "admin" | "user" | 3 | UserAddress | (string | number)[] | [number, number?] | { [key: string]: string };` + "\n",
+			"export interface UserAddress {\n  city?: string;\n}\n",
+		},
+	},
+	{
+		schema: `{"type":"object","required":["shape"],"properties":{"shape":{"$ref":"#/definitions/shape"},"owner":{"$ref":"#/$defs/named"}},
+			"definitions":{
+				"shape":{"description":"A shape","oneOf":[{"$ref":"#/definitions/circle"},{"$ref":"#/definitions/square"}]},
+				"circle":{"properties":{"kind":{"const":"circle"},"radius":{"type":"number"}}},
+				"square":{"properties":{"kind":{"const":"square"},"side":{"type":"number"}}}
+			},
+			"$defs":{"named":{"allOf":[{"$ref":"#/definitions/circle"},{"properties":{"name":{"type":"string"}},"required":["name"]}]}}}`,
+		opts: TypeScriptOptions{RootType: "Drawing"},
+		expected: []string{
+			"export interface Drawing {\n  shape: Shape;\n  owner?: Named;\n}\n",
+			"/**\n * A shape\n */\nexport type Shape = Circle | Square;\n",
+			"export interface Circle {\n  kind?: \"circle\";\n  radius?: number;\n}\n",
+			"export type Named = Circle & NamedAllOf2;\n",
+			"export interface NamedAllOf2 {\n  name: string;\n}\n",
+		},
+	},
+	{
+		schema: `{"type":"array","items":[{"type":"string"}],"additionalItems":{"oneOf":[{"type":"boolean"},{"type":"null"}]}}`,
+		expected: []string{
+			"export type Root = [string?, ...(boolean | null)[]];\n",
+		},
+	},
+	{
+		schema: `{"type":"object","additionalProperties":false,"definitions":{"any":{"type":"object"},"tuple":{"items":[{"const":"a"}]}}}`,
+		expected: []string{
+			"export type Root = { [key: string]: never };\n",
+			"export type Any = { [key: string]: unknown };\n",
+			"export type Tuple = [\"a\"?, ...unknown[]];\n",
+		},
+	},
+}
+
+func TestTypeScript(t *testing.T) {
+	for _, tt := range typeScriptTests {
+		s, err := jsonschema.New([]byte(tt.schema))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		src, err := TypeScript(s, tt.opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, exp := range tt.expected {
+			if !strings.Contains(string(src), exp) {
+				t.Fatalf("expected generated code to contain:\n%s\ngot:\n%s", exp, src)
+			}
+		}
+
+		// The output must be the same every time
+		for i := 0; i < 5; i++ {
+			again, err := TypeScript(s, tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(src, again) {
+				t.Fatalf("expected the same output every time, got:\n%s\nand:\n%s", src, again)
+			}
+		}
+	}
+}
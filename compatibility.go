@@ -0,0 +1,554 @@
+package jsonschema
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// CompatibilityMode decides which direction changes are checked in
+type CompatibilityMode uint8
+
+const (
+	// Backward compatibility means new consumers accept old data,
+	// i.e. everything valid against the old schema is valid against the new schema.
+	Backward CompatibilityMode = iota + 1
+	// Forward compatibility means old consumers accept new data,
+	// i.e. everything valid against the new schema is valid against the old schema.
+	Forward
+	// Full compatibility is both backward and forward compatibility.
+	Full
+)
+
+func (m CompatibilityMode) String() string {
+	switch m {
+	case Backward:
+		return "backward"
+	case Forward:
+		return "forward"
+	case Full:
+		return "full"
+	default:
+		return "unknown"
+	}
+}
+
+// Incompatibility is a breaking change found by CheckCompatibility
+type Incompatibility struct {
+	// Pointer is the JSON Pointer to the schema, that changed
+	Pointer string
+	Keyword string
+	Message string
+
+	// Mode is the compatibility, that the change breaks - either Backward or Forward
+	Mode CompatibilityMode
+}
+
+func (i Incompatibility) String() string {
+	return fmt.Sprintf("#%s/%s: %s (breaks %s compatibility)", i.Pointer, i.Keyword, i.Message, i.Mode)
+}
+
+type compatibilityChecker struct {
+	mode    CompatibilityMode
+	checked map[[2]*Schema]struct{}
+	issues  []Incompatibility
+
+	// anything is used in place of missing writer schemas, so recursive schemas still terminate
+	anything *Schema
+}
+
+// CheckCompatibility compares the structure of two versions of a schema, and returns the
+// changes that break compatibility in the given mode.
+// In backward mode, anything new rejects, that old accepted, is a breaking change, e.g. newly
+// required properties, narrowed types and enums, tightened bounds, removed properties where
+// additionalProperties is false and $refs pointing to a different schema.
+// Forward mode is the same check in the opposite direction.
+// The check is conservative: changes it can't reason about, like a changed pattern, are reported.
+func CheckCompatibility(old, new *Schema, mode CompatibilityMode) ([]Incompatibility, error) {
+	if old == nil || new == nil {
+		return nil, errors.New("invalid schema")
+	}
+	if mode < Backward || mode > Full {
+		return nil, fmt.Errorf("invalid compatibility mode: %d", mode)
+	}
+
+	issues := []Incompatibility{}
+	if mode == Backward || mode == Full {
+		c := &compatibilityChecker{mode: Backward, checked: map[[2]*Schema]struct{}{}, anything: &Schema{}}
+		if err := c.check(old, new, ""); err != nil {
+			return nil, err
+		}
+		issues = append(issues, c.issues...)
+	}
+	if mode == Forward || mode == Full {
+		c := &compatibilityChecker{mode: Forward, checked: map[[2]*Schema]struct{}{}, anything: &Schema{}}
+		if err := c.check(new, old, ""); err != nil {
+			return nil, err
+		}
+		issues = append(issues, c.issues...)
+	}
+
+	return issues, nil
+}
+
+func (c *compatibilityChecker) report(ptr, keyword, format string, args ...interface{}) {
+	c.issues = append(c.issues, Incompatibility{
+		Pointer: ptr,
+		Keyword: keyword,
+		Message: fmt.Sprintf(format, args...),
+		Mode:    c.mode,
+	})
+}
+
+// check reports everything reader rejects, that writer accepts.
+// A nil schema accepts everything.
+func (c *compatibilityChecker) check(writer, reader *Schema, ptr string) error {
+	if reader == nil {
+		return nil
+	}
+	if writer == nil {
+		writer = c.anything
+	}
+
+	if writer.Ref != nil && reader.Ref != nil && writer.Ref.String != nil && reader.Ref.String != nil &&
+		*writer.Ref.String != *reader.Ref.String {
+		writerTarget, err := followRefs(writer)
+		if err != nil {
+			return err
+		}
+		readerTarget, err := followRefs(reader)
+		if err != nil {
+			return err
+		}
+		if writerTarget.String() != readerTarget.String() {
+			c.report(ptr, "$ref", "changed from %s to %s", *writer.Ref.String, *reader.Ref.String)
+		}
+	}
+
+	writer, err := followRefs(writer)
+	if err != nil {
+		return err
+	}
+	reader, err = followRefs(reader)
+	if err != nil {
+		return err
+	}
+
+	// Recursive schemas are only checked once
+	key := [2]*Schema{writer, reader}
+	if _, ok := c.checked[key]; ok {
+		return nil
+	}
+	c.checked[key] = struct{}{}
+
+	if writer.boolean != nil && !*writer.boolean {
+		// Nothing is accepted, so nothing can be rejected
+		return nil
+	}
+	if reader.boolean != nil {
+		if !*reader.boolean {
+			c.report(ptr, "false", "no longer accepts any value")
+		}
+		return nil
+	}
+	if writer.boolean != nil {
+		writer = c.anything
+	}
+
+	c.checkType(writer, reader, ptr)
+	c.checkValues(writer, reader, ptr)
+	c.checkNumbers(writer, reader, ptr)
+	c.checkLimits(writer, reader, ptr)
+	c.checkStrings(writer, reader, ptr)
+
+	if err := c.checkObjects(writer, reader, ptr); err != nil {
+		return err
+	}
+	if err := c.checkArrays(writer, reader, ptr); err != nil {
+		return err
+	}
+	return c.checkCombinations(writer, reader, ptr)
+}
+
+func (c *compatibilityChecker) checkType(writer, reader *Schema, ptr string) {
+	readerTypes := schemaTypes(reader)
+	if len(readerTypes) == 0 {
+		return
+	}
+
+	allowed := map[string]bool{}
+	for _, typ := range readerTypes {
+		allowed[typ] = true
+	}
+
+	writerTypes := schemaTypes(writer)
+	if len(writerTypes) == 0 {
+		c.report(ptr, "type", "added type %s", strings.Join(readerTypes, ", "))
+		return
+	}
+
+	removed := []string{}
+	for _, typ := range writerTypes {
+		if !allowed[typ] && !(typ == "integer" && allowed["number"]) {
+			removed = append(removed, typ)
+		}
+	}
+	if len(removed) > 0 {
+		c.report(ptr, "type", "no longer allows %s", strings.Join(removed, ", "))
+	}
+}
+
+func (c *compatibilityChecker) checkValues(writer, reader *Schema, ptr string) {
+	if reader.Const != nil {
+		if writer.Const == nil || !writer.Const.Equal(reader.Const) {
+			c.report(ptr, "const", "only allows %s", valueString(reader.Const))
+		}
+	}
+
+	if reader.Enum == nil {
+		return
+	}
+
+	if writer.Const != nil {
+		if !enumContains(reader.Enum, writer.Const) {
+			c.report(ptr, "enum", "no longer allows %s", valueString(writer.Const))
+		}
+		return
+	}
+	if writer.Enum == nil {
+		c.report(ptr, "enum", "added enum")
+		return
+	}
+
+	removed := []string{}
+	for _, val := range *writer.Enum {
+		if !enumContains(reader.Enum, val) {
+			removed = append(removed, valueString(val))
+		}
+	}
+	if len(removed) > 0 {
+		c.report(ptr, "enum", "no longer allows %s", strings.Join(removed, ", "))
+	}
+}
+
+func (c *compatibilityChecker) checkNumbers(writer, reader *Schema, ptr string) {
+	writerLo, writerHi, writerLoExcl, writerHiExcl := numberBounds(writer)
+	readerLo, readerHi, readerLoExcl, readerHiExcl := numberBounds(reader)
+
+	if readerLo != nil {
+		keyword := "minimum"
+		if readerLoExcl {
+			keyword = "exclusiveMinimum"
+		}
+		if writerLo == nil {
+			c.report(ptr, keyword, "added lower bound %s", readerLo.RatString())
+		} else if cmp := readerLo.Cmp(writerLo); cmp > 0 {
+			c.report(ptr, keyword, "raised lower bound from %s to %s", writerLo.RatString(), readerLo.RatString())
+		} else if cmp == 0 && readerLoExcl && !writerLoExcl {
+			c.report(ptr, keyword, "lower bound %s is now exclusive", readerLo.RatString())
+		}
+	}
+
+	if readerHi != nil {
+		keyword := "maximum"
+		if readerHiExcl {
+			keyword = "exclusiveMaximum"
+		}
+		if writerHi == nil {
+			c.report(ptr, keyword, "added upper bound %s", readerHi.RatString())
+		} else if cmp := readerHi.Cmp(writerHi); cmp < 0 {
+			c.report(ptr, keyword, "lowered upper bound from %s to %s", writerHi.RatString(), readerHi.RatString())
+		} else if cmp == 0 && readerHiExcl && !writerHiExcl {
+			c.report(ptr, keyword, "upper bound %s is now exclusive", readerHi.RatString())
+		}
+	}
+
+	if reader.MultipleOf != nil {
+		readerStep, ok := new(big.Rat).SetString(string(*reader.MultipleOf))
+		if !ok || readerStep.Sign() == 0 {
+			return
+		}
+		if writer.MultipleOf == nil {
+			c.report(ptr, "multipleOf", "added multipleOf %s", *reader.MultipleOf)
+			return
+		}
+		// Every multiple of the writers step must also be a multiple of the readers
+		writerStep, ok := new(big.Rat).SetString(string(*writer.MultipleOf))
+		if !ok || !new(big.Rat).Quo(writerStep, readerStep).IsInt() {
+			c.report(ptr, "multipleOf", "changed from %s to %s", *writer.MultipleOf, *reader.MultipleOf)
+		}
+	}
+}
+
+// checkLimits checks the length and size keywords, that all work the same way
+func (c *compatibilityChecker) checkLimits(writer, reader *Schema, ptr string) {
+	limits := []struct {
+		keyword        string
+		writer, reader *int64
+		isMin          bool
+	}{
+		{"minLength", writer.MinLength, reader.MinLength, true},
+		{"maxLength", writer.MaxLength, reader.MaxLength, false},
+		{"minItems", writer.MinItems, reader.MinItems, true},
+		{"maxItems", writer.MaxItems, reader.MaxItems, false},
+		{"minProperties", writer.MinProperties, reader.MinProperties, true},
+		{"maxProperties", writer.MaxProperties, reader.MaxProperties, false},
+	}
+
+	for _, limit := range limits {
+		if limit.reader == nil {
+			continue
+		}
+		switch {
+		case limit.writer == nil:
+			c.report(ptr, limit.keyword, "added %s %d", limit.keyword, *limit.reader)
+		case limit.isMin && *limit.reader > *limit.writer:
+			c.report(ptr, limit.keyword, "raised from %d to %d", *limit.writer, *limit.reader)
+		case !limit.isMin && *limit.reader < *limit.writer:
+			c.report(ptr, limit.keyword, "lowered from %d to %d", *limit.writer, *limit.reader)
+		}
+	}
+
+	if reader.UniqueItems != nil && *reader.UniqueItems && (writer.UniqueItems == nil || !*writer.UniqueItems) {
+		c.report(ptr, "uniqueItems", "items must now be unique")
+	}
+}
+
+func (c *compatibilityChecker) checkStrings(writer, reader *Schema, ptr string) {
+	if reader.Pattern != nil && (writer.Pattern == nil || *writer.Pattern != *reader.Pattern) {
+		c.report(ptr, "pattern", "changed to %s", *reader.Pattern)
+	}
+	if reader.Format != nil && (writer.Format == nil || *writer.Format != *reader.Format) {
+		c.report(ptr, "format", "changed to %s", *reader.Format)
+	}
+}
+
+func (c *compatibilityChecker) checkObjects(writer, reader *Schema, ptr string) error {
+	if reader.Required != nil {
+		writerRequired := map[string]bool{}
+		if writer.Required != nil {
+			for _, req := range *writer.Required {
+				writerRequired[*req] = true
+			}
+		}
+		for _, req := range *reader.Required {
+			if !writerRequired[*req] {
+				c.report(ptr, "required", "%s is now required", *req)
+			}
+		}
+	}
+
+	// Properties the writer knows, are checked against whatever the reader validates them with
+	if writer.Properties != nil {
+		for _, prop := range *writer.Properties {
+			propPtr := ptr + "/properties/" + escapePointerToken(prop.Name)
+			readerProp, how := readerPropertySchema(reader, prop.Name)
+			if how == "additionalProperties" && reader.AdditionalProperties != nil &&
+				reader.AdditionalProperties.boolean != nil && !*reader.AdditionalProperties.boolean {
+				c.report(propPtr, "additionalProperties", "%s was removed and additionalProperties is false", prop.Name)
+				continue
+			}
+			if err := c.check(prop.Property, readerProp, propPtr); err != nil {
+				return err
+			}
+		}
+	}
+
+	// New properties, the writer could already send as additional properties
+	writerAllowsAdditional := writer.AdditionalProperties == nil || writer.AdditionalProperties.boolean == nil ||
+		*writer.AdditionalProperties.boolean
+	if reader.Properties != nil && writerAllowsAdditional {
+		for _, prop := range *reader.Properties {
+			if writer.Properties != nil {
+				if _, ok := writer.Properties.GetProperty(prop.Name); ok {
+					continue
+				}
+			}
+			if err := c.check(writer.AdditionalProperties, prop.Property, ptr+"/properties/"+escapePointerToken(prop.Name)); err != nil {
+				return err
+			}
+		}
+	}
+
+	if reader.AdditionalProperties != nil && writerAllowsAdditional {
+		if reader.AdditionalProperties.boolean != nil && !*reader.AdditionalProperties.boolean {
+			c.report(ptr, "additionalProperties", "additional properties are no longer allowed")
+		} else if err := c.check(writer.AdditionalProperties, reader.AdditionalProperties, ptr+"/additionalProperties"); err != nil {
+			return err
+		}
+	}
+
+	if reader.PatternProperties != nil {
+		for _, prop := range *reader.PatternProperties {
+			var writerProp *Schema
+			if writer.PatternProperties != nil {
+				if wp, ok := writer.PatternProperties.GetProperty(prop.Name); ok {
+					writerProp = wp.Property
+				}
+			}
+			if writerProp == nil && !writerAllowsAdditional {
+				continue
+			}
+			if writerProp == nil {
+				writerProp = writer.AdditionalProperties
+			}
+			if err := c.check(writerProp, prop.Property, ptr+"/patternProperties/"+escapePointerToken(prop.Name)); err != nil {
+				return err
+			}
+		}
+	}
+
+	if reader.PropertyNames != nil {
+		if err := c.check(writer.PropertyNames, reader.PropertyNames, ptr+"/propertyNames"); err != nil {
+			return err
+		}
+	}
+
+	if reader.Dependencies != nil {
+		names := []string{}
+		for name := range *reader.Dependencies {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			readerDep := (*reader.Dependencies)[name]
+			var writerDep *Dependency
+			if writer.Dependencies != nil {
+				writerDep = (*writer.Dependencies)[name]
+			}
+			if writerDep == nil || dependencyString(writerDep) != dependencyString(readerDep) {
+				c.report(ptr+"/dependencies", escapePointerToken(name), "changed to %s", dependencyString(readerDep))
+			}
+		}
+	}
+
+	return nil
+}
+
+// readerPropertySchema returns the schema a property is validated against, and the keyword it comes from
+func readerPropertySchema(s *Schema, name string) (*Schema, string) {
+	if s.Properties != nil {
+		if prop, ok := s.Properties.GetProperty(name); ok {
+			return prop.Property, "properties"
+		}
+	}
+	if s.PatternProperties != nil {
+		for _, prop := range *s.PatternProperties {
+			if re, err := regexp.Compile(convertRegexp(prop.Name)); err == nil && re.MatchString(name) {
+				return prop.Property, "patternProperties"
+			}
+		}
+	}
+	return s.AdditionalProperties, "additionalProperties"
+}
+
+func (c *compatibilityChecker) checkArrays(writer, reader *Schema, ptr string) error {
+	if reader.Items != nil {
+		readerItems := reader.Items
+		writerItems := writer.Items
+		if writerItems == nil {
+			writerItems = &Items{}
+		}
+
+		switch {
+		case readerItems.Boolean != nil && !*readerItems.Boolean:
+			if writerItems.Boolean == nil || *writerItems.Boolean {
+				c.report(ptr, "items", "items are no longer allowed")
+			}
+
+		case readerItems.Schema != nil && writerItems.Schemas == nil:
+			if err := c.check(writerItems.Schema, readerItems.Schema, ptr+"/items"); err != nil {
+				return err
+			}
+
+		case readerItems.Schemas != nil && writerItems.Schemas != nil:
+			for i, readerItem := range *readerItems.Schemas {
+				var writerItem *Schema
+				if i < len(*writerItems.Schemas) {
+					writerItem = (*writerItems.Schemas)[i]
+				} else {
+					writerItem = writer.AdditionalItems
+				}
+				if err := c.check(writerItem, readerItem, fmt.Sprintf("%s/items/%d", ptr, i)); err != nil {
+					return err
+				}
+			}
+
+		case readerItems.Schemas != nil || writerItems.Schemas != nil:
+			c.report(ptr, "items", "changed between a single schema and a list of schemas")
+		}
+	}
+
+	if reader.AdditionalItems != nil && reader.Items != nil && reader.Items.Schemas != nil {
+		if err := c.check(writer.AdditionalItems, reader.AdditionalItems, ptr+"/additionalItems"); err != nil {
+			return err
+		}
+	}
+
+	if reader.Contains != nil && (writer.Contains == nil || writer.Contains.String() != reader.Contains.String()) {
+		c.report(ptr, "contains", "changed to %s", reader.Contains.String())
+	}
+
+	return nil
+}
+
+// checkCombinations compares allOf, anyOf, oneOf, not and if, which are only checked by position
+func (c *compatibilityChecker) checkCombinations(writer, reader *Schema, ptr string) error {
+	for _, kw := range []string{"allOf", "anyOf", "oneOf"} {
+		readerSchemas := *schemasFields(reader)[kw]
+		writerSchemas := *schemasFields(writer)[kw]
+		if readerSchemas == nil {
+			continue
+		}
+
+		if writerSchemas == nil || len(*writerSchemas) != len(*readerSchemas) {
+			c.report(ptr, kw, "changed the list of schemas")
+			continue
+		}
+
+		for i := range *readerSchemas {
+			if err := c.check((*writerSchemas)[i], (*readerSchemas)[i], fmt.Sprintf("%s/%s/%d", ptr, kw, i)); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, kw := range []string{"not", "if", "then", "else"} {
+		readerSchema := *schemaFields(reader)[kw]
+		writerSchema := *schemaFields(writer)[kw]
+		if readerSchema != nil && (writerSchema == nil || writerSchema.String() != readerSchema.String()) {
+			c.report(ptr, kw, "changed to %s", readerSchema.String())
+		}
+	}
+
+	return nil
+}
+
+func enumContains(enum *Enum, val *Value) bool {
+	for _, v := range *enum {
+		if v.Equal(val) {
+			return true
+		}
+	}
+	return false
+}
+
+func valueString(val *Value) string {
+	b, err := val.MarshalJSON()
+	if err != nil {
+		return "?"
+	}
+	return string(b)
+}
+
+func dependencyString(dep *Dependency) string {
+	b, err := dep.MarshalJSON()
+	if err != nil {
+		return "?"
+	}
+	return string(b)
+}
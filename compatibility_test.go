@@ -0,0 +1,127 @@
+package jsonschema
+
+import (
+	"reflect"
+	"testing"
+)
+
+var compatibilityTests = []struct {
+	old      string
+	new      string
+	mode     CompatibilityMode
+	expected []string
+}{
+	{
+		old:      `{"type":"object","properties":{"name":{"type":"string"}}}`,
+		new:      `{"type":"object","properties":{"name":{"type":"string"},"age":{"type":"integer"}}}`,
+		mode:     Full,
+		expected: []string{"#/properties/age/type: added type integer (breaks backward compatibility)"},
+	},
+	{
+		old:      `{"type":"object","properties":{"name":{"type":"string"}},"additionalProperties":false}`,
+		new:      `{"type":"object","properties":{"name":{"type":"string"},"age":{"type":"integer"}},"additionalProperties":false}`,
+		mode:     Backward,
+		expected: []string{},
+	},
+	{
+		old:      `{"type":"object","properties":{"name":{"type":"string"},"age":{"type":"integer"}},"additionalProperties":false}`,
+		new:      `{"type":"object","properties":{"name":{"type":"string"}},"additionalProperties":false}`,
+		mode:     Backward,
+		expected: []string{"#/properties/age/additionalProperties: age was removed and additionalProperties is false (breaks backward compatibility)"},
+	},
+	{
+		old:      `{"properties":{"name":{"type":"string"}}}`,
+		new:      `{"properties":{"name":{"type":"string"}},"required":["name"]}`,
+		mode:     Full,
+		expected: []string{"#/required: name is now required (breaks backward compatibility)"},
+	},
+	{
+		old:  `{"type":["string","number"],"enum":["a","b",1]}`,
+		new:  `{"type":"string","enum":["a","c"]}`,
+		mode: Full,
+		expected: []string{
+			"#/type: no longer allows number (breaks backward compatibility)",
+			`#/enum: no longer allows "b", 1 (breaks backward compatibility)`,
+			`#/enum: no longer allows "c" (breaks forward compatibility)`,
+		},
+	},
+	{
+		old:  `{"type":"integer","minimum":0,"maximum":100,"multipleOf":2}`,
+		new:  `{"type":"number","exclusiveMinimum":0,"maximum":50,"multipleOf":4}`,
+		mode: Backward,
+		expected: []string{
+			"#/exclusiveMinimum: lower bound 0 is now exclusive (breaks backward compatibility)",
+			"#/maximum: lowered upper bound from 100 to 50 (breaks backward compatibility)",
+			"#/multipleOf: changed from 2 to 4 (breaks backward compatibility)",
+		},
+	},
+	{
+		old:      `{"type":"array","items":{"type":"string","maxLength":10},"minItems":1}`,
+		new:      `{"type":"array","items":{"type":"string","maxLength":20},"minItems":2}`,
+		mode:     Forward,
+		expected: []string{"#/items/maxLength: lowered from 20 to 10 (breaks forward compatibility)"},
+	},
+	{
+		old:  `{"properties":{"a":{"$ref":"#/definitions/a"}},"definitions":{"a":{"type":"string"},"b":{"type":"integer"}}}`,
+		new:  `{"properties":{"a":{"$ref":"#/definitions/b"}},"definitions":{"a":{"type":"string"},"b":{"type":"integer"}}}`,
+		mode: Backward,
+		expected: []string{
+			"#/properties/a/$ref: changed from #/definitions/a to #/definitions/b (breaks backward compatibility)",
+			"#/properties/a/type: no longer allows string (breaks backward compatibility)",
+		},
+	},
+	{
+		// A renamed definition with the same content is not a breaking change
+		old:      `{"properties":{"a":{"$ref":"#/definitions/a"}},"definitions":{"a":{"type":"string"}}}`,
+		new:      `{"properties":{"a":{"$ref":"#/definitions/b"}},"definitions":{"b":{"type":"string"}}}`,
+		mode:     Full,
+		expected: []string{},
+	},
+	{
+		old:      `{"properties":{"child":{"$ref":"#"},"name":{"type":"string"}}}`,
+		new:      `{"properties":{"child":{"$ref":"#"},"name":{"type":"string","minLength":1}}}`,
+		mode:     Full,
+		expected: []string{"#/properties/name/minLength: added minLength 1 (breaks backward compatibility)"},
+	},
+}
+
+func TestCheckCompatibility(t *testing.T) {
+	for i, tt := range compatibilityTests {
+		oldSchema, err := New([]byte(tt.old))
+		if err != nil {
+			t.Fatal(err)
+		}
+		newSchema, err := New([]byte(tt.new))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		issues, err := CheckCompatibility(oldSchema, newSchema, tt.mode)
+		if err != nil {
+			t.Fatalf("test #%d: %s", i+1, err)
+		}
+
+		got := []string{}
+		for _, issue := range issues {
+			got = append(got, issue.String())
+		}
+		if !reflect.DeepEqual(got, tt.expected) {
+			t.Fatalf("test #%d: expected:\n%v\ngot:\n%v", i+1, tt.expected, got)
+		}
+	}
+}
+
+func TestCheckCompatibilityIdentical(t *testing.T) {
+	schema, err := New([]byte(`{"type":"object","properties":{"a":{"type":"string","pattern":"^a"}},"required":["a"],"additionalProperties":false}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	issues, err := CheckCompatibility(schema, schema, Full)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(issues) != 0 {
+		t.Fatalf("expected no issues, got: %v", issues)
+	}
+}
@@ -0,0 +1,260 @@
+package jsonschema
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/buger/jsonparser"
+)
+
+// maxInlineDepth guards against $refs, that keep resolving to new copies of the same schema
+const maxInlineDepth = 1000
+
+type inlineFrame struct {
+	schema *Schema
+	ptr    string
+}
+
+type inliner struct {
+	// stack holds the schemas currently being written and their location in the output
+	stack []inlineFrame
+
+	// resolved ensures that $refs to the same location always gives the same schema
+	resolved map[string]*Schema
+}
+
+// Inline returns the schema as JSON, with every $ref replaced by the schema it points to.
+// Recursive $refs can't be inlined, so only those are kept, but they are rewritten to point
+// at where the referenced schema ended up in the output, which means they always resolve.
+// As with validation, keywords next to a $ref are ignored.
+// $id and $schema are removed from everything but the root, as they would otherwise
+// change how the remaining $refs resolve.
+func (s *Schema) Inline() ([]byte, error) {
+	if s == nil {
+		return nil, errors.New("invalid schema")
+	}
+
+	in := &inliner{resolved: map[string]*Schema{}}
+	n, err := in.encode(s, "", true)
+	if err != nil {
+		return nil, err
+	}
+
+	return n.MarshalJSON()
+}
+
+// resolve returns the schema s.Ref points to
+func (in *inliner) resolve(s *Schema) (*Schema, error) {
+	if s.Ref.String == nil {
+		return s.ResolveRef(s.Ref)
+	}
+
+	refStr := *s.Ref.String
+	key := refStr
+	if strings.HasPrefix(refStr, "#") {
+		base := s
+		if s.baseURI == nil && s.base != nil {
+			base = s.base
+		}
+		key = fmt.Sprintf("%p%s", base, refStr)
+	} else if refURI, err := s.ExpandURI(refStr); err == nil {
+		key = refURI.String()
+	}
+
+	if target, ok := in.resolved[key]; ok {
+		return target, nil
+	}
+
+	target, err := s.ResolveRef(s.Ref)
+	if err != nil {
+		return nil, err
+	}
+	in.resolved[key] = target
+
+	return target, nil
+}
+
+func (in *inliner) find(s *Schema) *inlineFrame {
+	for i := range in.stack {
+		if in.stack[i].schema == s {
+			return &in.stack[i]
+		}
+	}
+	return nil
+}
+
+func (in *inliner) encode(s *Schema, ptr string, isRoot bool) (*node, error) {
+	if s == nil {
+		return newBoolNode(true), nil
+	}
+
+	for s.Ref != nil {
+		target, err := in.resolve(s)
+		if err != nil {
+			return nil, err
+		}
+
+		if frame := in.find(target); frame != nil {
+			ref := newObjectNode()
+			ref.set("$ref", newStringNode("#"+escapeFragment(frame.ptr)))
+			return ref, nil
+		}
+
+		s = target
+	}
+
+	if s.boolean != nil {
+		return newBoolNode(*s.boolean), nil
+	}
+
+	if len(in.stack) >= maxInlineDepth {
+		return nil, errors.New("maximum depth reached while inlining $refs")
+	}
+
+	in.stack = append(in.stack, inlineFrame{schema: s, ptr: ptr})
+	defer func() { in.stack = in.stack[:len(in.stack)-1] }()
+
+	// Marshal everything but the sub schemas, which are marshalled as placeholders,
+	// so they keep their position, when replaced below.
+	placeholder := &Schema{boolean: new(bool)}
+	c := *s
+	c.Ref = nil
+	if !isRoot {
+		c.Schema = nil
+		c.ID = nil
+		c.IDDraft04 = nil
+	}
+
+	for _, field := range schemaFields(&c) {
+		if *field != nil {
+			*field = placeholder
+		}
+	}
+	for _, field := range propertiesFields(&c) {
+		if *field != nil {
+			*field = &Properties{}
+		}
+	}
+	for _, field := range schemasFields(&c) {
+		if *field != nil {
+			*field = &Schemas{}
+		}
+	}
+	if c.Items != nil && c.Items.Boolean == nil {
+		c.Items = &Items{Boolean: new(bool)}
+	}
+	if c.Dependencies != nil {
+		c.Dependencies = &Dependencies{}
+	}
+
+	b, err := c.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+	n, err := parseNode(b)
+	if err != nil {
+		return nil, err
+	}
+
+	// Replace the placeholders
+	for kw, field := range schemaFields(s) {
+		if *field == nil {
+			continue
+		}
+		subNode, err := in.encode(*field, ptr+"/"+kw, false)
+		if err != nil {
+			return nil, err
+		}
+		n.set(kw, subNode)
+	}
+
+	for kw, field := range propertiesFields(s) {
+		if *field == nil {
+			continue
+		}
+		props := newObjectNode()
+		for _, prop := range **field {
+			subNode, err := in.encode(prop.Property, ptr+"/"+kw+"/"+escapePointerToken(prop.Name), false)
+			if err != nil {
+				return nil, err
+			}
+			props.members = append(props.members, &member{key: prop.Name, value: subNode})
+		}
+		n.set(kw, props)
+	}
+
+	for kw, field := range schemasFields(s) {
+		if *field == nil {
+			continue
+		}
+		subNode, err := in.encodeSchemas(**field, ptr+"/"+kw)
+		if err != nil {
+			return nil, err
+		}
+		n.set(kw, subNode)
+	}
+
+	if s.Items != nil && s.Items.Schema != nil {
+		subNode, err := in.encode(s.Items.Schema, ptr+"/items", false)
+		if err != nil {
+			return nil, err
+		}
+		n.set("items", subNode)
+	} else if s.Items != nil && s.Items.Schemas != nil {
+		subNode, err := in.encodeSchemas(*s.Items.Schemas, ptr+"/items")
+		if err != nil {
+			return nil, err
+		}
+		n.set("items", subNode)
+	}
+
+	if s.Dependencies != nil {
+		keys := []string{}
+		for key := range *s.Dependencies {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		deps := newObjectNode()
+		for _, key := range keys {
+			dep := (*s.Dependencies)[key]
+			var depNode *node
+			if dep.Schema != nil {
+				depNode, err = in.encode(dep.Schema, ptr+"/dependencies/"+escapePointerToken(key), false)
+			} else {
+				depNode, err = newNodeFrom(dep)
+			}
+			if err != nil {
+				return nil, err
+			}
+			deps.members = append(deps.members, &member{key: key, value: depNode})
+		}
+		n.set("dependencies", deps)
+	}
+
+	return n, nil
+}
+
+func (in *inliner) encodeSchemas(schemas Schemas, ptr string) (*node, error) {
+	arr := &node{typ: jsonparser.Array, items: []*node{}}
+	for i, sub := range schemas {
+		subNode, err := in.encode(sub, fmt.Sprintf("%s/%d", ptr, i), false)
+		if err != nil {
+			return nil, err
+		}
+		arr.items = append(arr.items, subNode)
+	}
+	return arr, nil
+}
+
+// escapeFragment encodes a JSON Pointer for use as an URI fragment
+func escapeFragment(ptr string) string {
+	tokens := strings.Split(ptr, "/")
+	for i, token := range tokens {
+		tokens[i] = strings.ReplaceAll(url.PathEscape(token), "+", "%2B")
+	}
+	return strings.Join(tokens, "/")
+}
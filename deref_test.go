@@ -40,3 +40,57 @@ func TestDeRef(t *testing.T) {
 		}
 	}
 }
+
+var inlineTests = []struct {
+	schema         string
+	expectedSchema string
+	docs           []string
+}{
+	{
+		schema:         `{"$schema":"http://json-schema.org/draft-04/schema#","properties":{"foo":{"$ref":"#"}},"additionalProperties":false}`,
+		expectedSchema: `{"$schema":"http://json-schema.org/draft-04/schema#","properties":{"foo":{"$ref":"#"}},"additionalProperties":false}`,
+		docs:           []string{`{"foo":{"foo":{}}}`, `{"foo":{"bar":1}}`, `{"bar":1}`},
+	},
+	{
+		schema:         `{"definitions":{"node":{"properties":{"children":{"items":{"$ref":"#/definitions/node"}},"name":{"$ref":"#/definitions/name"}}},"name":{"type":"string"}},"properties":{"tree":{"$ref":"#/definitions/node"}}}`,
+		expectedSchema: `{"definitions":{"node":{"properties":{"children":{"items":{"$ref":"#/definitions/node"}},"name":{"type":"string"}}},"name":{"type":"string"}},"properties":{"tree":{"properties":{"children":{"items":{"$ref":"#/properties/tree"}},"name":{"type":"string"}}}}}`,
+		docs:           []string{`{"tree":{"name":"a","children":[{"name":"b","children":[{"name":"c"}]}]}}`, `{"tree":{"children":[{"children":[{"name":1}]}]}}`},
+	},
+	{
+		schema:         `{"$id":"http://example.com/root.json","properties":{"a b":{"$id":"http://example.com/other.json","items":{"$ref":"#"}},"c":{"$ref":"other.json"}}}`,
+		expectedSchema: `{"$id":"http://example.com/root.json","properties":{"a b":{"items":{"$ref":"#/properties/a%20b"}},"c":{"items":{"$ref":"#/properties/c"}}}}`,
+		docs:           []string{`{"a b":[[[]]],"c":[[]]}`, `{"a b":[1]}`, `{"c":[[1]]}`},
+	},
+}
+
+func TestInline(t *testing.T) {
+	for i, tt := range inlineTests {
+		s, err := New([]byte(tt.schema))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		inlined, err := s.Inline()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if string(inlined) != tt.expectedSchema {
+			t.Fatalf("test #%d: expected inlined schema to match:\n%s\ngot:\n%s\n", i+1, tt.expectedSchema, string(inlined))
+		}
+
+		// Validation must give the same result as with the original schema
+		inlinedSchema, err := New(inlined)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, doc := range tt.docs {
+			expected, _ := s.Validate([]byte(doc))
+			actual, err := inlinedSchema.Validate([]byte(doc))
+			if actual != expected {
+				t.Fatalf("test #%d: expected %s to be valid: %t, got: %t (%v)", i+1, doc, expected, actual, err)
+			}
+		}
+	}
+}
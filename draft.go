@@ -0,0 +1,113 @@
+package jsonschema
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Draft identifies a version of the JSON Schema specification
+type Draft uint8
+
+const (
+	DraftUnknown Draft = iota
+	Draft04
+	Draft06
+	Draft07
+	Draft2019_09
+	Draft2020_12
+)
+
+// LatestDraft is the newest draft known by this package
+const LatestDraft = Draft2020_12
+
+func (d Draft) String() string {
+	switch d {
+	case Draft04:
+		return "draft-04"
+	case Draft06:
+		return "draft-06"
+	case Draft07:
+		return "draft-07"
+	case Draft2019_09:
+		return "2019-09"
+	case Draft2020_12:
+		return "2020-12"
+	default:
+		return "unknown"
+	}
+}
+
+// URI returns the meta-schema URI, as used in $schema
+func (d Draft) URI() string {
+	switch d {
+	case Draft04:
+		return "http://json-schema.org/draft-04/schema#"
+	case Draft06:
+		return "http://json-schema.org/draft-06/schema#"
+	case Draft07:
+		return "http://json-schema.org/draft-07/schema#"
+	case Draft2019_09:
+		return "https://json-schema.org/draft/2019-09/schema"
+	case Draft2020_12:
+		return "https://json-schema.org/draft/2020-12/schema"
+	default:
+		return ""
+	}
+}
+
+// DraftFromURI returns the Draft matching a $schema value, or DraftUnknown
+func DraftFromURI(uri string) Draft {
+	uri = strings.TrimSuffix(uri, "#")
+	uri = strings.TrimPrefix(strings.TrimPrefix(uri, "http://"), "https://")
+
+	switch uri {
+	case "json-schema.org/draft-04/schema", "json-schema.org/draft-05/schema", "json-schema.org/schema":
+		return Draft04
+	case "json-schema.org/draft-06/schema":
+		return Draft06
+	case "json-schema.org/draft-07/schema":
+		return Draft07
+	case "json-schema.org/draft/2019-09/schema":
+		return Draft2019_09
+	case "json-schema.org/draft/2020-12/schema":
+		return Draft2020_12
+	default:
+		return DraftUnknown
+	}
+}
+
+// ParseDraft accepts the most common ways of naming a draft,
+// e.g. 4, draft4, draft-04, 2020-12, draft2020-12 or a meta-schema URI
+func ParseDraft(name string) (Draft, error) {
+	if d := DraftFromURI(name); d != DraftUnknown {
+		return d, nil
+	}
+
+	name = strings.TrimPrefix(strings.ToLower(name), "draft")
+	name = strings.TrimPrefix(name, "-")
+	switch name {
+	case "4", "04":
+		return Draft04, nil
+	case "6", "06":
+		return Draft06, nil
+	case "7", "07":
+		return Draft07, nil
+	case "2019-09", "201909":
+		return Draft2019_09, nil
+	case "2020-12", "202012":
+		return Draft2020_12, nil
+	}
+
+	return DraftUnknown, fmt.Errorf("unknown draft: %s", name)
+}
+
+// Draft returns the draft set in $schema, or DraftUnknown if it isn't set or recognized
+func (s Schema) Draft() Draft {
+	if s.Schema == nil {
+		if s.root != nil && s.root.Schema != nil {
+			return DraftFromURI(*s.root.Schema)
+		}
+		return DraftUnknown
+	}
+	return DraftFromURI(*s.Schema)
+}
@@ -0,0 +1,217 @@
+package jsonschema
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/buger/jsonparser"
+)
+
+// ValidationError describes why a value in a document is invalid
+type ValidationError struct {
+	// InstancePointer is the JSON Pointer to the invalid value in the document, e.g. /items/0
+	InstancePointer string
+
+	// Code identifies the kind of error, e.g. minimum or required, and Params holds the values
+	// used in its message. Errors, that didn't come from a keyword, have no code.
+	Code   string
+	Params Params
+
+	Message string
+
+	// Offset is the byte offset of the invalid value in the validated JSON document.
+	// It is -1 when the document wasn't JSON, e.g. for ValidateYAML.
+	Offset int
+
+	// Line and Column are the 1-based position of the invalid value in the source document,
+	// where Column counts characters. They are 0 when the position isn't known.
+	Line   int
+	Column int
+}
+
+// positionFormat prefixes messages about a position in a source document, e.g. a YAML document
+const positionFormat = "line %d, column %d: "
+
+// Error returns the message. Errors in a document, that wasn't JSON, are prefixed by the line and
+// column, as the offset in the converted document is of no use to the caller.
+func (e *ValidationError) Error() string {
+	if e.Offset < 0 && e.Line > 0 {
+		return fmt.Sprintf(positionFormat+"%s", e.Line, e.Column, e.Message)
+	}
+	return e.Message
+}
+
+// Snippet renders the line of doc with the invalid value, with a caret under the value, e.g:
+//
+//	3 |   "age": -1,
+//	  |          ^ value is less than the minimum of 0
+//
+// doc must be the document, that was validated. Snippet returns the message, if the position isn't known.
+func (e *ValidationError) Snippet(doc []byte) string {
+	lines := bytes.Split(doc, []byte("\n"))
+	if e.Line < 1 || e.Line > len(lines) {
+		return e.Message
+	}
+
+	line := bytes.TrimRight(lines[e.Line-1], "\r")
+	gutter := strconv.Itoa(e.Line)
+
+	// Tabs are kept in the padding, so the caret lines up however tabs are displayed
+	padding := []byte{}
+	for i, r := range []rune(string(line)) {
+		if i >= e.Column-1 {
+			break
+		}
+		if r == '\t' {
+			padding = append(padding, '\t')
+		} else {
+			padding = append(padding, ' ')
+		}
+	}
+
+	return fmt.Sprintf("%s | %s\n%s | %s^ %s", gutter, line, strings.Repeat(" ", len(gutter)), padding, e.Message)
+}
+
+// ValidationErrors holds every error found in a document
+type ValidationErrors []*ValidationError
+
+func (errs ValidationErrors) Error() string {
+	msgs := make([]string, len(errs))
+	for i, err := range errs {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "\n")
+}
+
+// Errors returns the validation errors in err, which is an error returned by Validate.
+// Errors that didn't come from validation, e.g. an invalid schema, are returned as a single
+// ValidationError without a pointer.
+func Errors(err error) ValidationErrors {
+	switch e := err.(type) {
+	case nil:
+		return nil
+	case ValidationErrors:
+		return e
+	case *ValidationError:
+		return ValidationErrors{e}
+	default:
+		return ValidationErrors{{Message: err.Error()}}
+	}
+}
+
+func isValidationError(err error) bool {
+	switch err.(type) {
+	case ValidationErrors, *ValidationError:
+		return true
+	default:
+		return false
+	}
+}
+
+// asValidationError turns any error into a validation error, keeping existing validation errors
+func asValidationError(err error) error {
+	if err == nil || isValidationError(err) {
+		return err
+	}
+	return &ValidationError{Message: err.Error()}
+}
+
+// errorAt prefixes the instance pointer of every error in err with token, which is the key or
+// index of the value, that was validated, and moves the offset by the offset of the value in its parent
+func errorAt(err error, token string, offset int) error {
+	if err == nil {
+		return nil
+	}
+
+	errs := Errors(err)
+	for _, e := range errs {
+		e.InstancePointer = "/" + escapePointerToken(token) + e.InstancePointer
+		e.Offset += offset
+	}
+	if len(errs) == 1 {
+		return errs[0]
+	}
+	return errs
+}
+
+func errorAtIndex(err error, idx int, offset int) error {
+	if err == nil {
+		return nil
+	}
+	return errorAt(err, strconv.Itoa(idx), offset)
+}
+
+// errorAtKey is errorAt for the raw, possibly escaped, key of an object member
+func errorAtKey(err error, key []byte, offset int) error {
+	if err == nil {
+		return nil
+	}
+	if unescaped, unescapeErr := jsonparser.Unescape(key, nil); unescapeErr == nil {
+		key = unescaped
+	}
+	return errorAt(err, string(key), offset)
+}
+
+// The offsets jsonparser passes to callbacks are where the parser stopped, not where the value
+// starts, and string values are passed without their quotes.
+
+// arrayValueStart returns the offset of a value from the offset ArrayEach passes to its callback
+func arrayValueStart(offset int, dataType jsonparser.ValueType) int {
+	if dataType == jsonparser.String {
+		return offset - 2
+	}
+	return offset
+}
+
+// objectValueStart returns the offset of a value from the offset ObjectEach passes to its callback
+func objectValueStart(offset int, value []byte, dataType jsonparser.ValueType) int {
+	offset -= len(value)
+	if dataType == jsonparser.String {
+		offset -= 2
+	}
+	return offset
+}
+
+// keyStart returns the offset of the key of the object member, whose value starts at valueStart
+func keyStart(data []byte, valueStart int) int {
+	i := valueStart - 1
+	for i > 0 && (isJSONSpace(data[i]) || data[i] == ':') {
+		i--
+	}
+	// i is now at the closing quote of the key, so find the opening quote, that isn't escaped
+	for i--; i > 0; i-- {
+		if data[i] != '"' {
+			continue
+		}
+		escapes := 0
+		for j := i - 1; j >= 0 && data[j] == '\\'; j-- {
+			escapes++
+		}
+		if escapes%2 == 0 {
+			return i
+		}
+	}
+	return valueStart
+}
+
+func isJSONSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\r' || c == '\n'
+}
+
+// positionErrors sets the offset, line and column of every error in err, which was found by
+// validating the value at offset base in doc
+func positionErrors(err error, doc []byte, base int) {
+	for _, e := range Errors(err) {
+		e.Offset += base
+		if e.Offset < 0 || e.Offset > len(doc) {
+			continue
+		}
+		before := doc[:e.Offset]
+		lineStart := bytes.LastIndexByte(before, '\n') + 1
+		e.Line = bytes.Count(before, []byte("\n")) + 1
+		e.Column = utf8.RuneCount(before[lineStart:]) + 1
+	}
+}
@@ -0,0 +1,120 @@
+package jsonschema
+
+import (
+	"testing"
+)
+
+var errorPositionTests = []struct {
+	schema  string
+	doc     string
+	pointer string
+	offset  int
+	line    int
+	column  int
+}{
+	{
+		schema:  `{"properties": {"age": {"minimum": 0}}}`,
+		doc:     "{\n  \"name\": \"Jane\",\n  \"age\": -1\n}",
+		pointer: "/age",
+		offset:  29,
+		line:    3,
+		column:  10,
+	},
+	{
+		schema:  `{"items": {"properties": {"name": {"type": "string"}}}}`,
+		doc:     "[\n  {\"name\": \"a\"},\n  {\"name\": \"b\\\"c\"},\n  {\"name\": 1}\n]",
+		pointer: "/2/name",
+		offset:  50,
+		line:    4,
+		column:  12,
+	},
+	{
+		schema:  `{"items": {"maxLength": 1}}`,
+		doc:     `["ø", "æøå"]`,
+		pointer: "/1",
+		offset:  7,
+		line:    1,
+		column:  7,
+	},
+	{
+		schema:  `{"uniqueItems": true}`,
+		doc:     `[1, 2, 1]`,
+		pointer: "/2",
+		offset:  7,
+		line:    1,
+		column:  8,
+	},
+	{
+		schema:  `{"propertyNames": {"maxLength": 3}}`,
+		doc:     `{"abc": 1, "a\"bcd" : 2}`,
+		pointer: `/a"bcd`,
+		offset:  11,
+		line:    1,
+		column:  12,
+	},
+	{
+		schema:  `{"required": ["name"]}`,
+		doc:     "\n\n  {}",
+		pointer: "",
+		offset:  4,
+		line:    3,
+		column:  3,
+	},
+	{
+		schema:  `{"maxLength": 1}`,
+		doc:     ` "abc"`,
+		pointer: "",
+		offset:  1,
+		line:    1,
+		column:  2,
+	},
+}
+
+func TestErrorPositions(t *testing.T) {
+	for i, tt := range errorPositionTests {
+		schema, err := NewFromString(tt.schema)
+		if err != nil {
+			t.Fatalf("test #%d: %s", i+1, err)
+		}
+
+		_, err = schema.Validate([]byte(tt.doc))
+		errs := Errors(err)
+		if len(errs) != 1 {
+			t.Fatalf("test #%d: expected 1 error, got: %v", i+1, err)
+		}
+		e := errs[0]
+		if e.InstancePointer != tt.pointer || e.Offset != tt.offset || e.Line != tt.line || e.Column != tt.column {
+			t.Fatalf("test #%d: expected %q at offset %d (%d:%d), got: %q at offset %d (%d:%d)",
+				i+1, tt.pointer, tt.offset, tt.line, tt.column, e.InstancePointer, e.Offset, e.Line, e.Column)
+		}
+
+		// The position of JSON documents is kept out of the message
+		if e.Error() != e.Message {
+			t.Fatalf("test #%d: expected the error to be the message %q, got: %q", i+1, e.Message, e.Error())
+		}
+	}
+}
+
+func TestSnippet(t *testing.T) {
+	schema, err := NewFromString(`{"properties": {"age": {"minimum": 0}}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	doc := []byte("{\n\t\"name\": \"Jane\",\n\t\"age\": -1\n}")
+	_, err = schema.Validate(doc)
+	errs := Errors(err)
+	if len(errs) != 1 {
+		t.Fatalf("expected 1 error, got: %v", err)
+	}
+
+	expected := "3 | \t\"age\": -1\n  | \t       ^ value is less than the minimum of 0"
+	if snippet := errs[0].Snippet(doc); snippet != expected {
+		t.Fatalf("expected:\n%s\ngot:\n%s", expected, snippet)
+	}
+
+	unknown := &ValidationError{Message: "invalid"}
+	if snippet := unknown.Snippet(doc); snippet != "invalid" {
+		t.Fatalf("expected only the message, got: %s", snippet)
+	}
+}
@@ -0,0 +1,370 @@
+package jsonschema
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"math/rand"
+	"sort"
+	"strings"
+)
+
+// exampleFormats holds a fixed, valid value for each format known by the validator
+var exampleFormats = map[string]string{
+	"date-time":             "2006-01-02T15:04:05Z",
+	"date":                  "2006-01-02",
+	"time":                  "15:04:05Z",
+	"duration":              "P3D",
+	"email":                 "user@example.com",
+	"idn-email":             "user@example.com",
+	"hostname":              "example.com",
+	"idn-hostname":          "example.com",
+	"ipv4":                  "192.0.2.1",
+	"ipv6":                  "2001:db8::1",
+	"uuid":                  "3e4666bf-d5e5-4aa7-b8ce-cefe41c7568a",
+	"uri":                   "https://example.com/",
+	"uri-reference":         "/example",
+	"iri":                   "https://example.com/",
+	"iri-reference":         "/example",
+	"uri-template":          "https://example.com/{id}",
+	"json-pointer":          "/example",
+	"relative-json-pointer": "0/example",
+	"regex":                 "^example$",
+}
+
+// firstSource makes a generator always pick the first option, e.g. the first oneOf schema
+type firstSource struct{}
+
+func (firstSource) Int63() int64 { return 0 }
+func (firstSource) Seed(int64)   {}
+
+type exampler struct {
+	*generator
+
+	// building holds the object schemas currently being built, so recursive optional
+	// properties and items can be left out
+	building []*Schema
+}
+
+// Example returns a readable example document for the schema.
+// Each value is, in order of preference, the first of examples, default, const, the first
+// enum value or a placeholder matching the type and constraints of the schema.
+// It follows properties, items, $refs, allOf and the first schema of anyOf and oneOf.
+// Optional properties are included, unless they are recursive.
+// An error is returned if the resulting document isn't valid against the schema.
+func Example(s *Schema) ([]byte, error) {
+	if s == nil {
+		return nil, errors.New("invalid schema")
+	}
+
+	ex := &exampler{generator: &generator{rnd: rand.New(firstSource{})}}
+	v, err := ex.value(s, 0)
+	if err != nil {
+		return nil, fmt.Errorf("unable to build an example: %w", err)
+	}
+
+	doc, err := encodeGenerated(v)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.Validate(doc); err != nil {
+		return nil, fmt.Errorf("unable to build a valid example: %w", err)
+	}
+
+	return doc, nil
+}
+
+func (ex *exampler) value(s *Schema, depth int) (interface{}, error) {
+	if depth > 4*maxGenerateDepth {
+		return nil, errors.New("maximum depth reached")
+	}
+	if s == nil {
+		return map[string]interface{}{}, nil
+	}
+
+	s, err := followRefs(s)
+	if err != nil {
+		return nil, err
+	}
+	if s.boolean != nil {
+		if !*s.boolean {
+			return nil, errors.New("no value is valid against false")
+		}
+		return map[string]interface{}{}, nil
+	}
+
+	resolved := s
+	s, err = ex.merge(s)
+	if err != nil {
+		return nil, err
+	}
+
+	// Examples and defaults aren't validated by the spec, so they're only used if valid
+	candidates := []*Value{}
+	if s.Examples != nil && len(*s.Examples) > 0 {
+		candidates = append(candidates, (*s.Examples)[0])
+	}
+	if s.Default != nil {
+		candidates = append(candidates, s.Default)
+	}
+	if s.Const != nil {
+		candidates = append(candidates, s.Const)
+	}
+	if s.Enum != nil && len(*s.Enum) > 0 {
+		candidates = append(candidates, (*s.Enum)[0])
+	}
+	for _, candidate := range candidates {
+		v, err := generatedValue(candidate)
+		if err == nil && isValidExample(resolved, v) {
+			return v, nil
+		}
+	}
+
+	types := schemaTypes(s)
+	if len(types) == 0 {
+		types = inferType(s)
+	}
+	typ := "object"
+	for i, t := range types {
+		if t != "null" || i == len(types)-1 {
+			typ = t
+			break
+		}
+	}
+
+	switch typ {
+	case "null":
+		return nil, nil
+	case "boolean":
+		return true, nil
+	case "integer", "number":
+		return ex.number(s, typ == "integer")
+	case "string":
+		return ex.str(s)
+	case "array":
+		return ex.array(s, depth)
+	default:
+		if ex.isBuilding(resolved) {
+			return nil, errors.New("recursive schema")
+		}
+		return ex.object(s, resolved, depth)
+	}
+}
+
+// number returns the valid number closest to zero, preferring whole numbers
+func (ex *exampler) number(s *Schema, integer bool) (interface{}, error) {
+	lo, hi, loExcl, hiExcl := numberBounds(s)
+
+	step, err := numberStep(s, integer)
+	if err != nil {
+		return nil, err
+	}
+
+	steps := []*big.Rat{step}
+	if step == nil {
+		steps = []*big.Rat{big.NewRat(1, 1), big.NewRat(1, 100)}
+	}
+
+	for _, step := range steps {
+		kMin, kMax := multipleRange(lo, hi, loExcl, hiExcl, step)
+		k := big.NewInt(0)
+		if kMin != nil && kMin.Sign() > 0 {
+			k = kMin
+		}
+		if kMax != nil && kMax.Sign() < 0 {
+			k = kMax
+		}
+		if (kMin == nil || k.Cmp(kMin) >= 0) && (kMax == nil || k.Cmp(kMax) <= 0) {
+			return ratNumber(new(big.Rat).Mul(new(big.Rat).SetInt(k), step)), nil
+		}
+	}
+
+	if step == nil && lo != nil && hi != nil && lo.Cmp(hi) < 0 {
+		mid := new(big.Rat).Add(lo, hi)
+		return ratNumber(mid.Quo(mid, big.NewRat(2, 1))), nil
+	}
+
+	return nil, errors.New("no number exists within the bounds")
+}
+
+func (ex *exampler) str(s *Schema) (interface{}, error) {
+	if s.Format != nil {
+		if str, ok := exampleFormats[*s.Format]; ok {
+			return str, nil
+		}
+	}
+
+	if s.Pattern != nil {
+		return ex.generator.str(&Schema{Pattern: s.Pattern})
+	}
+
+	str := "string"
+	if s.MinLength != nil && int(*s.MinLength) > len(str) {
+		str += strings.Repeat("s", int(*s.MinLength)-len(str))
+	}
+	if s.MaxLength != nil && int(*s.MaxLength) < len(str) {
+		str = str[:*s.MaxLength]
+	}
+
+	return str, nil
+}
+
+func (ex *exampler) array(s *Schema, depth int) (interface{}, error) {
+	count := 0
+	if s.MinItems != nil {
+		count = int(*s.MinItems)
+	}
+	// Show a single item, if there is anything to show
+	if count == 0 && s.Items != nil && (s.Items.Schema != nil || s.Items.Schemas != nil) {
+		count = 1
+	}
+	if s.Items != nil && s.Items.Schemas != nil && count < len(*s.Items.Schemas) {
+		count = len(*s.Items.Schemas)
+	}
+	if s.MaxItems != nil && int(*s.MaxItems) < count {
+		count = int(*s.MaxItems)
+	}
+
+	minItems := 0
+	if s.MinItems != nil {
+		minItems = int(*s.MinItems)
+	}
+
+	items := []interface{}{}
+	for i := 0; i < count; i++ {
+		sub, ok := itemSchema(s, i)
+		if !ok {
+			break
+		}
+		item, err := ex.value(sub, depth+1)
+		if err != nil && i >= minItems {
+			// Optional items are left out, e.g. for recursive schemas
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+
+	if s.Contains != nil {
+		item, err := ex.value(s.Contains, depth+1)
+		if err != nil {
+			return nil, err
+		}
+		if len(items) == 0 {
+			items = append(items, item)
+		} else {
+			items[0] = item
+		}
+	}
+
+	return items, nil
+}
+
+// object builds an example of s, which is the result of merging resolved with its allOf etc.
+func (ex *exampler) object(s, resolved *Schema, depth int) (interface{}, error) {
+	ex.building = append(ex.building, resolved)
+	defer func() { ex.building = ex.building[:len(ex.building)-1] }()
+
+	obj := map[string]interface{}{}
+	full := func() bool { return s.MaxProperties != nil && len(obj) >= int(*s.MaxProperties) }
+
+	propertySchema := func(name string) *Schema {
+		if s.Properties != nil {
+			if prop, ok := s.Properties.GetProperty(name); ok {
+				return prop.Property
+			}
+		}
+		return s.AdditionalProperties
+	}
+
+	if s.Required != nil {
+		for _, req := range *s.Required {
+			if _, ok := obj[*req]; ok {
+				continue
+			}
+			v, err := ex.value(propertySchema(*req), depth+1)
+			if err != nil {
+				return nil, err
+			}
+			obj[*req] = v
+		}
+	}
+
+	if s.Properties != nil {
+		for _, prop := range *s.Properties {
+			if _, ok := obj[prop.Name]; ok || full() {
+				continue
+			}
+			// Optional properties are left out, if no valid example can be made
+			if v, err := ex.value(prop.Property, depth+1); err == nil && isValidExample(prop.Property, v) {
+				obj[prop.Name] = v
+			}
+		}
+	}
+
+	if s.Dependencies != nil {
+		names := []string{}
+		for name := range *s.Dependencies {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			dep := (*s.Dependencies)[name]
+			if _, ok := obj[name]; !ok || dep.Strings == nil {
+				continue
+			}
+			for _, depName := range *dep.Strings {
+				if _, ok := obj[*depName]; ok {
+					continue
+				}
+				v, err := ex.value(propertySchema(*depName), depth+1)
+				if err != nil {
+					return nil, err
+				}
+				obj[*depName] = v
+			}
+		}
+	}
+
+	for i := 1; s.MinProperties != nil && len(obj) < int(*s.MinProperties); i++ {
+		name := fmt.Sprintf("property%d", i)
+		if _, ok := obj[name]; ok {
+			continue
+		}
+		v, err := ex.value(propertySchema(name), depth+1)
+		if err != nil {
+			return nil, err
+		}
+		obj[name] = v
+	}
+
+	return obj, nil
+}
+
+// isBuilding reports whether s is an object schema, that is currently being built
+func (ex *exampler) isBuilding(s *Schema) bool {
+	s, err := followRefs(s)
+	if err != nil {
+		return false
+	}
+	for _, building := range ex.building {
+		if building == s {
+			return true
+		}
+	}
+	return false
+}
+
+// isValidExample reports whether v is valid against s
+func isValidExample(s *Schema, v interface{}) bool {
+	doc, err := encodeGenerated(v)
+	if err != nil {
+		return false
+	}
+	_, err = s.Validate(doc)
+	return err == nil
+}
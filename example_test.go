@@ -0,0 +1,53 @@
+package jsonschema
+
+import "testing"
+
+var exampleTests = []struct {
+	schema   string
+	expected string
+}{
+	{
+		schema:   `{"type":"object","required":["id","name"],"properties":{"id":{"type":"integer","minimum":1},"name":{"type":"string","examples":["Alice","Bob"]},"role":{"enum":["admin","user"]},"active":{"type":["null","boolean"],"default":false},"score":{"type":"number","exclusiveMaximum":0,"multipleOf":0.5},"created":{"format":"date-time"},"code":{"pattern":"^[A-Z]{3}-\\d{2,4}$"},"tags":{"type":"array","items":{"type":"string","minLength":8}},"kind":{"oneOf":[{"const":"a"},{"const":"b"}]}}}`,
+		expected: `{"active":false,"code":"AAA-00","created":"2006-01-02T15:04:05Z","id":1,"kind":"a","name":"Alice","role":"admin","score":-0.5,"tags":["stringss"]}`,
+	},
+	{
+		// Recursive optional properties are left out and invalid defaults are ignored
+		schema:   `{"definitions":{"node":{"type":"object","properties":{"value":{"type":"integer","default":"none"},"children":{"type":"array","items":{"$ref":"#/definitions/node"}}}}},"$ref":"#/definitions/node"}`,
+		expected: `{"children":[],"value":0}`,
+	},
+	{
+		schema:   `{"allOf":[{"properties":{"a":{"type":"string","maxLength":3}},"required":["a"]},{"properties":{"b":{"const":3}},"required":["b"]}]}`,
+		expected: `{"a":"str","b":3}`,
+	},
+	{
+		schema:   `{"type":"array","items":[{"type":"boolean"},{"type":"null"}],"contains":{"type":"boolean"}}`,
+		expected: `[true,null]`,
+	},
+}
+
+func TestExample(t *testing.T) {
+	for _, tt := range exampleTests {
+		s, err := NewFromString(tt.schema)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		doc, err := Example(s)
+		if err != nil {
+			t.Fatalf("unable to build an example for %s: %s", tt.schema, err)
+		}
+		if string(doc) != tt.expected {
+			t.Fatalf("expected example to match:\n%s\ngot:\n%s\n", tt.expected, doc)
+		}
+	}
+}
+
+func TestExampleInvalid(t *testing.T) {
+	s, err := NewFromString(`{"type":"integer","not":{"minimum":0}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if doc, err := Example(s); err == nil {
+		t.Fatalf("expected an error, got: %s", doc)
+	}
+}
@@ -0,0 +1,1198 @@
+package jsonschema
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"math/rand"
+	"reflect"
+	"regexp"
+	"regexp/syntax"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// maxGenerateAttempts is the number of documents tried, before giving up on a schema
+	maxGenerateAttempts = 100
+
+	// maxGenerateDepth limits recursive schemas - deeper than this, only required properties
+	// and the minimum number of items are generated
+	maxGenerateDepth = 6
+)
+
+const generatedChars = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+
+var generatedTypes = []string{"null", "boolean", "integer", "number", "string", "array", "object"}
+
+// formatGenerators creates valid values for the formats known by the validator
+var formatGenerators = map[string]func(g *generator) string{
+	"date-time": func(g *generator) string { return g.time().Format(time.RFC3339) },
+	"date":      func(g *generator) string { return g.time().Format("2006-01-02") },
+	"time":      func(g *generator) string { return g.time().Format("15:04:05Z07:00") },
+	"duration": func(g *generator) string {
+		return fmt.Sprintf("P%dDT%dH", g.rnd.Intn(30)+1, g.rnd.Intn(24))
+	},
+	"email":        func(g *generator) string { return g.letters(1, 8) + "@example.com" },
+	"idn-email":    func(g *generator) string { return g.letters(1, 8) + "@example.com" },
+	"hostname":     func(g *generator) string { return g.letters(1, 8) + ".example.com" },
+	"idn-hostname": func(g *generator) string { return g.letters(1, 8) + ".example.com" },
+	"ipv4": func(g *generator) string {
+		return fmt.Sprintf("%d.%d.%d.%d", g.rnd.Intn(256), g.rnd.Intn(256), g.rnd.Intn(256), g.rnd.Intn(256))
+	},
+	"ipv6": func(g *generator) string {
+		return fmt.Sprintf("2001:db8::%x:%x", g.rnd.Intn(0x10000), g.rnd.Intn(0x10000))
+	},
+	"uuid": func(g *generator) string {
+		return fmt.Sprintf("%08x-%04x-4%03x-%04x-%012x",
+			g.rnd.Uint32(), g.rnd.Intn(0x10000), g.rnd.Intn(0x1000), 0x8000|g.rnd.Intn(0x4000), g.rnd.Int63n(1<<48))
+	},
+	"uri":                   func(g *generator) string { return "https://example.com/" + g.letters(0, 8) },
+	"uri-reference":         func(g *generator) string { return "/" + g.letters(0, 8) },
+	"iri":                   func(g *generator) string { return "https://example.com/" + g.letters(0, 8) },
+	"iri-reference":         func(g *generator) string { return "/" + g.letters(0, 8) },
+	"uri-template":          func(g *generator) string { return "https://example.com/{" + g.letters(1, 8) + "}" },
+	"json-pointer":          func(g *generator) string { return "/" + g.letters(1, 8) },
+	"relative-json-pointer": func(g *generator) string { return strconv.Itoa(g.rnd.Intn(3)) + "/" + g.letters(1, 8) },
+	"regex":                 func(g *generator) string { return "^" + g.letters(1, 8) + "$" },
+}
+
+// Violation describes how a document from GenerateInvalid was made invalid
+type Violation struct {
+	// Keyword is the keyword the document was changed to violate, e.g. "minimum"
+	Keyword string
+
+	// Pointer is the JSON Pointer to the value in the document, that was changed
+	Pointer string
+}
+
+func (v Violation) String() string {
+	return v.Keyword + " at " + v.Pointer
+}
+
+type generator struct {
+	rnd *rand.Rand
+}
+
+// Generate returns a random document, that is valid against the schema.
+// It follows type, enum, const, numeric bounds, multipleOf, string lengths, pattern, format,
+// properties, required, additionalProperties, array bounds, uniqueItems and $refs.
+// Anything else, like not and if / then / else, is handled by generating documents,
+// until one validates, so schemas with very narrow constraints may return an error.
+// The same source and schema always results in the same document.
+func Generate(s *Schema, src rand.Source) ([]byte, error) {
+	if s == nil {
+		return nil, errors.New("invalid schema")
+	}
+
+	g := &generator{rnd: rand.New(src)}
+	return g.generate(s)
+}
+
+// GenerateInvalid returns a random document, that is invalid against the schema.
+// It generates a valid document and changes a single value, so it violates one keyword,
+// making it a near miss, that should exercise a single check in whatever consumes it.
+// Only documents, where the violated keyword is the only error Validate reports, are returned.
+func GenerateInvalid(s *Schema, src rand.Source) ([]byte, *Violation, error) {
+	if s == nil {
+		return nil, nil, errors.New("invalid schema")
+	}
+
+	g := &generator{rnd: rand.New(src)}
+
+	for attempt := 0; attempt < maxGenerateAttempts; attempt++ {
+		valid, err := g.generate(s)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		// The mutations change the document in place, so the document is decoded again for each
+		root, err := decodeGenerated(valid)
+		if err != nil {
+			return nil, nil, err
+		}
+		count := len(g.mutations(s, root, func(v interface{}) { root = v }, "", 0))
+
+		for _, i := range g.rnd.Perm(count) {
+			root, err := decodeGenerated(valid)
+			if err != nil {
+				return nil, nil, err
+			}
+			muts := g.mutations(s, root, func(v interface{}) { root = v }, "", 0)
+			if i >= len(muts) {
+				continue
+			}
+			mut := muts[i]
+			mut.apply()
+
+			doc, err := encodeGenerated(root)
+			if err != nil {
+				return nil, nil, err
+			}
+			// Mutations, that also break other keywords, aren't near misses
+			if _, err := s.Validate(doc); mut.violates(err) {
+				return doc, &Violation{Keyword: mut.keyword, Pointer: mut.pointer}, nil
+			}
+		}
+	}
+
+	return nil, nil, errors.New("unable to generate an invalid document")
+}
+
+func (g *generator) generate(s *Schema) ([]byte, error) {
+	var lastErr error
+	for attempt := 0; attempt < maxGenerateAttempts; attempt++ {
+		v, err := g.value(s, 0)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+
+		doc, err := encodeGenerated(v)
+		if err != nil {
+			return nil, err
+		}
+
+		if _, err := s.Validate(doc); err != nil {
+			lastErr = err
+			continue
+		}
+		return doc, nil
+	}
+
+	return nil, fmt.Errorf("unable to generate a valid document: %w", lastErr)
+}
+
+func encodeGenerated(v interface{}) ([]byte, error) {
+	buf := &bytes.Buffer{}
+	enc := json.NewEncoder(buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	return bytes.TrimRight(buf.Bytes(), "\n"), nil
+}
+
+func decodeGenerated(doc []byte) (interface{}, error) {
+	var v interface{}
+	dec := json.NewDecoder(bytes.NewReader(doc))
+	dec.UseNumber()
+	err := dec.Decode(&v)
+	return v, err
+}
+
+// value generates a value for s, as a type encoding/json can marshal
+func (g *generator) value(s *Schema, depth int) (interface{}, error) {
+	if depth > 4*maxGenerateDepth {
+		// Only happens when recursive properties are required
+		return nil, errors.New("maximum depth reached")
+	}
+	if s == nil {
+		return g.value(&Schema{}, depth)
+	}
+
+	s, err := followRefs(s)
+	if err != nil {
+		return nil, err
+	}
+	if s.boolean != nil {
+		if !*s.boolean {
+			return nil, errors.New("no value is valid against false")
+		}
+		s = &Schema{}
+	}
+
+	s, err = g.merge(s)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.Const != nil {
+		return generatedValue(s.Const)
+	}
+	if s.Enum != nil && len(*s.Enum) > 0 {
+		return generatedValue((*s.Enum)[g.rnd.Intn(len(*s.Enum))])
+	}
+
+	switch g.pickType(s, depth) {
+	case "null":
+		return nil, nil
+	case "boolean":
+		return g.rnd.Intn(2) == 0, nil
+	case "integer":
+		return g.number(s, true)
+	case "number":
+		return g.number(s, false)
+	case "string":
+		return g.str(s)
+	case "array":
+		return g.array(s, depth)
+	default:
+		return g.object(s, depth)
+	}
+}
+
+// followRefs follows $refs, until it reaches a schema without one
+func followRefs(s *Schema) (*Schema, error) {
+	for i := 0; s.Ref != nil; i++ {
+		if i > maxGenerateAttempts {
+			return nil, errors.New("too many nested $refs")
+		}
+
+		target := s.Ref.Schema
+		if target == nil {
+			var err error
+			target, err = s.ResolveRef(s.Ref)
+			if err != nil {
+				return nil, err
+			}
+		}
+		s = target
+	}
+	return s, nil
+}
+
+// merge combines s with its allOf schemas and a random anyOf and oneOf schema
+func (g *generator) merge(s *Schema) (*Schema, error) {
+	subs := []*Schema{}
+	if s.AllOf != nil {
+		subs = append(subs, *s.AllOf...)
+	}
+	if s.AnyOf != nil && len(*s.AnyOf) > 0 {
+		subs = append(subs, (*s.AnyOf)[g.rnd.Intn(len(*s.AnyOf))])
+	}
+	if s.OneOf != nil && len(*s.OneOf) > 0 {
+		subs = append(subs, (*s.OneOf)[g.rnd.Intn(len(*s.OneOf))])
+	}
+	// Whether the value ends up matching if is only known after validation, so either branch is tried
+	if s.If != nil {
+		if g.rnd.Intn(2) == 0 {
+			subs = append(subs, s.If)
+			if s.Then != nil {
+				subs = append(subs, s.Then)
+			}
+		} else if s.Else != nil {
+			subs = append(subs, s.Else)
+		}
+	}
+	if len(subs) == 0 {
+		return s, nil
+	}
+
+	merged := *s
+	merged.AllOf, merged.AnyOf, merged.OneOf = nil, nil, nil
+	merged.If, merged.Then, merged.Else = nil, nil, nil
+
+	for _, sub := range subs {
+		sub, err := followRefs(sub)
+		if err != nil {
+			return nil, err
+		}
+		if sub.boolean != nil {
+			if !*sub.boolean {
+				return nil, errors.New("no value is valid against false")
+			}
+			continue
+		}
+		sub, err = g.merge(sub)
+		if err != nil {
+			return nil, err
+		}
+		mergeSchema(&merged, sub)
+	}
+
+	return &merged, nil
+}
+
+// mergeSchema copies the keywords of src, that are not set in dst, to dst.
+// Properties and required are combined, and type becomes the types allowed by both.
+func mergeSchema(dst, src *Schema) {
+	dv := reflect.ValueOf(dst).Elem()
+	sv := reflect.ValueOf(src).Elem()
+	for i := 0; i < dv.NumField(); i++ {
+		field := dv.Field(i)
+		if !field.CanSet() || field.Kind() != reflect.Ptr {
+			continue
+		}
+		if field.IsNil() {
+			field.Set(sv.Field(i))
+		}
+	}
+
+	if dst.Properties != src.Properties && src.Properties != nil {
+		props := append(Properties{}, *dst.Properties...)
+		for _, prop := range *src.Properties {
+			if _, ok := props.GetProperty(prop.Name); !ok {
+				props = append(props, prop)
+			}
+		}
+		dst.Properties = &props
+	}
+
+	if dst.Required != src.Required && src.Required != nil {
+		required := append(Strings{}, *dst.Required...)
+		required = append(required, *src.Required...)
+		dst.Required = &required
+	}
+
+	if dst.Type != src.Type && src.Type != nil {
+		allowed := map[string]bool{}
+		for _, typ := range schemaTypes(src) {
+			allowed[typ] = true
+		}
+
+		both := []*string{}
+		for _, typ := range schemaTypes(dst) {
+			if allowed[typ] || (typ == "integer" && allowed["number"]) {
+				both = append(both, stringPtr(typ))
+			} else if typ == "number" && allowed["integer"] {
+				both = append(both, stringPtr("integer"))
+			}
+		}
+		if len(both) > 0 {
+			dst.Type = &Type{Strings: &both}
+		}
+	}
+}
+
+func schemaTypes(s *Schema) []string {
+	if s.Type == nil {
+		return nil
+	}
+	if s.Type.String != nil {
+		return []string{*s.Type.String}
+	}
+
+	types := []string{}
+	if s.Type.Strings != nil {
+		for _, typ := range *s.Type.Strings {
+			types = append(types, *typ)
+		}
+	}
+	return types
+}
+
+// pickType picks one of the allowed types, or infers it from the keywords in use
+func (g *generator) pickType(s *Schema, depth int) string {
+	types := schemaTypes(s)
+	if len(types) == 0 {
+		types = inferType(s)
+	}
+	if len(types) == 0 {
+		types = generatedTypes
+		if depth >= maxGenerateDepth {
+			types = generatedTypes[:5]
+		}
+	}
+
+	return types[g.rnd.Intn(len(types))]
+}
+
+// inferType returns the type implied by the keywords used in s, if any
+func inferType(s *Schema) []string {
+	switch {
+	case s.Properties != nil || s.Required != nil || s.AdditionalProperties != nil || s.PatternProperties != nil ||
+		s.MinProperties != nil || s.MaxProperties != nil || s.PropertyNames != nil || s.Dependencies != nil:
+		return []string{"object"}
+	case s.Items != nil || s.MinItems != nil || s.MaxItems != nil || s.UniqueItems != nil || s.Contains != nil:
+		return []string{"array"}
+	case s.MinLength != nil || s.MaxLength != nil || s.Pattern != nil || s.Format != nil:
+		return []string{"string"}
+	case s.Minimum != nil || s.Maximum != nil || s.ExclusiveMinimum != nil || s.ExclusiveMaximum != nil || s.MultipleOf != nil:
+		return []string{"number"}
+	}
+	return nil
+}
+
+// number generates an integer or number within the bounds of s
+func (g *generator) number(s *Schema, integer bool) (json.Number, error) {
+	lo, hi, loExcl, hiExcl := numberBounds(s)
+
+	span := big.NewRat(1000, 1)
+	switch {
+	case lo == nil && hi == nil:
+		lo, hi = new(big.Rat).Neg(span), span
+	case lo == nil:
+		lo = new(big.Rat).Sub(hi, span)
+	case hi == nil:
+		hi = new(big.Rat).Add(lo, span)
+	}
+
+	step, err := numberStep(s, integer)
+	if err != nil {
+		return "", err
+	}
+	if step == nil {
+		step = big.NewRat(1, 100)
+	}
+
+	val, ok := g.multiple(lo, hi, loExcl, hiExcl, step)
+	if !ok && s.MultipleOf == nil && !integer {
+		// The range is narrower than the step, so use the middle
+		val = new(big.Rat).Add(lo, hi)
+		val.Quo(val, big.NewRat(2, 1))
+		ok = lo.Cmp(hi) < 0 || (!loExcl && !hiExcl && lo.Cmp(hi) == 0)
+	}
+	if !ok {
+		return "", errors.New("no number exists within the bounds")
+	}
+
+	return ratNumber(val), nil
+}
+
+// numberBounds returns the lower and upper bound of s, if any, and whether they are exclusive
+func numberBounds(s *Schema) (lo, hi *big.Rat, loExcl, hiExcl bool) {
+	if s.Minimum != nil && s.Minimum.Number != nil {
+		lo, _ = s.Minimum.Number.Rat(nil)
+	}
+	if s.ExclusiveMinimum != nil {
+		if s.ExclusiveMinimum.Boolean != nil {
+			loExcl = *s.ExclusiveMinimum.Boolean
+		} else if s.ExclusiveMinimum.Number != nil {
+			excl, _ := s.ExclusiveMinimum.Number.Rat(nil)
+			if lo == nil || excl.Cmp(lo) >= 0 {
+				lo, loExcl = excl, true
+			}
+		}
+	}
+
+	if s.Maximum != nil && s.Maximum.Number != nil {
+		hi, _ = s.Maximum.Number.Rat(nil)
+	}
+	if s.ExclusiveMaximum != nil {
+		if s.ExclusiveMaximum.Boolean != nil {
+			hiExcl = *s.ExclusiveMaximum.Boolean
+		} else if s.ExclusiveMaximum.Number != nil {
+			excl, _ := s.ExclusiveMaximum.Number.Rat(nil)
+			if hi == nil || excl.Cmp(hi) <= 0 {
+				hi, hiExcl = excl, true
+			}
+		}
+	}
+
+	return lo, hi, loExcl, hiExcl
+}
+
+// numberStep returns the step between valid numbers, or nil for numbers without multipleOf
+func numberStep(s *Schema, integer bool) (*big.Rat, error) {
+	if s.MultipleOf != nil {
+		step, ok := new(big.Rat).SetString(string(*s.MultipleOf))
+		if !ok || step.Sign() <= 0 {
+			return nil, fmt.Errorf("invalid multipleOf: %s", *s.MultipleOf)
+		}
+		if integer && !step.IsInt() {
+			// A multiple of the numerator is always a whole multiple of step
+			step = new(big.Rat).SetInt(step.Num())
+		}
+		return step, nil
+	}
+	if integer {
+		return big.NewRat(1, 1), nil
+	}
+	return nil, nil
+}
+
+// multiple returns a random multiple of step between lo and hi
+func (g *generator) multiple(lo, hi *big.Rat, loExcl, hiExcl bool, step *big.Rat) (*big.Rat, bool) {
+	kMin, kMax := multipleRange(lo, hi, loExcl, hiExcl, step)
+	if kMin.Cmp(kMax) > 0 {
+		return nil, false
+	}
+
+	n := new(big.Int).Sub(kMax, kMin)
+	n.Add(n, big.NewInt(1))
+	k := new(big.Int).Rand(g.rnd, n)
+	k.Add(k, kMin)
+
+	return new(big.Rat).Mul(new(big.Rat).SetInt(k), step), true
+}
+
+// multipleRange returns the range of k, where k*step is within the bounds.
+// kMin is nil if lo is, and kMax is nil if hi is.
+func multipleRange(lo, hi *big.Rat, loExcl, hiExcl bool, step *big.Rat) (kMin, kMax *big.Int) {
+	if lo != nil {
+		kMin = ratCeil(new(big.Rat).Quo(lo, step))
+		if loExcl && new(big.Rat).Mul(new(big.Rat).SetInt(kMin), step).Cmp(lo) == 0 {
+			kMin.Add(kMin, big.NewInt(1))
+		}
+	}
+
+	if hi != nil {
+		kMax = ratFloor(new(big.Rat).Quo(hi, step))
+		if hiExcl && new(big.Rat).Mul(new(big.Rat).SetInt(kMax), step).Cmp(hi) == 0 {
+			kMax.Sub(kMax, big.NewInt(1))
+		}
+	}
+
+	return kMin, kMax
+}
+
+// ratFloor rounds r down - big.Int.Div uses Euclidean division, which is floor for positive divisors
+func ratFloor(r *big.Rat) *big.Int {
+	return new(big.Int).Div(r.Num(), r.Denom())
+}
+
+func ratCeil(r *big.Rat) *big.Int {
+	q, m := new(big.Int).DivMod(r.Num(), r.Denom(), new(big.Int))
+	if m.Sign() != 0 {
+		q.Add(q, big.NewInt(1))
+	}
+	return q
+}
+
+// str generates a string matching the format or pattern of s, or a random string of a valid length
+func (g *generator) str(s *Schema) (string, error) {
+	if s.Format != nil {
+		if gen, ok := formatGenerators[*s.Format]; ok {
+			return gen(g), nil
+		}
+	}
+
+	if s.Pattern != nil {
+		re, err := syntax.Parse(*s.Pattern, syntax.Perl)
+		if err != nil {
+			return "", err
+		}
+		buf := &strings.Builder{}
+		if err := g.regexString(buf, re.Simplify()); err != nil {
+			return "", err
+		}
+		return buf.String(), nil
+	}
+
+	minLen := 0
+	if s.MinLength != nil {
+		minLen = int(*s.MinLength)
+	}
+	maxLen := minLen + 10
+	if s.MaxLength != nil && int(*s.MaxLength) < maxLen {
+		maxLen = int(*s.MaxLength)
+	}
+	if maxLen < minLen {
+		return "", errors.New("maxLength is less than minLength")
+	}
+
+	return g.letters(minLen, maxLen), nil
+}
+
+// letters returns a random string of letters and digits, with a length between min and max
+func (g *generator) letters(min, max int) string {
+	b := make([]byte, min+g.rnd.Intn(max-min+1))
+	for i := range b {
+		b[i] = generatedChars[g.rnd.Intn(len(generatedChars))]
+	}
+	return string(b)
+}
+
+func (g *generator) time() time.Time {
+	return time.Date(1970+g.rnd.Intn(100), time.Month(1+g.rnd.Intn(12)), 1+g.rnd.Intn(28),
+		g.rnd.Intn(24), g.rnd.Intn(60), g.rnd.Intn(60), 0, time.UTC)
+}
+
+// regexString writes a random string matching re
+func (g *generator) regexString(buf *strings.Builder, re *syntax.Regexp) error {
+	switch re.Op {
+	case syntax.OpNoMatch:
+		return errors.New("pattern doesn't match anything")
+
+	case syntax.OpLiteral:
+		buf.WriteString(string(re.Rune))
+
+	case syntax.OpCharClass:
+		if len(re.Rune) == 0 {
+			return errors.New("pattern has an empty character class")
+		}
+		// Prefer printable ASCII, as negated classes contains every other rune too
+		printable := [][2]rune{}
+		for i := 0; i+1 < len(re.Rune); i += 2 {
+			lo, hi := re.Rune[i], re.Rune[i+1]
+			if lo < ' ' {
+				lo = ' '
+			}
+			if hi > '~' {
+				hi = '~'
+			}
+			if lo <= hi {
+				printable = append(printable, [2]rune{lo, hi})
+			}
+		}
+		if len(printable) == 0 {
+			printable = append(printable, [2]rune{re.Rune[0], re.Rune[1]})
+		}
+		r := printable[g.rnd.Intn(len(printable))]
+		buf.WriteRune(r[0] + rune(g.rnd.Intn(int(r[1]-r[0])+1)))
+
+	case syntax.OpAnyChar, syntax.OpAnyCharNotNL:
+		buf.WriteByte(generatedChars[g.rnd.Intn(len(generatedChars))])
+
+	case syntax.OpCapture:
+		return g.regexString(buf, re.Sub[0])
+
+	case syntax.OpStar, syntax.OpPlus, syntax.OpQuest, syntax.OpRepeat:
+		min, max := 0, 3
+		switch re.Op {
+		case syntax.OpPlus:
+			min, max = 1, 4
+		case syntax.OpQuest:
+			max = 1
+		case syntax.OpRepeat:
+			min, max = re.Min, re.Max
+			if max < 0 || max > min+5 {
+				max = min + 5
+			}
+		}
+		for i := min + g.rnd.Intn(max-min+1); i > 0; i-- {
+			if err := g.regexString(buf, re.Sub[0]); err != nil {
+				return err
+			}
+		}
+
+	case syntax.OpConcat:
+		for _, sub := range re.Sub {
+			if err := g.regexString(buf, sub); err != nil {
+				return err
+			}
+		}
+
+	case syntax.OpAlternate:
+		return g.regexString(buf, re.Sub[g.rnd.Intn(len(re.Sub))])
+	}
+
+	// Anchors and empty matches doesn't add anything
+	return nil
+}
+
+func (g *generator) array(s *Schema, depth int) ([]interface{}, error) {
+	minItems := 0
+	if s.MinItems != nil {
+		minItems = int(*s.MinItems)
+	}
+	maxItems := minItems + 3
+	if s.MaxItems != nil && int(*s.MaxItems) < maxItems {
+		maxItems = int(*s.MaxItems)
+	}
+	if maxItems < minItems {
+		return nil, errors.New("maxItems is less than minItems")
+	}
+
+	count := minItems
+	if depth < maxGenerateDepth {
+		count += g.rnd.Intn(maxItems - minItems + 1)
+	}
+
+	unique := s.UniqueItems != nil && *s.UniqueItems
+	seen := map[string]struct{}{}
+	items := []interface{}{}
+
+	for i := 0; i < count; i++ {
+		sub, ok := itemSchema(s, i)
+		if !ok {
+			break
+		}
+
+		var item interface{}
+		for attempt := 0; attempt < 10; attempt++ {
+			var err error
+			if item, err = g.value(sub, depth+1); err != nil {
+				return nil, err
+			}
+			if !unique {
+				break
+			}
+			key, err := encodeGenerated(item)
+			if err != nil {
+				return nil, err
+			}
+			if _, ok := seen[string(key)]; !ok {
+				seen[string(key)] = struct{}{}
+				break
+			}
+		}
+		items = append(items, item)
+	}
+
+	if s.Contains != nil {
+		item, err := g.value(s.Contains, depth+1)
+		if err != nil {
+			return nil, err
+		}
+		if len(items) == 0 || len(items) < maxItems {
+			items = append(items, item)
+		} else {
+			items[g.rnd.Intn(len(items))] = item
+		}
+	}
+
+	return items, nil
+}
+
+// itemSchema returns the schema for the item at index i, or false if no item is allowed at i
+func itemSchema(s *Schema, i int) (*Schema, bool) {
+	if s.Items == nil {
+		return nil, true
+	}
+	if s.Items.Boolean != nil {
+		return nil, *s.Items.Boolean
+	}
+	if s.Items.Schemas != nil {
+		if i < len(*s.Items.Schemas) {
+			return (*s.Items.Schemas)[i], true
+		}
+		if s.AdditionalItems != nil && s.AdditionalItems.boolean != nil {
+			return nil, *s.AdditionalItems.boolean
+		}
+		return s.AdditionalItems, true
+	}
+	return s.Items.Schema, true
+}
+
+func (g *generator) object(s *Schema, depth int) (map[string]interface{}, error) {
+	obj := map[string]interface{}{}
+
+	maxProps := -1
+	if s.MaxProperties != nil {
+		maxProps = int(*s.MaxProperties)
+	}
+	full := func() bool { return maxProps >= 0 && len(obj) >= maxProps }
+
+	required := map[string]bool{}
+	if s.Required != nil {
+		for _, req := range *s.Required {
+			required[*req] = true
+		}
+	}
+
+	var patterns []*regexp.Regexp
+	if s.PatternProperties != nil {
+		for _, prop := range *s.PatternProperties {
+			re, err := regexp.Compile(prop.Name)
+			if err != nil {
+				return nil, err
+			}
+			patterns = append(patterns, re)
+		}
+	}
+
+	// propertySchema returns the schema the value of name must be valid against
+	propertySchema := func(name string) *Schema {
+		if s.Properties != nil {
+			if prop, ok := s.Properties.GetProperty(name); ok {
+				return prop.Property
+			}
+		}
+		for i, re := range patterns {
+			if re.MatchString(name) {
+				return (*s.PatternProperties)[i].Property
+			}
+		}
+		return s.AdditionalProperties
+	}
+
+	add := func(name string) error {
+		if _, ok := obj[name]; ok {
+			return nil
+		}
+		v, err := g.value(propertySchema(name), depth+1)
+		if err != nil {
+			return err
+		}
+		obj[name] = v
+		return nil
+	}
+
+	if s.Required != nil {
+		for _, req := range *s.Required {
+			if err := add(*req); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if s.Properties != nil && depth < maxGenerateDepth {
+		for _, prop := range *s.Properties {
+			if full() {
+				break
+			}
+			if !required[prop.Name] && g.rnd.Intn(2) == 0 {
+				if err := add(prop.Name); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+
+	if s.PatternProperties != nil && depth < maxGenerateDepth {
+		for _, prop := range *s.PatternProperties {
+			if full() || g.rnd.Intn(2) == 0 {
+				continue
+			}
+			name, err := g.str(&Schema{Pattern: stringPtr(prop.Name)})
+			if err != nil {
+				return nil, err
+			}
+			if err := add(name); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	additionalAllowed := s.AdditionalProperties == nil || s.AdditionalProperties.boolean == nil || *s.AdditionalProperties.boolean
+	extra := 0
+	if additionalAllowed && depth < maxGenerateDepth {
+		extra = g.rnd.Intn(3)
+	}
+	if s.MinProperties != nil && int(*s.MinProperties)-len(obj) > extra {
+		extra = int(*s.MinProperties) - len(obj)
+	}
+
+	for attempts := 0; extra > 0 && !full() && attempts < 20; attempts++ {
+		var name string
+		if s.PropertyNames != nil {
+			v, err := g.value(s.PropertyNames, maxGenerateDepth)
+			if err != nil {
+				return nil, err
+			}
+			str, ok := v.(string)
+			if !ok {
+				return nil, errors.New("propertyNames doesn't allow strings")
+			}
+			name = str
+		} else {
+			name = g.letters(1, 8)
+		}
+
+		if _, ok := obj[name]; ok {
+			continue
+		}
+		if s.Properties != nil {
+			if _, ok := s.Properties.GetProperty(name); ok {
+				continue
+			}
+		}
+		if !additionalAllowed && propertySchema(name) == s.AdditionalProperties {
+			continue
+		}
+		if err := add(name); err != nil {
+			return nil, err
+		}
+		extra--
+	}
+
+	if s.Dependencies != nil {
+		names := []string{}
+		for name := range *s.Dependencies {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			dep := (*s.Dependencies)[name]
+			if _, ok := obj[name]; !ok || dep.Strings == nil {
+				continue
+			}
+			for _, depName := range *dep.Strings {
+				if err := add(*depName); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+
+	return obj, nil
+}
+
+// mutation changes a single value in a generated document, to make it violate keyword
+type mutation struct {
+	keyword string
+	pointer string
+	apply   func()
+
+	// code and errorPointer are the error Validate must report for the changed document, which
+	// is at the pointer, unless the mutation adds a value, that is invalid
+	code         string
+	errorPointer string
+}
+
+// violates tells whether err, as returned by Validate, only reports the mutated keyword
+func (m *mutation) violates(err error) bool {
+	errs := Errors(err)
+	return len(errs) == 1 && errs[0].Code == m.code && errs[0].InstancePointer == m.errorPointer
+}
+
+// mutations returns the possible ways of making v invalid against s.
+// set replaces v in the document.
+func (g *generator) mutations(s *Schema, v interface{}, set func(interface{}), ptr string, depth int) []*mutation {
+	if s == nil || depth > maxGenerateDepth {
+		return nil
+	}
+	s, err := followRefs(s)
+	if err != nil || s.boolean != nil {
+		return nil
+	}
+
+	muts := []*mutation{}
+	mutate := func(keyword string, apply func()) {
+		muts = append(muts, &mutation{keyword: keyword, pointer: ptr, apply: apply, code: keyword, errorPointer: ptr})
+	}
+	// mutateChild is mutate for mutations, that add the child token to v, which is reported with code
+	mutateChild := func(keyword, code, token string, apply func()) {
+		muts = append(muts, &mutation{keyword: keyword, pointer: ptr, apply: apply, code: code, errorPointer: ptr + "/" + escapePointerToken(token)})
+	}
+
+	if s.AllOf != nil {
+		for _, sub := range *s.AllOf {
+			muts = append(muts, g.mutations(sub, v, set, ptr, depth+1)...)
+		}
+	}
+
+	if types := schemaTypes(s); len(types) > 0 {
+		allowed := map[string]bool{}
+		for _, typ := range types {
+			allowed[typ] = true
+		}
+		candidates := map[string]interface{}{
+			"null": nil, "boolean": true, "number": json.Number("1.5"), "string": "x",
+			"array": []interface{}{}, "object": map[string]interface{}{},
+		}
+		for _, typ := range []string{"null", "boolean", "number", "string", "array", "object"} {
+			if !allowed[typ] {
+				value := candidates[typ]
+				mutate("type", func() { set(value) })
+				break
+			}
+		}
+	}
+
+	if s.Const != nil {
+		value := mutatedValue(s.Const)
+		mutate("const", func() { set(value) })
+	}
+
+	if s.Enum != nil && len(*s.Enum) > 0 {
+		value := mutatedValue((*s.Enum)[0])
+		mutate("enum", func() { set(value) })
+	}
+
+	// Without knowing which branch the value is valid against, other values are tried,
+	// until one of them is invalid against every branch
+	for _, keyword := range []string{"anyOf", "oneOf"} {
+		if (keyword == "anyOf" && s.AnyOf == nil) || (keyword == "oneOf" && s.OneOf == nil) {
+			continue
+		}
+		for _, value := range []interface{}{nil, true, json.Number("1.5"), "", []interface{}{}, map[string]interface{}{}} {
+			value := value
+			mutate(keyword, func() { set(value) })
+		}
+	}
+
+	if s.Not != nil {
+		// A fixed seed keeps the list of mutations the same every time
+		notGen := &generator{rnd: rand.New(rand.NewSource(int64(depth)))}
+		if value, err := notGen.value(s.Not, maxGenerateDepth); err == nil {
+			mutate("not", func() { set(value) })
+		}
+	}
+
+	switch v := v.(type) {
+	case json.Number:
+		num, ok := new(big.Rat).SetString(string(v))
+		if !ok {
+			break
+		}
+		one := big.NewRat(1, 1)
+		if s.Minimum != nil && s.Minimum.Number != nil {
+			min, _ := s.Minimum.Number.Rat(nil)
+			if s.ExclusiveMinimum != nil && s.ExclusiveMinimum.Boolean != nil && *s.ExclusiveMinimum.Boolean {
+				mutate("exclusiveMinimum", func() { set(ratNumber(min)) })
+			} else {
+				mutate("minimum", func() { set(ratNumber(new(big.Rat).Sub(min, one))) })
+			}
+		}
+		if s.Maximum != nil && s.Maximum.Number != nil {
+			max, _ := s.Maximum.Number.Rat(nil)
+			if s.ExclusiveMaximum != nil && s.ExclusiveMaximum.Boolean != nil && *s.ExclusiveMaximum.Boolean {
+				mutate("exclusiveMaximum", func() { set(ratNumber(max)) })
+			} else {
+				mutate("maximum", func() { set(ratNumber(new(big.Rat).Add(max, one))) })
+			}
+		}
+		if s.ExclusiveMinimum != nil && s.ExclusiveMinimum.Number != nil {
+			min, _ := s.ExclusiveMinimum.Number.Rat(nil)
+			mutate("exclusiveMinimum", func() { set(ratNumber(min)) })
+		}
+		if s.ExclusiveMaximum != nil && s.ExclusiveMaximum.Number != nil {
+			max, _ := s.ExclusiveMaximum.Number.Rat(nil)
+			mutate("exclusiveMaximum", func() { set(ratNumber(max)) })
+		}
+		if s.MultipleOf != nil {
+			if step, ok := new(big.Rat).SetString(string(*s.MultipleOf)); ok {
+				half := new(big.Rat).Quo(step, big.NewRat(2, 1))
+				mutate("multipleOf", func() { set(ratNumber(new(big.Rat).Add(num, half))) })
+			}
+		}
+
+	case string:
+		length := len([]rune(v))
+		if s.MinLength != nil && *s.MinLength > 0 && int(*s.MinLength) <= length {
+			shorter := string([]rune(v)[:*s.MinLength-1])
+			mutate("minLength", func() { set(shorter) })
+		}
+		if s.MaxLength != nil {
+			longer := v + strings.Repeat("a", int(*s.MaxLength)+1-length)
+			mutate("maxLength", func() { set(longer) })
+		}
+		if s.Pattern != nil {
+			if re, err := regexp.Compile(*s.Pattern); err == nil {
+				// The candidates are fixed, so the list of mutations is the same every time
+				for _, str := range []string{"", " ", v + " ", "~" + v, "0"} {
+					str := str
+					if !re.MatchString(str) {
+						mutate("pattern", func() { set(str) })
+						break
+					}
+				}
+			}
+		}
+		if s.Format != nil {
+			if _, ok := formatGenerators[*s.Format]; ok {
+				mutate("format", func() { set("not a valid " + *s.Format + " %%") })
+			}
+		}
+
+	case []interface{}:
+		if s.MinItems != nil && *s.MinItems > 0 && int(*s.MinItems) <= len(v) {
+			shorter := v[:*s.MinItems-1]
+			mutate("minItems", func() { set(shorter) })
+		}
+		if s.MaxItems != nil && len(v) > 0 {
+			longer := append([]interface{}{}, v...)
+			for len(longer) <= int(*s.MaxItems) {
+				longer = append(longer, v[len(v)-1])
+			}
+			mutate("maxItems", func() { set(longer) })
+		}
+		if s.Items != nil && s.Items.Schemas != nil && len(v) >= len(*s.Items.Schemas) &&
+			s.AdditionalItems != nil && s.AdditionalItems.boolean != nil && !*s.AdditionalItems.boolean {
+			longer := append(append([]interface{}{}, v...), nil)
+			mutateChild("additionalItems", "false", strconv.Itoa(len(v)), func() { set(longer) })
+		}
+		if s.UniqueItems != nil && *s.UniqueItems && len(v) > 0 {
+			duplicated := append(append([]interface{}{}, v...), v[0])
+			mutateChild("uniqueItems", "uniqueItems", strconv.Itoa(len(v)), func() { set(duplicated) })
+		}
+
+		if s.Items != nil {
+			for i, item := range v {
+				var sub *Schema
+				switch {
+				case s.Items.Schema != nil:
+					sub = s.Items.Schema
+				case s.Items.Schemas != nil && i < len(*s.Items.Schemas):
+					sub = (*s.Items.Schemas)[i]
+				case s.Items.Schemas != nil:
+					sub = s.AdditionalItems
+				}
+				i := i
+				muts = append(muts, g.mutations(sub, item, func(val interface{}) { v[i] = val }, ptr+"/"+strconv.Itoa(i), depth+1)...)
+			}
+		}
+
+	case map[string]interface{}:
+		if s.Required != nil {
+			for _, req := range *s.Required {
+				name := *req
+				if _, ok := v[name]; ok {
+					mutate("required", func() { delete(v, name) })
+				}
+			}
+		}
+		if s.AdditionalProperties != nil && s.AdditionalProperties.boolean != nil && !*s.AdditionalProperties.boolean {
+			mutateChild("additionalProperties", "false", "unexpected property", func() { v["unexpected property"] = true })
+		}
+		if s.MinProperties != nil && *s.MinProperties > 0 && int(*s.MinProperties) <= len(v) {
+			names := sortedKeys(v)
+			mutate("minProperties", func() {
+				for _, name := range names[*s.MinProperties-1:] {
+					delete(v, name)
+				}
+			})
+		}
+		if s.MaxProperties != nil {
+			mutate("maxProperties", func() {
+				for i := 0; len(v) <= int(*s.MaxProperties); i++ {
+					v["extra"+strconv.Itoa(i)] = true
+				}
+			})
+		}
+
+		for _, name := range sortedKeys(v) {
+			var sub *Schema
+			if s.Properties != nil {
+				if prop, ok := s.Properties.GetProperty(name); ok {
+					sub = prop.Property
+				}
+			}
+			if sub == nil && s.PatternProperties != nil {
+				for _, prop := range *s.PatternProperties {
+					if re, err := regexp.Compile(prop.Name); err == nil && re.MatchString(name) {
+						sub = prop.Property
+						break
+					}
+				}
+			}
+			if sub == nil {
+				sub = s.AdditionalProperties
+			}
+			name := name
+			muts = append(muts, g.mutations(sub, v[name], func(val interface{}) { v[name] = val }, ptr+"/"+escapePointerToken(name), depth+1)...)
+		}
+	}
+
+	return muts
+}
+
+// generatedValue returns val as it was written in the schema, so e.g. large integers stays integers
+func generatedValue(val *Value) (json.RawMessage, error) {
+	if val.String == nil && len(val.Raw()) > 0 {
+		return json.RawMessage(val.Raw()), nil
+	}
+	raw, err := json.Marshal(val)
+	return json.RawMessage(raw), err
+}
+
+// mutatedValue returns a value of the same type as val, but with a different value
+func mutatedValue(val *Value) interface{} {
+	switch {
+	case val.String != nil:
+		return *val.String + " (changed)"
+	case val.Number != nil:
+		num, _ := val.Number.Rat(nil)
+		return ratNumber(num.Add(num, big.NewRat(1, 2)))
+	case val.Boolean != nil:
+		return !*val.Boolean
+	case val.Null != nil:
+		return false
+	default:
+		return "changed"
+	}
+}
+
+func ratNumber(r *big.Rat) json.Number {
+	if r.IsInt() {
+		return json.Number(r.Num().String())
+	}
+	return json.Number(strings.TrimRight(r.FloatString(20), "0"))
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,104 @@
+package jsonschema
+
+import (
+	"bytes"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+var generateTests = []string{
+	`{"type":"object","required":["id","name","tags"],"properties":{"id":{"type":"integer","minimum":1,"maximum":5},"name":{"type":"string","minLength":3,"maxLength":5},"tags":{"type":"array","items":{"enum":["a","b","c"]},"uniqueItems":true,"minItems":2},"price":{"type":"number","exclusiveMinimum":0,"multipleOf":0.25},"email":{"format":"email"},"code":{"type":"string","pattern":"^[A-Z]{3}-\\d{2,4}$"}},"additionalProperties":false}`,
+	`{"$schema":"http://json-schema.org/draft-04/schema#","definitions":{"node":{"type":"object","properties":{"value":{"type":"integer","minimum":0,"exclusiveMinimum":true},"next":{"$ref":"#/definitions/node"}},"required":["value"]}},"$ref":"#/definitions/node"}`,
+	`{"oneOf":[{"type":"string","maxLength":2},{"type":"integer","multipleOf":7}]}`,
+	`{"allOf":[{"properties":{"a":{"type":"string","format":"date-time"}},"required":["a"]},{"properties":{"b":{"const":3}},"required":["b"]}]}`,
+	`{"type":"array","items":[{"type":"boolean"},{"type":"null"}],"additionalItems":false,"minItems":2}`,
+	`{"type":"object","patternProperties":{"^x-":{"type":"integer"}},"additionalProperties":false,"minProperties":1}`,
+	`{"if":{"properties":{"kind":{"const":"a"}},"required":["kind"]},"then":{"required":["a"]},"else":{"not":{"required":["a"]}},"properties":{"a":{"format":"uuid"}}}`,
+}
+
+func TestGenerate(t *testing.T) {
+	for _, schema := range generateTests {
+		s, err := NewFromString(schema)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for seed := int64(0); seed < 20; seed++ {
+			doc, err := Generate(s, rand.NewSource(seed))
+			if err != nil {
+				t.Fatalf("unable to generate a document for %s: %s", schema, err)
+			}
+			if _, err := s.Validate(doc); err != nil {
+				t.Fatalf("expected generated document %s to be valid against %s, got: %s", doc, schema, err)
+			}
+
+			again, err := Generate(s, rand.NewSource(seed))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(doc, again) {
+				t.Fatalf("expected the same seed to generate the same document, got %s and %s", doc, again)
+			}
+		}
+	}
+}
+
+func TestGenerateInvalid(t *testing.T) {
+	for _, schema := range generateTests {
+		s, err := NewFromString(schema)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for seed := int64(0); seed < 20; seed++ {
+			doc, violation, err := GenerateInvalid(s, rand.NewSource(seed))
+			if err != nil {
+				t.Fatalf("unable to generate an invalid document for %s: %s", schema, err)
+			}
+			_, err = s.Validate(doc)
+			if err == nil {
+				t.Fatalf("expected generated document %s (%s) to be invalid against %s", doc, violation, schema)
+			}
+			if violation.Keyword == "" {
+				t.Fatalf("expected a violated keyword for %s", doc)
+			}
+
+			// Values added to violate a keyword are reported at the value, and false schemas have their own code
+			errs := Errors(err)
+			if len(errs) != 1 || (errs[0].Code != violation.Keyword && errs[0].Code != "false") ||
+				!strings.HasPrefix(errs[0].InstancePointer, violation.Pointer) {
+				t.Fatalf("expected %s to only violate %s, got: %v", doc, violation, err)
+			}
+		}
+	}
+}
+
+func TestGenerateInvalidSkipsOtherViolations(t *testing.T) {
+	// Below the minimum isn't a multiple of 5, and half a step more isn't an integer,
+	// so only changing the type leaves the other keywords valid
+	s, err := NewFromString(`{"type":"integer","minimum":5,"multipleOf":5}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for seed := int64(0); seed < 20; seed++ {
+		doc, violation, err := GenerateInvalid(s, rand.NewSource(seed))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if violation.Keyword != "type" {
+			t.Fatalf("expected only type to be violated, got: %s (%s)", doc, violation)
+		}
+	}
+}
+
+func TestGenerateUnsatisfiable(t *testing.T) {
+	s, err := NewFromString(`{"type":"integer","minimum":5,"maximum":4}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if doc, err := Generate(s, rand.NewSource(1)); err == nil {
+		t.Fatalf("expected an error, got: %s", doc)
+	}
+}
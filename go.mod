@@ -7,4 +7,5 @@ require (
 	github.com/davecgh/go-spew v1.1.1 // indirect
 	github.com/xeipuuv/gojsonschema v1.2.0
 	golang.org/x/net v0.0.0-20210805182204-aaa1db679c0d
+	gopkg.in/yaml.v3 v3.0.1
 )
@@ -63,6 +63,11 @@ func addError(err, errs error) error {
 		return err
 	}
 
+	// Validation errors are kept apart, so each keeps its pointer
+	if isValidationError(err) || isValidationError(errs) {
+		return append(Errors(errs), Errors(err)...)
+	}
+
 	return fmt.Errorf("%w\n%s", errs, err.Error())
 }
 
@@ -174,3 +179,7 @@ numberLoop:
 	// TODO: Ensure this is a valid assumption
 	return String
 }
+
+func stringPtr(str string) *string {
+	return &str
+}
@@ -0,0 +1,247 @@
+// Package httpvalidate validates the JSON bodies of HTTP requests and responses against schemas.
+//
+// Invalid bodies are rejected with an application/problem+json body, as described by RFC 7807:
+//
+//	mux := http.NewServeMux()
+//	mux.HandleFunc("/users", createUser)
+//
+//	handler := httpvalidate.Middleware([]httpvalidate.Route{
+//		{Method: http.MethodPost, Path: "/users", Request: userSchema, Response: userSchema},
+//	}, httpvalidate.Options{ValidateResponses: true})(mux)
+package httpvalidate
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"log"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/flowstack/go-jsonschema"
+)
+
+// DefaultMaxBodySize is the largest request body validated, unless Options.MaxBodySize is set
+const DefaultMaxBodySize = 1 << 20
+
+// Route holds the schemas for a method and path
+type Route struct {
+	// Method is the HTTP method, e.g. POST. An empty method matches every method.
+	Method string
+
+	// Path is matched against the path of the request. A * matches a single path segment,
+	// e.g. /users/*/orders, and a trailing / matches every path below it, like http.ServeMux.
+	Path string
+
+	// Request is the schema of the request body. A nil schema isn't validated.
+	Request *jsonschema.Schema
+
+	// Response is the schema of successful (2xx) response bodies. A nil schema isn't validated.
+	Response *jsonschema.Schema
+}
+
+// Options controls the middleware
+type Options struct {
+	// ValidateResponses makes the middleware buffer responses and validate them.
+	// Invalid responses are logged and replaced by a 500 Internal Server Error.
+	ValidateResponses bool
+
+	// MaxBodySize is the largest request body accepted. Defaults to DefaultMaxBodySize.
+	MaxBodySize int64
+
+	// ErrorLog logs invalid responses. Defaults to the standard logger.
+	ErrorLog *log.Logger
+}
+
+// Problem is an RFC 7807 problem details body
+type Problem struct {
+	Type   string         `json:"type"`
+	Title  string         `json:"title"`
+	Status int            `json:"status"`
+	Detail string         `json:"detail,omitempty"`
+	Errors []ProblemError `json:"errors,omitempty"`
+}
+
+// ProblemError is a single validation error in a Problem
+type ProblemError struct {
+	Detail string `json:"detail"`
+
+	// Pointer is the JSON Pointer to the invalid value in the body
+	Pointer string `json:"pointer"`
+
+	// Code identifies the kind of error, e.g. required, so clients can show their own message
+	Code string `json:"code,omitempty"`
+}
+
+// Middleware returns a middleware, that validates request bodies against the schema of the
+// first matching route, before the next handler runs.
+// Requests not matching any route are passed on untouched.
+func Middleware(routes []Route, opts Options) func(http.Handler) http.Handler {
+	if opts.MaxBodySize <= 0 {
+		opts.MaxBodySize = DefaultMaxBodySize
+	}
+	if opts.ErrorLog == nil {
+		opts.ErrorLog = log.New(log.Writer(), log.Prefix(), log.Flags())
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			route := findRoute(routes, r)
+			if route == nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if route.Request != nil {
+				body, ok := validateRequest(w, r, route.Request, opts.MaxBodySize)
+				if !ok {
+					return
+				}
+				r.Body = ioutil.NopCloser(bytes.NewReader(body))
+			}
+
+			if route.Response == nil || !opts.ValidateResponses {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			rec := &responseRecorder{header: http.Header{}, status: http.StatusOK}
+			next.ServeHTTP(rec, r)
+			rec.flush(w, route.Response, opts.ErrorLog)
+		})
+	}
+}
+
+// validateRequest reads and validates the body. If the body is invalid a problem is written,
+// and ok is false.
+func validateRequest(w http.ResponseWriter, r *http.Request, schema *jsonschema.Schema, maxSize int64) (body []byte, ok bool) {
+	if !isJSON(r.Header.Get("Content-Type")) {
+		WriteProblem(w, http.StatusUnsupportedMediaType, "the request body must be JSON", nil)
+		return nil, false
+	}
+
+	body, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, maxSize))
+	if err != nil {
+		WriteProblem(w, http.StatusRequestEntityTooLarge, err.Error(), nil)
+		return nil, false
+	}
+
+	if _, err := schema.Validate(body); err != nil {
+		WriteProblem(w, http.StatusBadRequest, "the request body is not valid", err)
+		return nil, false
+	}
+
+	return body, true
+}
+
+// WriteProblem writes an application/problem+json response with the validation errors in err
+func WriteProblem(w http.ResponseWriter, status int, detail string, err error) {
+	problem := Problem{
+		Type:   "about:blank",
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: detail,
+	}
+	for _, e := range jsonschema.Errors(err) {
+		problem.Errors = append(problem.Errors, ProblemError{Detail: e.Message, Pointer: e.InstancePointer, Code: e.Code})
+	}
+
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(status)
+
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	_ = enc.Encode(problem)
+}
+
+func findRoute(routes []Route, r *http.Request) *Route {
+	for i := range routes {
+		route := &routes[i]
+		if route.Method != "" && route.Method != r.Method {
+			continue
+		}
+		if matchPath(route.Path, r.URL.Path) {
+			return route
+		}
+	}
+	return nil
+}
+
+func matchPath(pattern, path string) bool {
+	prefix := strings.HasSuffix(pattern, "/") && pattern != "/"
+
+	patternParts := strings.Split(strings.Trim(pattern, "/"), "/")
+	pathParts := strings.Split(strings.Trim(path, "/"), "/")
+
+	if len(pathParts) < len(patternParts) || (!prefix && len(pathParts) != len(patternParts)) {
+		return false
+	}
+
+	for i, part := range patternParts {
+		if part != "*" && part != pathParts[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// isJSON reports whether the media type is JSON, i.e. application/json or any +json type.
+// A missing content type is treated as JSON.
+func isJSON(contentType string) bool {
+	if contentType == "" {
+		return true
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
+
+// responseRecorder buffers a response, so it can be validated before it's sent
+type responseRecorder struct {
+	header      http.Header
+	status      int
+	wroteHeader bool
+	body        bytes.Buffer
+}
+
+func (rec *responseRecorder) Header() http.Header {
+	return rec.header
+}
+
+func (rec *responseRecorder) WriteHeader(status int) {
+	if rec.wroteHeader {
+		return
+	}
+	rec.status = status
+	rec.wroteHeader = true
+}
+
+func (rec *responseRecorder) Write(b []byte) (int, error) {
+	rec.WriteHeader(http.StatusOK)
+	return rec.body.Write(b)
+}
+
+// flush validates successful JSON responses and sends the response on to w
+func (rec *responseRecorder) flush(w http.ResponseWriter, schema *jsonschema.Schema, errorLog *log.Logger) {
+	if rec.status >= 200 && rec.status < 300 && rec.status != http.StatusNoContent &&
+		isJSON(rec.header.Get("Content-Type")) {
+		if _, err := schema.Validate(rec.body.Bytes()); err != nil {
+			// The errors are only logged, as they're about the server and not the client
+			errorLog.Printf("httpvalidate: invalid response body: %s", err)
+			WriteProblem(w, http.StatusInternalServerError, "the response body is not valid", nil)
+			return
+		}
+	}
+
+	for key, values := range rec.header {
+		w.Header()[key] = values
+	}
+	w.WriteHeader(rec.status)
+	if _, err := w.Write(rec.body.Bytes()); err != nil {
+		errorLog.Printf("httpvalidate: %s", err)
+	}
+}
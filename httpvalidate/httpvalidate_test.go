@@ -0,0 +1,105 @@
+package httpvalidate
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/flowstack/go-jsonschema"
+)
+
+func newHandler(t *testing.T, response string) http.Handler {
+	userSchema, err := jsonschema.NewFromString(`{"type":"object","required":["name"],"properties":{"name":{"type":"string"}}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	routes := []Route{
+		{Method: http.MethodPost, Path: "/users", Request: userSchema, Response: userSchema},
+		{Method: http.MethodPut, Path: "/users/*", Request: userSchema},
+	}
+	opts := Options{ValidateResponses: true, ErrorLog: log.New(ioutil.Discard, "", 0)}
+
+	return Middleware(routes, opts)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		if response == "" {
+			response = string(body)
+		}
+		_, _ = w.Write([]byte(response))
+	}))
+}
+
+var middlewareTests = []struct {
+	method      string
+	path        string
+	contentType string
+	body        string
+	response    string
+	status      int
+	errors      int
+	pointer     string
+}{
+	{method: http.MethodPost, path: "/users", body: `{"name":"Jane"}`, status: http.StatusCreated},
+	{method: http.MethodPost, path: "/users", body: `{"name":1}`, status: http.StatusBadRequest, errors: 1, pointer: "/name"},
+	{method: http.MethodPost, path: "/users", body: `{}`, status: http.StatusBadRequest, errors: 1},
+	{method: http.MethodPost, path: "/users", contentType: "text/plain", body: `{"name":"Jane"}`, status: http.StatusUnsupportedMediaType},
+	{method: http.MethodPost, path: "/users", contentType: "application/merge-patch+json", body: `{"name":"Jane"}`, status: http.StatusCreated},
+	{method: http.MethodPost, path: "/users", body: `{"name":"Jane"}`, response: `{"id":1}`, status: http.StatusInternalServerError},
+	{method: http.MethodPut, path: "/users/1", body: `[]`, status: http.StatusBadRequest, errors: 1},
+	{method: http.MethodPut, path: "/users/1/orders", body: `[]`, status: http.StatusCreated},
+	{method: http.MethodGet, path: "/users", body: `[]`, status: http.StatusCreated},
+}
+
+func TestMiddleware(t *testing.T) {
+	for i, tt := range middlewareTests {
+		req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+		if tt.contentType != "" {
+			req.Header.Set("Content-Type", tt.contentType)
+		}
+		rec := httptest.NewRecorder()
+		newHandler(t, tt.response).ServeHTTP(rec, req)
+
+		if rec.Code != tt.status {
+			t.Fatalf("test #%d: expected status %d, got: %d %s", i+1, tt.status, rec.Code, rec.Body.String())
+		}
+		if rec.Code < 400 {
+			continue
+		}
+
+		if ct := rec.Header().Get("Content-Type"); ct != "application/problem+json" {
+			t.Fatalf("test #%d: expected a problem, got: %s", i+1, ct)
+		}
+		problem := Problem{}
+		if err := json.Unmarshal(rec.Body.Bytes(), &problem); err != nil {
+			t.Fatalf("test #%d: %s", i+1, err)
+		}
+		if problem.Status != tt.status || problem.Title != http.StatusText(tt.status) {
+			t.Fatalf("test #%d: unexpected problem: %+v", i+1, problem)
+		}
+		if len(problem.Errors) != tt.errors {
+			t.Fatalf("test #%d: expected %d errors, got: %+v", i+1, tt.errors, problem.Errors)
+		}
+		if tt.pointer != "" && problem.Errors[0].Pointer != tt.pointer {
+			t.Fatalf("test #%d: expected the error at %s, got: %+v", i+1, tt.pointer, problem.Errors)
+		}
+	}
+}
+
+func TestMiddlewareBodyIsPassedOn(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/users", strings.NewReader(`{"name":"Jane"}`))
+	rec := httptest.NewRecorder()
+	newHandler(t, "").ServeHTTP(rec, req)
+
+	if rec.Body.String() != `{"name":"Jane"}` {
+		t.Fatalf("expected the handler to get the request body, got: %s", rec.Body.String())
+	}
+}
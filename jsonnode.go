@@ -0,0 +1,242 @@
+package jsonschema
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/buger/jsonparser"
+)
+
+// node is an ordered representation of a JSON value.
+// It is used when a document has to be rewritten, without losing the original
+// order of keys or the original formatting of numbers.
+type node struct {
+	typ jsonparser.ValueType
+
+	// raw holds the value of scalars, as found in the source.
+	// Strings are kept escaped and without the surrounding quotes.
+	raw []byte
+
+	members []*member // Only used for objects
+	items   []*node   // Only used for arrays
+}
+
+type member struct {
+	key   string
+	value *node
+}
+
+func parseNode(data []byte) (*node, error) {
+	value, vt, _, err := jsonparser.Get(data)
+	if err != nil {
+		return nil, err
+	}
+	return newNode(value, vt)
+}
+
+func newNode(value []byte, vt jsonparser.ValueType) (*node, error) {
+	n := &node{typ: vt}
+
+	switch vt {
+	case jsonparser.Object:
+		n.members = []*member{}
+		err := jsonparser.ObjectEach(value, func(key []byte, value []byte, dataType jsonparser.ValueType, offset int) error {
+			unescaped, err := jsonparser.Unescape(key, nil)
+			if err != nil {
+				return err
+			}
+			child, err := newNode(value, dataType)
+			if err != nil {
+				return err
+			}
+			n.members = append(n.members, &member{key: string(unescaped), value: child})
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+
+	case jsonparser.Array:
+		n.items = []*node{}
+		var errs error
+		_, err := jsonparser.ArrayEach(value, func(value []byte, dataType jsonparser.ValueType, offset int, parseErr error) {
+			if parseErr != nil {
+				errs = addError(parseErr, errs)
+				return
+			}
+			child, err := newNode(value, dataType)
+			if err != nil {
+				errs = addError(err, errs)
+				return
+			}
+			n.items = append(n.items, child)
+		})
+		if err != nil {
+			return nil, err
+		}
+		if errs != nil {
+			return nil, errs
+		}
+
+	case jsonparser.String, jsonparser.Number, jsonparser.Boolean, jsonparser.Null:
+		n.raw = value
+
+	default:
+		return nil, fmt.Errorf("unexpexted type: %s", vt.String())
+	}
+
+	return n, nil
+}
+
+// newNodeFrom converts any value encoding/json can marshal into a node.
+func newNodeFrom(v interface{}) (*node, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return parseNode(b)
+}
+
+func newStringNode(str string) *node {
+	return &node{typ: jsonparser.String, raw: escapeString(str)}
+}
+
+func newBoolNode(b bool) *node {
+	if b {
+		return &node{typ: jsonparser.Boolean, raw: trueLiteral}
+	}
+	return &node{typ: jsonparser.Boolean, raw: falseLiteral}
+}
+
+func newObjectNode() *node {
+	return &node{typ: jsonparser.Object, members: []*member{}}
+}
+
+// escapeString returns str as an escaped JSON string, without the surrounding quotes.
+func escapeString(str string) []byte {
+	buf := &bytes.Buffer{}
+	enc := json.NewEncoder(buf)
+	enc.SetEscapeHTML(false)
+	enc.Encode(str)
+	b := bytes.TrimRight(buf.Bytes(), "\n")
+	return b[1 : len(b)-1]
+}
+
+// str returns the unescaped value of a string node.
+func (n *node) str() (string, bool) {
+	if n == nil || n.typ != jsonparser.String {
+		return "", false
+	}
+	unescaped, err := jsonparser.Unescape(n.raw, nil)
+	if err != nil {
+		return "", false
+	}
+	return string(unescaped), true
+}
+
+func (n *node) boolean() (bool, bool) {
+	if n == nil || n.typ != jsonparser.Boolean {
+		return false, false
+	}
+	return bytes.Equal(n.raw, trueLiteral), true
+}
+
+func (n *node) get(key string) *node {
+	if n == nil {
+		return nil
+	}
+	for _, m := range n.members {
+		if m.key == key {
+			return m.value
+		}
+	}
+	return nil
+}
+
+func (n *node) has(key string) bool {
+	return n.get(key) != nil
+}
+
+// set replaces the value of key, or appends key if it doesn't exist
+func (n *node) set(key string, value *node) {
+	for _, m := range n.members {
+		if m.key == key {
+			m.value = value
+			return
+		}
+	}
+	n.members = append(n.members, &member{key: key, value: value})
+}
+
+// rename changes the name of a key, while keeping its position.
+// If newKey already exists, it is removed first.
+func (n *node) rename(key, newKey string) {
+	if key == newKey || !n.has(key) {
+		return
+	}
+	n.del(newKey)
+	for _, m := range n.members {
+		if m.key == key {
+			m.key = newKey
+			return
+		}
+	}
+}
+
+func (n *node) del(key string) {
+	for i, m := range n.members {
+		if m.key == key {
+			n.members = append(n.members[:i], n.members[i+1:]...)
+			return
+		}
+	}
+}
+
+func (n *node) MarshalJSON() ([]byte, error) {
+	buf := &bytes.Buffer{}
+	n.write(buf)
+	return buf.Bytes(), nil
+}
+
+func (n *node) write(buf *bytes.Buffer) {
+	switch n.typ {
+	case jsonparser.Object:
+		buf.WriteByte('{')
+		for i, m := range n.members {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			buf.WriteByte('"')
+			buf.Write(escapeString(m.key))
+			buf.WriteString(`":`)
+			m.value.write(buf)
+		}
+		buf.WriteByte('}')
+
+	case jsonparser.Array:
+		buf.WriteByte('[')
+		for i, item := range n.items {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			item.write(buf)
+		}
+		buf.WriteByte(']')
+
+	case jsonparser.String:
+		buf.WriteByte('"')
+		buf.Write(n.raw)
+		buf.WriteByte('"')
+
+	default:
+		buf.Write(n.raw)
+	}
+}
+
+// escapePointerToken escapes a single JSON Pointer reference token
+func escapePointerToken(token string) string {
+	token = strings.ReplaceAll(token, "~", "~0")
+	return strings.ReplaceAll(token, "/", "~1")
+}
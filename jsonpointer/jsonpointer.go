@@ -0,0 +1,308 @@
+// Package jsonpointer parses, formats and resolves JSON Pointers (RFC 6901) and
+// Relative JSON Pointers (draft-handrews-relative-json-pointer).
+//
+// Pointers can be resolved against raw JSON, against Go values and against any tree,
+// that implements Resolver, e.g. a parsed *jsonschema.Schema:
+//
+//	ptr, err := jsonpointer.Parse("/properties/name")
+//	name, err := ptr.GetJSON(doc)
+package jsonpointer
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/buger/jsonparser"
+)
+
+// Pointer is a parsed JSON Pointer, i.e. its unescaped reference tokens. The root is an empty Pointer.
+type Pointer []string
+
+// Resolver is implemented by trees, that know how to resolve a reference token themselves
+type Resolver interface {
+	// ResolvePointerToken returns the value referenced by token, or an error if there is none
+	ResolvePointerToken(token string) (interface{}, error)
+}
+
+// ErrNotFound is wrapped by the errors of pointers, that don't reference a value
+var ErrNotFound = errors.New("value not found")
+
+// Parse parses a JSON Pointer in its string representation, e.g. /definitions/a~1b
+func Parse(str string) (Pointer, error) {
+	if str == "" {
+		return Pointer{}, nil
+	}
+	if str[0] != '/' {
+		return nil, fmt.Errorf("JSON Pointer must be empty or start with /: %s", str)
+	}
+
+	tokens := strings.Split(str[1:], "/")
+	for i, token := range tokens {
+		unescaped, err := Unescape(token)
+		if err != nil {
+			return nil, err
+		}
+		tokens[i] = unescaped
+	}
+	return Pointer(tokens), nil
+}
+
+// ParseFragment parses a JSON Pointer in its URI fragment representation, e.g. #/definitions/a%20b
+func ParseFragment(fragment string) (Pointer, error) {
+	unescaped, err := url.PathUnescape(strings.TrimPrefix(fragment, "#"))
+	if err != nil {
+		return nil, err
+	}
+	return Parse(unescaped)
+}
+
+// New returns a Pointer of unescaped reference tokens
+func New(tokens ...string) Pointer {
+	return append(Pointer{}, tokens...)
+}
+
+// Escape escapes ~ and / in a reference token
+func Escape(token string) string {
+	if !strings.ContainsAny(token, "~/") {
+		return token
+	}
+	return strings.ReplaceAll(strings.ReplaceAll(token, "~", "~0"), "/", "~1")
+}
+
+// Unescape unescapes ~0 and ~1 in a reference token, and rejects any other use of ~
+func Unescape(token string) (string, error) {
+	if !strings.Contains(token, "~") {
+		return token, nil
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(token); i++ {
+		if token[i] != '~' {
+			b.WriteByte(token[i])
+			continue
+		}
+		if i+1 < len(token) && token[i+1] == '0' {
+			b.WriteByte('~')
+		} else if i+1 < len(token) && token[i+1] == '1' {
+			b.WriteByte('/')
+		} else {
+			return "", fmt.Errorf("invalid escape in JSON Pointer token: %s", token)
+		}
+		i++
+	}
+	return b.String(), nil
+}
+
+// String returns the string representation of the pointer
+func (p Pointer) String() string {
+	var b strings.Builder
+	for _, token := range p {
+		b.WriteByte('/')
+		b.WriteString(Escape(token))
+	}
+	return b.String()
+}
+
+// Fragment returns the URI fragment representation of the pointer, including the #
+func (p Pointer) Fragment() string {
+	u := url.URL{Fragment: p.String()}
+	return "#" + u.EscapedFragment()
+}
+
+// Append returns a new pointer with the tokens added
+func (p Pointer) Append(tokens ...string) Pointer {
+	return append(append(Pointer{}, p...), tokens...)
+}
+
+// Parent returns the pointer to the value containing the value p points to.
+// The parent of the root is the root.
+func (p Pointer) Parent() Pointer {
+	if len(p) == 0 {
+		return p
+	}
+	return p[: len(p)-1 : len(p)-1]
+}
+
+// IsRoot tells whether the pointer references the whole document
+func (p Pointer) IsRoot() bool {
+	return len(p) == 0
+}
+
+// arrayIndex parses a reference token into an index of an array of length n
+func arrayIndex(token string, n int) (int, error) {
+	if token == "-" {
+		return 0, fmt.Errorf("%w: - references the element after the last", ErrNotFound)
+	}
+	// Leading zeros aren't allowed
+	if token == "" || (len(token) > 1 && token[0] == '0') || strings.TrimLeft(token, "0123456789") != "" {
+		return 0, fmt.Errorf("invalid array index: %s", token)
+	}
+	idx, err := strconv.Atoi(token)
+	if err != nil || idx >= n {
+		return 0, fmt.Errorf("%w: index %s is out of range", ErrNotFound, token)
+	}
+	return idx, nil
+}
+
+// GetJSON returns the raw JSON of the value the pointer references in doc
+func (p Pointer) GetJSON(doc []byte) ([]byte, error) {
+	value, dataType, _, err := jsonparser.Get(doc)
+	if err != nil {
+		return nil, err
+	}
+	value = rawValue(value, dataType)
+
+	for i, token := range p {
+		found := false
+		switch dataType {
+		case jsonparser.Object:
+			err = jsonparser.ObjectEach(value, func(key, member []byte, memberType jsonparser.ValueType, offset int) error {
+				// Keys are unescaped by jsonparser, and the first matching key wins
+				if !found && string(key) == token {
+					found = true
+					value, dataType = rawValue(member, memberType), memberType
+				}
+				return nil
+			})
+
+		case jsonparser.Array:
+			n := 0
+			_, err = jsonparser.ArrayEach(value, func([]byte, jsonparser.ValueType, int, error) { n++ })
+			if err != nil {
+				break
+			}
+			var idx int
+			if idx, err = arrayIndex(token, n); err != nil {
+				break
+			}
+			var item []byte
+			var itemType jsonparser.ValueType
+			item, itemType, _, err = jsonparser.Get(value, "["+strconv.Itoa(idx)+"]")
+			value, dataType, found = rawValue(item, itemType), itemType, err == nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if !found {
+			return nil, fmt.Errorf("%w: %s", ErrNotFound, p[:i+1])
+		}
+	}
+
+	return value, nil
+}
+
+// rawValue adds the quotes jsonparser removes from strings
+func rawValue(value []byte, dataType jsonparser.ValueType) []byte {
+	if dataType != jsonparser.String {
+		return value
+	}
+	raw := make([]byte, 0, len(value)+2)
+	raw = append(raw, '"')
+	raw = append(raw, value...)
+	return append(raw, '"')
+}
+
+// Get returns the value the pointer references in v, which may be a Resolver, raw JSON
+// (json.RawMessage), or any Go value, that encoding/json can marshal, e.g. maps, slices and structs.
+// Struct fields are found by their JSON names.
+func (p Pointer) Get(v interface{}) (interface{}, error) {
+	for i, token := range p {
+		next, err := getToken(v, token)
+		if err != nil {
+			return nil, err
+		}
+		if raw, ok := next.(json.RawMessage); ok {
+			// The rest of the pointer is resolved in the raw JSON
+			value, err := p[i+1:].GetJSON(raw)
+			if err != nil {
+				return nil, err
+			}
+			return json.RawMessage(value), nil
+		}
+		v = next
+	}
+	return v, nil
+}
+
+func getToken(v interface{}, token string) (interface{}, error) {
+	if resolver, ok := v.(Resolver); ok {
+		return resolver.ResolvePointerToken(token)
+	}
+	if raw, ok := v.(json.RawMessage); ok {
+		value, err := Pointer{token}.GetJSON(raw)
+		if err != nil {
+			return nil, err
+		}
+		return json.RawMessage(value), nil
+	}
+
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr || rv.Kind() == reflect.Interface {
+		if rv.IsNil() {
+			return nil, fmt.Errorf("%w: %s in nil", ErrNotFound, token)
+		}
+		rv = rv.Elem()
+		// A Resolver may be behind an interface
+		if rv.CanInterface() {
+			if resolver, ok := rv.Interface().(Resolver); ok {
+				return resolver.ResolvePointerToken(token)
+			}
+		}
+	}
+
+	switch rv.Kind() {
+	case reflect.Map:
+		if rv.Type().Key().Kind() != reflect.String {
+			return nil, fmt.Errorf("unable to resolve %s in a map with %s keys", token, rv.Type().Key())
+		}
+		value := rv.MapIndex(reflect.ValueOf(token).Convert(rv.Type().Key()))
+		if !value.IsValid() {
+			return nil, fmt.Errorf("%w: %s", ErrNotFound, token)
+		}
+		return value.Interface(), nil
+
+	case reflect.Slice, reflect.Array:
+		idx, err := arrayIndex(token, rv.Len())
+		if err != nil {
+			return nil, err
+		}
+		return rv.Index(idx).Interface(), nil
+
+	case reflect.Struct:
+		if field, ok := structField(rv, token); ok {
+			return field.Interface(), nil
+		}
+		return nil, fmt.Errorf("%w: %s in %s", ErrNotFound, token, rv.Type())
+
+	default:
+		return nil, fmt.Errorf("%w: %s in a %s", ErrNotFound, token, rv.Kind())
+	}
+}
+
+// structField finds the exported field with the JSON name
+func structField(rv reflect.Value, name string) (reflect.Value, bool) {
+	t := rv.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		fieldName := strings.Split(tag, ",")[0]
+		if fieldName == "" {
+			fieldName = field.Name
+		}
+		if fieldName == name {
+			return rv.Field(i), true
+		}
+	}
+	return reflect.Value{}, false
+}
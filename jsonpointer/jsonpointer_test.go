@@ -0,0 +1,222 @@
+package jsonpointer
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// Examples from RFC 6901 section 5
+var rfcDoc = []byte(`{
+	"foo": ["bar", "baz"],
+	"": 0,
+	"a/b": 1,
+	"c%d": 2,
+	"e^f": 3,
+	"g|h": 4,
+	"i\\j": 5,
+	"k\"l": 6,
+	" ": 7,
+	"m~n": 8
+}`)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		str      string
+		expected Pointer
+		invalid  bool
+	}{
+		{str: "", expected: Pointer{}},
+		{str: "/", expected: Pointer{""}},
+		{str: "/foo/0", expected: Pointer{"foo", "0"}},
+		{str: "/a~1b/m~0n", expected: Pointer{"a/b", "m~n"}},
+		{str: "/~01", expected: Pointer{"~1"}},
+		{str: "foo", invalid: true},
+		{str: "/a~2b", invalid: true},
+		{str: "/a~", invalid: true},
+	}
+
+	for _, test := range tests {
+		ptr, err := Parse(test.str)
+		if test.invalid {
+			if err == nil {
+				t.Errorf("expected %q to be invalid", test.str)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("unable to parse %q: %s", test.str, err)
+			continue
+		}
+		if !reflect.DeepEqual(ptr, test.expected) {
+			t.Errorf("expected %q to parse to %#v, got: %#v", test.str, test.expected, ptr)
+		}
+		if ptr.String() != test.str {
+			t.Errorf("expected %q to format to itself, got: %q", test.str, ptr.String())
+		}
+	}
+}
+
+func TestFragment(t *testing.T) {
+	ptr, err := ParseFragment("#/c%25d/a~1b/%20")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(ptr, Pointer{"c%d", "a/b", " "}) {
+		t.Fatalf("unexpected pointer: %#v", ptr)
+	}
+	if fragment := ptr.Fragment(); fragment != "#/c%25d/a~1b/%20" {
+		t.Fatalf("unexpected fragment: %s", fragment)
+	}
+	if fragment := New().Fragment(); fragment != "#" {
+		t.Fatalf("unexpected fragment of the root: %s", fragment)
+	}
+}
+
+func TestGetJSON(t *testing.T) {
+	tests := map[string]string{
+		"":       string(rfcDoc),
+		"/foo":   `["bar", "baz"]`,
+		"/foo/0": `"bar"`,
+		"/":      `0`,
+		"/a~1b":  `1`,
+		"/c%d":   `2`,
+		"/e^f":   `3`,
+		"/g|h":   `4`,
+		"/i\\j":  `5`,
+		"/k\"l":  `6`,
+		"/ ":     `7`,
+		"/m~0n":  `8`,
+	}
+
+	for str, expected := range tests {
+		ptr, err := Parse(str)
+		if err != nil {
+			t.Fatal(err)
+		}
+		value, err := ptr.GetJSON(rfcDoc)
+		if err != nil {
+			t.Errorf("unable to get %q: %s", str, err)
+			continue
+		}
+		if string(value) != expected {
+			t.Errorf("expected %q to be %s, got: %s", str, expected, value)
+		}
+	}
+
+	for _, str := range []string{"/missing", "/foo/2", "/foo/-", "/foo/0/x"} {
+		ptr, _ := Parse(str)
+		if _, err := ptr.GetJSON(rfcDoc); !errors.Is(err, ErrNotFound) {
+			t.Errorf("expected %q not to be found, got: %v", str, err)
+		}
+	}
+
+	// Leading zeros aren't array indexes
+	if _, err := New("foo", "01").GetJSON(rfcDoc); err == nil || errors.Is(err, ErrNotFound) {
+		t.Errorf("expected 01 to be an invalid index, got: %v", err)
+	}
+}
+
+type resolverTree map[string]int
+
+func (r resolverTree) ResolvePointerToken(token string) (interface{}, error) {
+	if v, ok := r[token]; ok {
+		return v * 10, nil
+	}
+	return nil, ErrNotFound
+}
+
+func TestGet(t *testing.T) {
+	type item struct {
+		Name   string `json:"name"`
+		Hidden string `json:"-"`
+		Size   int
+	}
+	doc := map[string]interface{}{
+		"items":    []item{{Name: "a", Size: 1}, {Name: "b", Size: 2}},
+		"raw":      json.RawMessage(`{"x": [true, {"y": "z"}]}`),
+		"resolver": resolverTree{"a": 1},
+		"pointer":  &item{Name: "p"},
+	}
+
+	tests := []struct {
+		ptr      Pointer
+		expected interface{}
+	}{
+		{ptr: New("items", "1", "name"), expected: "b"},
+		{ptr: New("items", "0", "Size"), expected: 1},
+		{ptr: New("raw", "x", "1"), expected: json.RawMessage(`{"y": "z"}`)},
+		{ptr: New("raw", "x", "1", "y"), expected: json.RawMessage(`"z"`)},
+		{ptr: New("resolver", "a"), expected: 10},
+		{ptr: New("pointer", "name"), expected: "p"},
+	}
+	for _, test := range tests {
+		value, err := test.ptr.Get(doc)
+		if err != nil {
+			t.Errorf("unable to get %s: %s", test.ptr, err)
+			continue
+		}
+		if !reflect.DeepEqual(value, test.expected) {
+			t.Errorf("expected %s to be %#v, got: %#v", test.ptr, test.expected, value)
+		}
+	}
+
+	for _, ptr := range []Pointer{New("missing"), New("items", "2"), New("items", "0", "Hidden"), New("resolver", "b")} {
+		if _, err := ptr.Get(doc); !errors.Is(err, ErrNotFound) {
+			t.Errorf("expected %s not to be found, got: %v", ptr, err)
+		}
+	}
+}
+
+func TestRelativePointer(t *testing.T) {
+	// Examples from the Relative JSON Pointer draft
+	doc := []byte(`{"foo": ["bar", "baz"], "highly": {"nested": {"objects": true}}}`)
+	current := New("foo", "1")
+
+	tests := map[string]string{
+		"0":                       `"baz"`,
+		"1/0":                     `"bar"`,
+		"2/highly/nested/objects": `true`,
+		"0#":                      `1`,
+		"1#":                      `"foo"`,
+	}
+	for str, expected := range tests {
+		rel, err := ParseRelative(str)
+		if err != nil {
+			t.Fatalf("unable to parse %q: %s", str, err)
+		}
+		if rel.String() != str {
+			t.Errorf("expected %q to format to itself, got: %q", str, rel.String())
+		}
+		value, err := rel.GetJSON(doc, current)
+		if err != nil {
+			t.Errorf("unable to get %q: %s", str, err)
+			continue
+		}
+		if string(value) != expected {
+			t.Errorf("expected %q to be %s, got: %s", str, expected, value)
+		}
+	}
+
+	var v interface{}
+	if err := json.Unmarshal(doc, &v); err != nil {
+		t.Fatal(err)
+	}
+	rel, _ := ParseRelative("0#")
+	if idx, err := rel.Get(v, current); err != nil || idx != 1 {
+		t.Errorf("expected the index 1, got: %v (%v)", idx, err)
+	}
+
+	for _, str := range []string{"", "-1", "01", "1foo", "0#/a", "1/a~"} {
+		if _, err := ParseRelative(str); err == nil {
+			t.Errorf("expected %q to be invalid", str)
+		}
+	}
+	for _, str := range []string{"3", "2#"} {
+		rel, _ := ParseRelative(str)
+		if _, err := rel.GetJSON(doc, current); err == nil {
+			t.Errorf("expected %q to fail from %s", str, current)
+		}
+	}
+}
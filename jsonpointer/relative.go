@@ -0,0 +1,141 @@
+package jsonpointer
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// RelativePointer is a parsed Relative JSON Pointer, e.g. 1/name or 0#
+type RelativePointer struct {
+	// Up is the number of levels to go up from the current value
+	Up int
+
+	// Name is set for pointers ending with #, which reference the key or index of the value
+	// Up levels up, rather than the value itself
+	Name bool
+
+	// Pointer is resolved from the value Up levels up, when Name isn't set
+	Pointer Pointer
+}
+
+// ParseRelative parses a Relative JSON Pointer
+func ParseRelative(str string) (RelativePointer, error) {
+	end := 0
+	for end < len(str) && str[end] >= '0' && str[end] <= '9' {
+		end++
+	}
+	if end == 0 || (end > 1 && str[0] == '0') {
+		return RelativePointer{}, fmt.Errorf("relative JSON Pointer must start with a non-negative integer: %s", str)
+	}
+
+	up, err := strconv.Atoi(str[:end])
+	if err != nil {
+		return RelativePointer{}, fmt.Errorf("invalid relative JSON Pointer: %s", str)
+	}
+
+	rest := str[end:]
+	if rest == "#" {
+		return RelativePointer{Up: up, Name: true}, nil
+	}
+
+	ptr, err := Parse(rest)
+	if err != nil {
+		return RelativePointer{}, err
+	}
+	return RelativePointer{Up: up, Pointer: ptr}, nil
+}
+
+// String returns the string representation of the relative pointer
+func (r RelativePointer) String() string {
+	if r.Name {
+		return strconv.Itoa(r.Up) + "#"
+	}
+	return strconv.Itoa(r.Up) + r.Pointer.String()
+}
+
+// Resolve returns the absolute pointer, that r references from the value current points to.
+// For pointers ending with #, that is the pointer to the value, whose key or index is referenced.
+func (r RelativePointer) Resolve(current Pointer) (Pointer, error) {
+	if r.Up > len(current) {
+		return nil, fmt.Errorf("%w: %s goes above the root of %s", ErrNotFound, r, current)
+	}
+	base := current[:len(current)-r.Up]
+	if r.Name {
+		if len(base) == 0 {
+			return nil, errors.New("the root has no key or index")
+		}
+		return base.Append(), nil
+	}
+	return base.Append(r.Pointer...), nil
+}
+
+// GetJSON evaluates r in doc from the value current points to, and returns the raw JSON
+// of the value, or of the key or index, when r ends with #
+func (r RelativePointer) GetJSON(doc []byte, current Pointer) ([]byte, error) {
+	ptr, err := r.Resolve(current)
+	if err != nil {
+		return nil, err
+	}
+
+	if r.Name {
+		// Make sure the value exists, before its name is returned
+		parent, err := ptr.Parent().GetJSON(doc)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := ptr[len(ptr)-1:].GetJSON(parent); err != nil {
+			return nil, err
+		}
+		if isArray(json.RawMessage(parent)) {
+			return []byte(ptr[len(ptr)-1]), nil
+		}
+		return json.Marshal(ptr[len(ptr)-1])
+	}
+
+	return ptr.GetJSON(doc)
+}
+
+// Get evaluates r in v from the value current points to, like Pointer.Get.
+// For pointers ending with #, the key is returned as a string and the index as an int.
+func (r RelativePointer) Get(v interface{}, current Pointer) (interface{}, error) {
+	ptr, err := r.Resolve(current)
+	if err != nil {
+		return nil, err
+	}
+
+	if r.Name {
+		parent, err := ptr.Parent().Get(v)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := ptr[len(ptr)-1:].Get(parent); err != nil {
+			return nil, err
+		}
+		name := ptr[len(ptr)-1]
+		if idx, err := strconv.Atoi(name); err == nil && isArray(parent) {
+			return idx, nil
+		}
+		return name, nil
+	}
+
+	return ptr.Get(v)
+}
+
+// isArray tells whether v is an array, either as a Go value or as raw JSON
+func isArray(v interface{}) bool {
+	if raw, ok := v.(json.RawMessage); ok {
+		return strings.HasPrefix(strings.TrimLeft(string(raw), " \t\r\n"), "[")
+	}
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr || rv.Kind() == reflect.Interface {
+		if rv.IsNil() {
+			return false
+		}
+		rv = rv.Elem()
+	}
+	return rv.Kind() == reflect.Slice || rv.Kind() == reflect.Array
+}
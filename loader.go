@@ -0,0 +1,66 @@
+package jsonschema
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"path/filepath"
+)
+
+// LoaderFunc returns the raw schema found at uri
+type LoaderFunc func(uri string) ([]byte, error)
+
+// Loader is used for fetching schemas, that are referenced with $ref, but not known already.
+// It can be replaced, e.g. to load schemas from disk instead of over the network.
+var Loader LoaderFunc = DefaultLoader
+
+// DefaultLoader loads schemas over http(s) or from the file system (file:// URIs and plain paths)
+func DefaultLoader(uri string) ([]byte, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return nil, err
+	}
+
+	switch u.Scheme {
+	case "http", "https":
+		res, err := http.Get(uri)
+		if err != nil {
+			return nil, err
+		}
+		defer res.Body.Close()
+
+		if res.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("unable to fetch %s: %s", uri, res.Status)
+		}
+
+		return ioutil.ReadAll(res.Body)
+
+	case "file":
+		return ioutil.ReadFile(filepath.FromSlash(u.Path))
+
+	case "":
+		return ioutil.ReadFile(uri)
+
+	default:
+		return nil, errors.New("unsupported URI scheme: " + u.Scheme)
+	}
+}
+
+// toURI turns a plain file path into a file:// URI, while any other URI is returned as is
+func toURI(uri string) (string, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return "", err
+	}
+	if u.Scheme != "" {
+		return uri, nil
+	}
+
+	abs, err := filepath.Abs(uri)
+	if err != nil {
+		return "", err
+	}
+	return (&url.URL{Scheme: "file", Path: filepath.ToSlash(abs)}).String(), nil
+}
@@ -0,0 +1,275 @@
+package jsonschema
+
+import (
+	"bytes"
+	"math/big"
+
+	"github.com/buger/jsonparser"
+)
+
+// MarshalOptions controls how MarshalWithOptions marshals a schema
+type MarshalOptions struct {
+	// Lossless keeps keys in the order they were parsed in, numbers and strings as they were
+	// written and unknown keywords in place. Keywords, that are changed after parsing, are
+	// marshalled like MarshalJSON does, and added keywords come after the parsed ones.
+	// $refs are never replaced by the schemas they point to.
+	Lossless bool
+}
+
+// MarshalWithOptions marshals the schema to JSON like MarshalJSON, unless opts say otherwise
+func (s *Schema) MarshalWithOptions(opts MarshalOptions) ([]byte, error) {
+	if s == nil {
+		return []byte("null"), nil
+	}
+	if !opts.Lossless {
+		return s.MarshalJSON()
+	}
+
+	n, err := losslessNode(s)
+	if err != nil {
+		return nil, err
+	}
+	return n.MarshalJSON()
+}
+
+// losslessNode returns the schema as a node, with everything, that hasn't changed since parsing,
+// as it was in the raw JSON
+func losslessNode(s *Schema) (*node, error) {
+	var original *node
+	if len(s.raw) > 0 {
+		// A schema, that can't be parsed, has nothing to keep
+		original, _ = parseNode(s.raw)
+	}
+
+	if s.boolean != nil {
+		return mergeNodes(original, newBoolNode(*s.boolean)), nil
+	}
+
+	b, err := s.marshalKeywords()
+	if err != nil {
+		return nil, err
+	}
+	n, err := parseNode(b)
+	if err != nil {
+		return nil, err
+	}
+
+	// Sub schemas are marshalled losslessly too, which also keeps their $refs from being inlined
+	sub := func(schema *Schema, set func(*node)) error {
+		if schema == nil {
+			return nil
+		}
+		subNode, err := losslessNode(schema)
+		if err != nil {
+			return err
+		}
+		set(subNode)
+		return nil
+	}
+
+	for kw, field := range schemaFields(s) {
+		if *field != nil && n.has(kw) {
+			if err := sub(*field, func(subNode *node) { n.set(kw, subNode) }); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	for kw, field := range propertiesFields(s) {
+		props := n.get(kw)
+		if *field == nil || props == nil {
+			continue
+		}
+		for _, prop := range **field {
+			if err := sub(prop.Property, func(subNode *node) { props.set(prop.Name, subNode) }); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	for kw, field := range schemasFields(s) {
+		if *field != nil {
+			if err := subSchemasNodes(n.get(kw), **field, sub); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if s.Items != nil && s.Items.Schema != nil {
+		if err := sub(s.Items.Schema, func(subNode *node) { n.set("items", subNode) }); err != nil {
+			return nil, err
+		}
+	} else if s.Items != nil && s.Items.Schemas != nil {
+		if err := subSchemasNodes(n.get("items"), *s.Items.Schemas, sub); err != nil {
+			return nil, err
+		}
+	}
+
+	if deps := n.get("dependencies"); s.Dependencies != nil && deps != nil {
+		for name, dep := range *s.Dependencies {
+			if dep == nil {
+				continue
+			}
+			name := name
+			if err := sub(dep.Schema, func(subNode *node) { deps.set(name, subNode) }); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	// Numbers are parsed at a limited precision, so a value, that is still the one parsed from
+	// the original literal, keeps the literal, even when it marshals to a different number
+	for kw, v := range valueFields(s) {
+		if lit := original.get(kw); n.has(kw) && literalValue(v, lit) {
+			n.set(kw, lit)
+		}
+	}
+	for kw, values := range valuesFields(s) {
+		if lit := original.get(kw); n.has(kw) && literalValues(values, lit) {
+			n.set(kw, lit)
+		}
+	}
+
+	return mergeNodes(original, n), nil
+}
+
+// valueFields returns the values of s, including unknown keywords, by keyword
+func valueFields(s *Schema) map[string]*Value {
+	values := map[string]*Value{
+		"default":          s.Default,
+		"const":            s.Const,
+		"maximum":          s.Maximum,
+		"exclusiveMaximum": s.ExclusiveMaximum,
+		"minimum":          s.Minimum,
+		"exclusiveMinimum": s.ExclusiveMinimum,
+	}
+	for _, up := range s.unknownProps {
+		values[up.Name] = up.Value
+	}
+	return values
+}
+
+// valuesFields returns the lists of values of s by keyword
+func valuesFields(s *Schema) map[string][]*Value {
+	values := map[string][]*Value{}
+	if s.Enum != nil {
+		values["enum"] = *s.Enum
+	}
+	if s.Examples != nil {
+		values["examples"] = *s.Examples
+	}
+	return values
+}
+
+// literalValue tells whether v is the value parsed from lit, and hasn't been replaced or changed since.
+// A number is compared by the literal it was parsed from, as it may have been rounded when parsed.
+func literalValue(v *Value, lit *node) bool {
+	if v == nil || lit == nil {
+		return false
+	}
+
+	switch {
+	case v.Number != nil:
+		if lit.typ != jsonparser.Number || !bytes.Equal(v.raw, lit.raw) {
+			return false
+		}
+		parsed, ok := new(big.Float).SetString(string(lit.raw))
+		return ok && parsed.Cmp(v.Number) == 0
+
+	case v.Object != nil:
+		if lit.typ != jsonparser.Object || len(lit.members) != len(*v.Object) {
+			return false
+		}
+		for _, m := range lit.members {
+			if !literalValue((*v.Object)[m.key], m.value) {
+				return false
+			}
+		}
+		return true
+
+	case v.Array != nil:
+		return literalValues(*v.Array, lit)
+
+	default:
+		parsed, err := NewValue(lit.raw, lit.typ)
+		if err != nil {
+			return false
+		}
+		a, errA := parsed.MarshalJSON()
+		b, errB := v.MarshalJSON()
+		return errA == nil && errB == nil && bytes.Equal(a, b)
+	}
+}
+
+// literalValues tells whether values are the values parsed from the array lit
+func literalValues(values []*Value, lit *node) bool {
+	if lit == nil || lit.typ != jsonparser.Array || len(lit.items) != len(values) {
+		return false
+	}
+	for i, v := range values {
+		if !literalValue(v, lit.items[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// subSchemasNodes replaces the items of an array node with the lossless nodes of the schemas
+func subSchemasNodes(arr *node, schemas Schemas, sub func(*Schema, func(*node)) error) error {
+	if arr == nil || arr.typ != jsonparser.Array {
+		return nil
+	}
+	for i, schema := range schemas {
+		if i >= len(arr.items) {
+			break
+		}
+		i := i
+		if err := sub(schema, func(subNode *node) { arr.items[i] = subNode }); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// mergeNodes returns updated, but with everything, that is equal in original, as it was in original,
+// i.e. the order of keys, the formatting of numbers and the escaping of strings
+func mergeNodes(original, updated *node) *node {
+	if original == nil || updated == nil || original.typ != updated.typ {
+		return updated
+	}
+
+	switch updated.typ {
+	case jsonparser.Object:
+		merged := newObjectNode()
+		for _, m := range original.members {
+			if value := updated.get(m.key); value != nil && !merged.has(m.key) {
+				merged.set(m.key, mergeNodes(m.value, value))
+			}
+		}
+		for _, m := range updated.members {
+			if !merged.has(m.key) {
+				merged.set(m.key, m.value)
+			}
+		}
+		return merged
+
+	case jsonparser.Array:
+		if len(original.items) != len(updated.items) {
+			return updated
+		}
+		merged := &node{typ: jsonparser.Array, items: make([]*node, len(updated.items))}
+		for i := range updated.items {
+			merged.items[i] = mergeNodes(original.items[i], updated.items[i])
+		}
+		return merged
+
+	default:
+		a, errA := canonicalJSON(original.raw, original.typ)
+		b, errB := canonicalJSON(updated.raw, updated.typ)
+		if errA == nil && errB == nil && bytes.Equal(a, b) {
+			return original
+		}
+	}
+
+	return updated
+}
@@ -0,0 +1,105 @@
+package jsonschema
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+var losslessTests = []string{
+	`{"type":"object","x-order":1,"properties":{"b":{"maximum":1.0},"a":{"minimum":12345678901234567890123,"multipleOf":0.10}},"required":["b","a"],"$comment":"é <b>"}`,
+	`{"$ref":"#/definitions/a","x-note":{"z":1,"y":[2.50,{"b":true,"a":null}]},"definitions":{"a":{"enum":[1.0,"A",{"d":1,"c":2},[98765432109876543210987]],"default":1E2}}}`,
+	`{"items":[{"const":-0.0},true,false],"additionalItems":{"exclusiveMinimum":1e-7},"dependencies":{"b":["a"],"a":{"not":{"type":"null"}}}}`,
+	`true`,
+}
+
+func TestMarshalLossless(t *testing.T) {
+	for _, test := range losslessTests {
+		schema, err := NewFromString(test)
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, err := schema.MarshalWithOptions(MarshalOptions{Lossless: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(b) != test {
+			t.Errorf("expected:\n%s\ngot:\n%s", test, b)
+		}
+	}
+}
+
+func TestMarshalLosslessDoesNotInlineRefs(t *testing.T) {
+	test := `{"properties":{"a":{"$ref":"#/definitions/a"}},"definitions":{"a":{"type":"string"}}}`
+	schema, err := NewFromString(test)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := schema.DeRef(); err != nil {
+		t.Fatal(err)
+	}
+	b, err := schema.MarshalWithOptions(MarshalOptions{Lossless: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != test {
+		t.Errorf("expected:\n%s\ngot:\n%s", test, b)
+	}
+}
+
+func TestMarshalLosslessChanged(t *testing.T) {
+	schema, err := NewFromString(`{"type":"object","x-order":1,"properties":{"b":{"maximum":1.0,"minimum":12345678901234567890123},"a":{"type":"string"}}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	maxLength := int64(3)
+	a, _ := schema.Properties.GetProperty("a")
+	a.Property.MaxLength = &maxLength
+	description := "changed"
+	schema.Description = &description
+	b, _ := schema.Properties.GetProperty("b")
+	b.Property.Maximum, _ = NewValue([]byte("2.0"), Number.ParserValueType())
+
+	expected := `{"type":"object","x-order":1,"properties":{"b":{"maximum":2,"minimum":12345678901234567890123},"a":{"type":"string","maxLength":3}},"description":"changed"}`
+	out, err := schema.MarshalWithOptions(MarshalOptions{Lossless: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(out) != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, out)
+	}
+
+	// A number, that only differs beyond the precision numbers are parsed with, is still a change
+	minimum := b.Property.Minimum
+	b.Property.Minimum, _ = NewValue([]byte("12345678901234567890124"), Number.ParserValueType())
+	out, err = schema.MarshalWithOptions(MarshalOptions{Lossless: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(out, []byte("12345678901234567890123")) {
+		t.Errorf("expected the changed minimum to be marshalled, got:\n%s", out)
+	}
+	b.Property.Minimum = minimum
+
+	// Recompile keeps the original formatting in the raw JSON, which is used by $refs
+	if err := schema.Recompile(); err != nil {
+		t.Fatal(err)
+	}
+	out, err = schema.MarshalWithOptions(MarshalOptions{Lossless: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(out) != expected {
+		t.Errorf("expected after Recompile:\n%s\ngot:\n%s", expected, out)
+	}
+
+	// Without Lossless, the schema is marshalled as usual
+	out, err = schema.MarshalWithOptions(MarshalOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if def, _ := json.Marshal(schema); !bytes.Equal(out, def) {
+		t.Errorf("expected:\n%s\ngot:\n%s", def, out)
+	}
+}
@@ -0,0 +1,158 @@
+package jsonschema
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Params holds the values used in the message of a ValidationError, e.g. the limit of maxItems
+type Params map[string]interface{}
+
+// Catalogue renders the messages of validation errors, e.g. in another language
+type Catalogue interface {
+	// Message returns the message for the error code, or false if the catalogue has none
+	Message(code string, params Params) (string, bool)
+}
+
+// Messages is a Catalogue of message templates by error code.
+// A template refers to the parameters by name, e.g. "too many items, the maximum is {maxItems}".
+type Messages map[string]string
+
+// Message renders the template for code
+func (m Messages) Message(code string, params Params) (string, bool) {
+	template, ok := m[code]
+	if !ok {
+		return "", false
+	}
+
+	if len(params) == 0 {
+		return template, true
+	}
+	replacements := make([]string, 0, 2*len(params))
+	for name, value := range params {
+		replacements = append(replacements, "{"+name+"}", fmt.Sprint(value))
+	}
+	return strings.NewReplacer(replacements...).Replace(template), true
+}
+
+// DefaultCatalogue renders the Message of every ValidationError
+var DefaultCatalogue Catalogue = English
+
+// English holds the messages of every error code
+var English = Messages{
+	"false":            "document does not match the false schema",
+	"empty":            `empty document is not valid against any other schemas than "false"`,
+	"type":             `value "{value}" is of type {type}, but should be of type: {expected}`,
+	"enum":             "value is not part of the enum set",
+	"const":            "value does not match const",
+	"minimum":          "value is less than the minimum of {minimum}",
+	"exclusiveMinimum": "value must be more than {minimum}",
+	"maximum":          "value is more than the maximum of {maximum}",
+	"exclusiveMaximum": "value must be less than {maximum}",
+	"multipleOf":       "value ({value}) is not a multiple of {multipleOf}",
+	"minLength":        "length of value is less than {minLength}",
+	"maxLength":        "length of value is more than {maxLength}",
+	"pattern":          "value does not match the pattern {pattern}",
+	"format":           "value is not a valid {format}",
+	"unknownFormat":    "unknown format: {format}",
+	"items":            "items doesn't match schema",
+	"additionalItems":  "index {index} has no schema to match against",
+	"minItems":         "too few items, the minimum is {minItems}",
+	"maxItems":         "too many items, the maximum is {maxItems}",
+	"uniqueItems":      "values are not unique",
+	"contains":         "no values matched the contains schema",
+	"minProperties":    "too few properties, the minimum is {minProperties}",
+	"maxProperties":    "too many properties, the maximum is {maxProperties}",
+	"required":         "required property {property} is missing",
+	"anyOf":            "value does not match any of the schemas",
+	"oneOf":            "value does not match one of the schemas",
+	"oneOfMultiple":    "value matches more than one of the schemas",
+	"not":              "value should not match the schema",
+}
+
+// German holds German translations of the messages
+var German = Messages{
+	"false":            "das Dokument entspricht nicht dem false-Schema",
+	"empty":            `ein leeres Dokument ist nur gegen das Schema "false" gültig`,
+	"type":             `der Wert "{value}" hat den Typ {type}, erwartet wird: {expected}`,
+	"enum":             "der Wert ist keiner der erlaubten Werte",
+	"const":            "der Wert entspricht nicht dem konstanten Wert",
+	"minimum":          "der Wert ist kleiner als das Minimum von {minimum}",
+	"exclusiveMinimum": "der Wert muss größer als {minimum} sein",
+	"maximum":          "der Wert ist größer als das Maximum von {maximum}",
+	"exclusiveMaximum": "der Wert muss kleiner als {maximum} sein",
+	"multipleOf":       "der Wert ({value}) ist kein Vielfaches von {multipleOf}",
+	"minLength":        "der Wert ist kürzer als {minLength} Zeichen",
+	"maxLength":        "der Wert ist länger als {maxLength} Zeichen",
+	"pattern":          "der Wert entspricht nicht dem Muster {pattern}",
+	"format":           "der Wert ist kein gültiges Format: {format}",
+	"unknownFormat":    "unbekanntes Format: {format}",
+	"items":            "die Elemente entsprechen nicht dem Schema",
+	"additionalItems":  "für Index {index} gibt es kein Schema",
+	"minItems":         "zu wenige Elemente, das Minimum ist {minItems}",
+	"maxItems":         "zu viele Elemente, das Maximum ist {maxItems}",
+	"uniqueItems":      "die Werte sind nicht eindeutig",
+	"contains":         "kein Wert entspricht dem contains-Schema",
+	"minProperties":    "zu wenige Eigenschaften, das Minimum ist {minProperties}",
+	"maxProperties":    "zu viele Eigenschaften, das Maximum ist {maxProperties}",
+	"required":         "die Pflichteigenschaft {property} fehlt",
+	"anyOf":            "der Wert entspricht keinem der Schemas",
+	"oneOf":            "der Wert entspricht nicht genau einem der Schemas",
+	"oneOfMultiple":    "der Wert entspricht mehr als einem der Schemas",
+	"not":              "der Wert darf dem Schema nicht entsprechen",
+}
+
+// Danish holds Danish translations of the messages
+var Danish = Messages{
+	"false":            "dokumentet matcher ikke false-skemaet",
+	"empty":            `et tomt dokument er kun gyldigt mod skemaet "false"`,
+	"type":             `værdien "{value}" er af typen {type}, men skal være af typen: {expected}`,
+	"enum":             "værdien er ikke en af de tilladte værdier",
+	"const":            "værdien matcher ikke den konstante værdi",
+	"minimum":          "værdien er mindre end minimum på {minimum}",
+	"exclusiveMinimum": "værdien skal være større end {minimum}",
+	"maximum":          "værdien er større end maksimum på {maximum}",
+	"exclusiveMaximum": "værdien skal være mindre end {maximum}",
+	"multipleOf":       "værdien ({value}) er ikke et multiplum af {multipleOf}",
+	"minLength":        "værdien er kortere end {minLength} tegn",
+	"maxLength":        "værdien er længere end {maxLength} tegn",
+	"pattern":          "værdien matcher ikke mønsteret {pattern}",
+	"format":           "værdien er ikke et gyldigt format: {format}",
+	"unknownFormat":    "ukendt format: {format}",
+	"items":            "elementerne matcher ikke skemaet",
+	"additionalItems":  "der er intet skema for indeks {index}",
+	"minItems":         "for få elementer, minimum er {minItems}",
+	"maxItems":         "for mange elementer, maksimum er {maxItems}",
+	"uniqueItems":      "værdierne er ikke unikke",
+	"contains":         "ingen værdier matcher contains-skemaet",
+	"minProperties":    "for få egenskaber, minimum er {minProperties}",
+	"maxProperties":    "for mange egenskaber, maksimum er {maxProperties}",
+	"required":         "den påkrævede egenskab {property} mangler",
+	"anyOf":            "værdien matcher ingen af skemaerne",
+	"oneOf":            "værdien matcher ikke præcis et af skemaerne",
+	"oneOfMultiple":    "værdien matcher mere end et af skemaerne",
+	"not":              "værdien må ikke matche skemaet",
+}
+
+// Localize returns the message of e rendered by c.
+// Codes c doesn't know are rendered by DefaultCatalogue, and errors without a code keep their message.
+func (e *ValidationError) Localize(c Catalogue) string {
+	if e.Code != "" {
+		for _, catalogue := range []Catalogue{c, DefaultCatalogue, English} {
+			if catalogue == nil {
+				continue
+			}
+			if msg, ok := catalogue.Message(e.Code, e.Params); ok {
+				return msg
+			}
+		}
+	}
+	return e.Message
+}
+
+// newValidationError returns an error for the code, with its message rendered by DefaultCatalogue
+func newValidationError(code string, params Params) *ValidationError {
+	e := &ValidationError{Code: code, Params: params}
+	e.Message = e.Localize(DefaultCatalogue)
+	return e
+}
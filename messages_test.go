@@ -0,0 +1,175 @@
+package jsonschema
+
+import (
+	"testing"
+)
+
+var errorCodeTests = []struct {
+	schema   string
+	doc      string
+	code     string
+	params   Params
+	expected string
+}{
+	{
+		schema:   `{"minimum": 5}`,
+		doc:      `3`,
+		code:     "minimum",
+		params:   Params{"minimum": "5"},
+		expected: "value is less than the minimum of 5",
+	},
+	{
+		schema:   `{"minimum": 5, "exclusiveMinimum": 10}`,
+		doc:      `7`,
+		code:     "exclusiveMinimum",
+		params:   Params{"minimum": "10"},
+		expected: "value must be more than 10",
+	},
+	{
+		schema:   `{"$schema": "http://json-schema.org/draft-04/schema#", "maximum": 5, "exclusiveMaximum": true}`,
+		doc:      `5`,
+		code:     "exclusiveMaximum",
+		params:   Params{"maximum": "5"},
+		expected: "value must be less than 5",
+	},
+	{
+		schema:   `{"maxItems": 1}`,
+		doc:      `[1, 2]`,
+		code:     "maxItems",
+		params:   Params{"maxItems": int64(1)},
+		expected: "too many items, the maximum is 1",
+	},
+	{
+		schema:   `{"minItems": 3}`,
+		doc:      `[1, 2]`,
+		code:     "minItems",
+		params:   Params{"minItems": int64(3)},
+		expected: "too few items, the minimum is 3",
+	},
+	{
+		schema:   `{"type": ["string", "null"]}`,
+		doc:      `1`,
+		code:     "type",
+		params:   Params{"value": "1", "type": Integer, "expected": "string, null"},
+		expected: `value "1" is of type integer, but should be of type: string, null`,
+	},
+	{
+		schema:   `{"format": "ipv4"}`,
+		doc:      `"localhost"`,
+		code:     "format",
+		params:   Params{"format": "ipv4"},
+		expected: "value is not a valid ipv4",
+	},
+	{
+		schema:   `{"required": ["name"]}`,
+		doc:      `{"name": ""}`,
+		expected: "",
+	},
+}
+
+func TestErrorCodes(t *testing.T) {
+	for i, tt := range errorCodeTests {
+		schema, err := NewFromString(tt.schema)
+		if err != nil {
+			t.Fatalf("test #%d: %s", i+1, err)
+		}
+
+		_, err = schema.Validate([]byte(tt.doc))
+		if tt.expected == "" {
+			if err != nil {
+				t.Fatalf("test #%d: expected the document to be valid, got: %s", i+1, err)
+			}
+			continue
+		}
+
+		errs := Errors(err)
+		if len(errs) != 1 {
+			t.Fatalf("test #%d: expected 1 error, got: %v", i+1, err)
+		}
+		if errs[0].Code != tt.code || errs[0].Message != tt.expected {
+			t.Fatalf("test #%d: expected %s: %s, got %s: %s", i+1, tt.code, tt.expected, errs[0].Code, errs[0].Message)
+		}
+		for name, value := range tt.params {
+			if errs[0].Params[name] != value {
+				t.Fatalf("test #%d: expected %s to be %v, got: %v", i+1, name, value, errs[0].Params[name])
+			}
+		}
+	}
+}
+
+func TestRequiredReportsEveryProperty(t *testing.T) {
+	schema, err := NewFromString(`{"required": ["name", "age", "name"]}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = schema.Validate([]byte(`{"age": 1, "email": "jane@example.com"}`))
+	errs := Errors(err)
+	if len(errs) != 1 || errs[0].Code != "required" || errs[0].Params["property"] != "name" {
+		t.Fatalf("expected name to be missing, got: %v", err)
+	}
+
+	_, err = schema.Validate([]byte(`{}`))
+	if errs := Errors(err); len(errs) != 2 {
+		t.Fatalf("expected 2 missing properties, got: %v", err)
+	}
+}
+
+func TestLocalize(t *testing.T) {
+	schema, err := NewFromString(`{"properties": {"tags": {"maxItems": 2}}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = schema.Validate([]byte(`{"tags": ["a", "b", "c"]}`))
+	errs := Errors(err)
+	if len(errs) != 1 {
+		t.Fatalf("expected 1 error, got: %v", err)
+	}
+
+	localized := []struct {
+		catalogue Catalogue
+		expected  string
+	}{
+		{English, "too many items, the maximum is 2"},
+		{German, "zu viele Elemente, das Maximum ist 2"},
+		{Danish, "for mange elementer, maksimum er 2"},
+		// Unknown codes fall back to English
+		{Messages{}, "too many items, the maximum is 2"},
+	}
+	for _, tt := range localized {
+		if msg := errs[0].Localize(tt.catalogue); msg != tt.expected {
+			t.Fatalf("expected: %s, got: %s", tt.expected, msg)
+		}
+	}
+
+	// Errors without a code keep their message
+	plain := &ValidationError{Message: "invalid"}
+	if msg := plain.Localize(German); msg != "invalid" {
+		t.Fatalf("expected the message to be kept, got: %s", msg)
+	}
+}
+
+func TestCataloguesAreComplete(t *testing.T) {
+	for name, catalogue := range map[string]Messages{"German": German, "Danish": Danish} {
+		for code := range English {
+			if _, ok := catalogue[code]; !ok {
+				t.Errorf("%s has no message for %s", name, code)
+			}
+		}
+	}
+}
+
+func TestDefaultCatalogue(t *testing.T) {
+	DefaultCatalogue = Danish
+	defer func() { DefaultCatalogue = English }()
+
+	schema, err := NewFromString(`{"minLength": 3}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = schema.Validate([]byte(`"ab"`))
+	if err == nil || err.Error() != "værdien er kortere end 3 tegn" {
+		t.Fatalf("expected a Danish message, got: %v", err)
+	}
+}
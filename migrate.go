@@ -0,0 +1,495 @@
+package jsonschema
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/buger/jsonparser"
+)
+
+// MigrationIssue describes something Migrate either changed in a way that
+// needs attention, or couldn't convert safely.
+type MigrationIssue struct {
+	// Pointer is the JSON Pointer to the schema, in the original document
+	Pointer string
+	Keyword string
+	Message string
+}
+
+func (i MigrationIssue) String() string {
+	return fmt.Sprintf("#%s/%s: %s", i.Pointer, i.Keyword, i.Message)
+}
+
+// Keywords that hold sub schemas, grouped by the shape of their value
+var (
+	singleSchemaKeywords = []string{
+		"additionalItems", "additionalProperties", "contains", "not", "if", "then", "else",
+		"propertyNames", "unevaluatedItems", "unevaluatedProperties", "contentSchema",
+	}
+	mapSchemaKeywords   = []string{"properties", "patternProperties", "definitions", "$defs", "dependentSchemas"}
+	arraySchemaKeywords = []string{"allOf", "anyOf", "oneOf", "prefixItems"}
+)
+
+// Keywords that don't affect validation and can therefore safely stay next to a $ref
+var refSiblingKeywords = map[string]struct{}{
+	"$schema":     {},
+	"$comment":    {},
+	"title":       {},
+	"description": {},
+	"default":     {},
+	"examples":    {},
+	"readOnly":    {},
+	"writeOnly":   {},
+	"definitions": {},
+	"$defs":       {},
+}
+
+type migrator struct {
+	source Draft
+	target Draft
+	issues []MigrationIssue
+}
+
+// Migrate rewrites a draft-04, draft-06, draft-07 or 2019-09 schema to a newer draft.
+// The source draft is taken from $schema. If $schema isn't set, the schema is treated
+// as draft-04 through draft-07, and keywords are converted based on their shape.
+// Unknown keywords and the original key order are kept.
+// Anything that could not be converted safely is returned as issues.
+func Migrate(schema []byte, target Draft) ([]byte, []MigrationIssue, error) {
+	if target == DraftUnknown {
+		return nil, nil, errors.New("unknown target draft")
+	}
+
+	root, err := parseNode(schema)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Boolean schemas needs no conversion
+	if root.typ == jsonparser.Boolean {
+		return schema, nil, nil
+	}
+	if root.typ != jsonparser.Object {
+		return nil, nil, fmt.Errorf("expected schema to be an object or boolean, got: %s", root.typ.String())
+	}
+
+	m := &migrator{target: target}
+	if str, ok := root.get("$schema").str(); ok {
+		m.source = DraftFromURI(str)
+	}
+	if m.source > target {
+		return nil, nil, fmt.Errorf("unable to migrate from %s to the older %s", m.source, target)
+	}
+
+	// Refs are rewritten first, as they need to be resolved against the original structure
+	m.rewriteRefs(root, root, "")
+	m.migrate(root, "")
+
+	if root.has("$schema") {
+		root.set("$schema", newStringNode(target.URI()))
+	} else {
+		root.members = append([]*member{{key: "$schema", value: newStringNode(target.URI())}}, root.members...)
+	}
+
+	out, err := root.MarshalJSON()
+	return out, m.issues, err
+}
+
+func (m *migrator) addIssue(ptr, keyword, format string, args ...interface{}) {
+	m.issues = append(m.issues, MigrationIssue{Pointer: ptr, Keyword: keyword, Message: fmt.Sprintf(format, args...)})
+}
+
+// upTo reports whether the source draft is at most d.
+// An unknown source is treated as any of the drafts Migrate accepts.
+func (m *migrator) upTo(d Draft) bool {
+	return m.source == DraftUnknown || m.source <= d
+}
+
+// eachSubSchema calls fn for every sub schema of n, with the pointer to the sub schema
+func eachSubSchema(n *node, ptr string, fn func(sub *node, subPtr string)) {
+	if n == nil || n.typ != jsonparser.Object {
+		return
+	}
+
+	for _, kw := range singleSchemaKeywords {
+		if sub := n.get(kw); sub != nil {
+			fn(sub, ptr+"/"+kw)
+		}
+	}
+
+	if items := n.get("items"); items != nil {
+		if items.typ == jsonparser.Array {
+			for i, sub := range items.items {
+				fn(sub, fmt.Sprintf("%s/items/%d", ptr, i))
+			}
+		} else {
+			fn(items, ptr+"/items")
+		}
+	}
+
+	for _, kw := range mapSchemaKeywords {
+		if subs := n.get(kw); subs != nil && subs.typ == jsonparser.Object {
+			for _, sub := range subs.members {
+				fn(sub.value, ptr+"/"+kw+"/"+escapePointerToken(sub.key))
+			}
+		}
+	}
+
+	if deps := n.get("dependencies"); deps != nil && deps.typ == jsonparser.Object {
+		for _, dep := range deps.members {
+			if dep.value.typ != jsonparser.Array {
+				fn(dep.value, ptr+"/dependencies/"+escapePointerToken(dep.key))
+			}
+		}
+	}
+
+	for _, kw := range arraySchemaKeywords {
+		if subs := n.get(kw); subs != nil && subs.typ == jsonparser.Array {
+			for i, sub := range subs.items {
+				fn(sub, fmt.Sprintf("%s/%s/%d", ptr, kw, i))
+			}
+		}
+	}
+}
+
+func (m *migrator) rewriteRefs(n, resource *node, ptr string) {
+	if n == nil || n.typ != jsonparser.Object {
+		return
+	}
+
+	// Fragments are resolved against the nearest schema with an $id, that changes the base URI
+	id, ok := n.get("$id").str()
+	if !ok && m.upTo(Draft04) {
+		id, ok = n.get("id").str()
+	}
+	if ok && !strings.HasPrefix(id, "#") {
+		resource = n
+	}
+
+	if ref, ok := n.get("$ref").str(); ok {
+		parts := strings.SplitN(ref, "#", 2)
+		if len(parts) == 2 && strings.HasPrefix(parts[1], "/") {
+			start := resource
+			if parts[0] != "" {
+				// Remote documents can't be inspected, so only the pointer itself is used
+				start = nil
+			}
+
+			frag := m.rewriteFragment(start, parts[1], ptr)
+			if frag != parts[1] {
+				n.set("$ref", newStringNode(parts[0]+"#"+frag))
+				if start == nil {
+					m.addIssue(ptr, "$ref", "rewrote pointer into remote schema %s, which must be migrated as well", parts[0])
+				}
+			}
+		}
+	}
+
+	eachSubSchema(n, ptr, func(sub *node, subPtr string) {
+		m.rewriteRefs(sub, resource, subPtr)
+	})
+}
+
+// rewriteFragment converts a JSON Pointer to the matching location after migration.
+// cur is the schema the pointer is resolved against, which is nil for remote schemas.
+func (m *migrator) rewriteFragment(cur *node, frag, ptr string) string {
+	tokens := strings.Split(frag[1:], "/")
+
+	for i := 0; i < len(tokens); i++ {
+		kw := tokens[i]
+		var next *node
+
+		switch kw {
+		case "definitions":
+			if m.target >= Draft2019_09 && (i+1 >= len(tokens) || definitionMoves(cur, unescapePointerToken(tokens[i+1]))) {
+				tokens[i] = "$defs"
+			}
+			fallthrough
+
+		case "properties", "patternProperties", "$defs":
+			if i+1 < len(tokens) {
+				i++
+				next = cur.get(kw).get(unescapePointerToken(tokens[i]))
+			}
+
+		case "dependencies":
+			if i+1 < len(tokens) {
+				i++
+				next = cur.get(kw).get(unescapePointerToken(tokens[i]))
+				if m.target >= Draft2019_09 {
+					if next != nil && next.typ == jsonparser.Array {
+						tokens[i-1] = "dependentRequired"
+					} else {
+						tokens[i-1] = "dependentSchemas"
+					}
+				}
+			}
+
+		case "allOf", "anyOf", "oneOf":
+			if i+1 < len(tokens) {
+				i++
+				next = nodeIndex(cur.get(kw), tokens[i])
+			}
+
+		case "items":
+			items := cur.get(kw)
+			if i+1 < len(tokens) && isArrayIndex(tokens[i+1]) && (items == nil || items.typ == jsonparser.Array) {
+				if m.target >= Draft2020_12 {
+					tokens[i] = "prefixItems"
+				}
+				i++
+				next = nodeIndex(items, tokens[i])
+			} else {
+				next = items
+			}
+
+		case "additionalItems":
+			if m.target >= Draft2020_12 {
+				items := cur.get("items")
+				if items != nil && items.typ != jsonparser.Array {
+					m.addIssue(ptr, "$ref", "pointer %s targets additionalItems, which is removed as it has no effect", frag)
+					return frag
+				}
+				tokens[i] = "items"
+			}
+			next = cur.get(kw)
+
+		case "not", "if", "then", "else", "additionalProperties", "propertyNames", "contains":
+			next = cur.get(kw)
+
+		default:
+			// Pointers into unknown keywords are left alone
+			return "/" + strings.Join(tokens, "/")
+		}
+
+		cur = next
+	}
+
+	return "/" + strings.Join(tokens, "/")
+}
+
+func (m *migrator) migrate(n *node, ptr string) {
+	if n == nil || n.typ != jsonparser.Object {
+		return
+	}
+
+	if m.target >= Draft2019_09 {
+		m.migrateRefSiblings(n, ptr)
+	}
+
+	m.migrateID(n, ptr)
+	m.migrateExclusive(n, ptr, "maximum", "exclusiveMaximum")
+	m.migrateExclusive(n, ptr, "minimum", "exclusiveMinimum")
+
+	if m.target >= Draft2019_09 {
+		m.migrateDefinitions(n, ptr)
+		m.migrateDependencies(n, ptr)
+
+		// $recursiveRef is valid in 2019-09 and replaced by $dynamicRef in 2020-12, so it only needs
+		// attention, when it wasn't part of the source draft, or is dropped by the target draft
+		if (m.upTo(Draft07) || m.target >= Draft2020_12) && (n.has("$recursiveRef") || n.has("$recursiveAnchor")) {
+			m.addIssue(ptr, "$recursiveRef", "$recursiveRef and $recursiveAnchor can't be converted automatically")
+		}
+	}
+
+	if m.target >= Draft2020_12 {
+		m.migrateItems(n, ptr)
+	}
+
+	eachSubSchema(n, ptr, func(sub *node, subPtr string) {
+		m.migrate(sub, subPtr)
+	})
+}
+
+func (m *migrator) migrateID(n *node, ptr string) {
+	if m.upTo(Draft04) {
+		if _, ok := n.get("id").str(); ok && !n.has("$id") {
+			n.rename("id", "$id")
+			if m.source == DraftUnknown {
+				m.addIssue(ptr, "id", "converted to $id, as the schema has no $schema")
+			}
+		}
+	}
+
+	if m.target < Draft2019_09 {
+		return
+	}
+
+	id, ok := n.get("$id").str()
+	if !ok {
+		return
+	}
+
+	idx := strings.Index(id, "#")
+	switch {
+	case idx == 0 && len(id) > 1:
+		// Plain name fragments are anchors from 2019-09 and forward
+		n.rename("$id", "$anchor")
+		n.set("$anchor", newStringNode(id[1:]))
+	case idx == len(id)-1:
+		// Empty fragments are not allowed anymore
+		n.set("$id", newStringNode(id[:idx]))
+	case idx > 0:
+		m.addIssue(ptr, "$id", "$id with a fragment (%s) is not allowed, but could not be split into $id and $anchor safely", id)
+	}
+}
+
+func (m *migrator) migrateExclusive(n *node, ptr, limitKey, exclusiveKey string) {
+	exclusive, ok := n.get(exclusiveKey).boolean()
+	if !ok || !m.upTo(Draft04) {
+		return
+	}
+
+	limit := n.get(limitKey)
+	switch {
+	case exclusive && limit != nil:
+		n.del(exclusiveKey)
+		n.rename(limitKey, exclusiveKey)
+	case exclusive:
+		n.del(exclusiveKey)
+		m.addIssue(ptr, exclusiveKey, "removed, as it has no effect without %s", limitKey)
+	default:
+		n.del(exclusiveKey)
+	}
+}
+
+// In draft-07 and earlier, everything next to $ref is ignored, which is not the case in later drafts.
+func (m *migrator) migrateRefSiblings(n *node, ptr string) {
+	if !n.has("$ref") || !m.upTo(Draft07) {
+		return
+	}
+
+	kept := []*member{}
+	for _, mem := range n.members {
+		if _, ok := refSiblingKeywords[mem.key]; ok || mem.key == "$ref" {
+			kept = append(kept, mem)
+		} else if _, known := nameToProp[mem.key]; known {
+			m.addIssue(ptr, mem.key, "removed, as it was ignored next to $ref")
+		} else {
+			kept = append(kept, mem)
+		}
+	}
+	n.members = kept
+}
+
+func (m *migrator) migrateDefinitions(n *node, ptr string) {
+	defs := n.get("definitions")
+	if defs == nil {
+		return
+	}
+
+	if existing := n.get("$defs"); existing != nil {
+		if existing.typ != jsonparser.Object || defs.typ != jsonparser.Object {
+			m.addIssue(ptr, "definitions", "could not be merged into $defs")
+			return
+		}
+		// Definitions, that also exist in $defs, are kept, so they and their $refs are left intact
+		kept := []*member{}
+		for _, def := range defs.members {
+			if existing.has(def.key) {
+				m.addIssue(ptr, "definitions", "%s also exists in $defs and was kept in definitions", def.key)
+				kept = append(kept, def)
+				continue
+			}
+			existing.set(def.key, def.value)
+		}
+		if len(kept) > 0 {
+			defs.members = kept
+		} else {
+			n.del("definitions")
+		}
+		return
+	}
+
+	n.rename("definitions", "$defs")
+}
+
+// definitionMoves tells whether migrateDefinitions moves the definition name of n to $defs
+func definitionMoves(n *node, name string) bool {
+	existing, defs := n.get("$defs"), n.get("definitions")
+	if existing == nil || defs == nil {
+		return true
+	}
+	return existing.typ == jsonparser.Object && defs.typ == jsonparser.Object && !existing.has(name)
+}
+
+func (m *migrator) migrateDependencies(n *node, ptr string) {
+	deps := n.get("dependencies")
+	if deps == nil || deps.typ != jsonparser.Object {
+		return
+	}
+
+	if n.has("dependentRequired") || n.has("dependentSchemas") {
+		m.addIssue(ptr, "dependencies", "could not be converted, as dependentRequired or dependentSchemas already exists")
+		return
+	}
+
+	required := newObjectNode()
+	schemas := newObjectNode()
+	for _, dep := range deps.members {
+		if dep.value.typ == jsonparser.Array {
+			required.set(dep.key, dep.value)
+		} else {
+			schemas.set(dep.key, dep.value)
+		}
+	}
+
+	members := []*member{}
+	for _, mem := range n.members {
+		if mem.key != "dependencies" {
+			members = append(members, mem)
+			continue
+		}
+		if len(required.members) > 0 {
+			members = append(members, &member{key: "dependentRequired", value: required})
+		}
+		if len(schemas.members) > 0 {
+			members = append(members, &member{key: "dependentSchemas", value: schemas})
+		}
+	}
+	n.members = members
+}
+
+func (m *migrator) migrateItems(n *node, ptr string) {
+	items := n.get("items")
+
+	if items != nil && items.typ == jsonparser.Array {
+		if n.has("prefixItems") {
+			m.addIssue(ptr, "items", "could not be converted, as prefixItems already exists")
+			return
+		}
+		n.rename("items", "prefixItems")
+		n.rename("additionalItems", "items")
+		return
+	}
+
+	if n.has("additionalItems") {
+		n.del("additionalItems")
+		if items != nil {
+			m.addIssue(ptr, "additionalItems", "removed, as it has no effect when items is a single schema")
+		}
+	}
+}
+
+func unescapePointerToken(token string) string {
+	token = strings.ReplaceAll(token, "~1", "/")
+	return strings.ReplaceAll(token, "~0", "~")
+}
+
+func isArrayIndex(token string) bool {
+	_, err := strconv.ParseUint(token, 10, 32)
+	return err == nil
+}
+
+func nodeIndex(n *node, token string) *node {
+	if n == nil || n.typ != jsonparser.Array {
+		return nil
+	}
+	idx, err := strconv.Atoi(token)
+	if err != nil || idx < 0 || idx >= len(n.items) {
+		return nil
+	}
+	return n.items[idx]
+}
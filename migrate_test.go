@@ -0,0 +1,82 @@
+package jsonschema
+
+import (
+	"testing"
+)
+
+var migrateTests = []struct {
+	schema         string
+	target         Draft
+	expectedSchema string
+	expectedIssues int
+}{
+	{
+		schema:         `{"$schema":"http://json-schema.org/draft-04/schema#","id":"http://example.com/root.json","x-custom":1,"properties":{"id":{"type":"integer","minimum":0,"exclusiveMinimum":true},"size":{"maximum":10,"exclusiveMaximum":false},"tags":{"$ref":"#/definitions/tags"}},"definitions":{"tags":{"type":"array","items":[{"type":"string"}],"additionalItems":false}}}`,
+		target:         Draft2020_12,
+		expectedSchema: `{"$schema":"https://json-schema.org/draft/2020-12/schema","$id":"http://example.com/root.json","x-custom":1,"properties":{"id":{"type":"integer","exclusiveMinimum":0},"size":{"maximum":10},"tags":{"$ref":"#/$defs/tags"}},"$defs":{"tags":{"type":"array","prefixItems":[{"type":"string"}],"items":false}}}`,
+	},
+	{
+		schema:         `{"$schema":"http://json-schema.org/draft-07/schema#","dependencies":{"a":["b"],"c":{"required":["d"]}},"properties":{"x":{"$ref":"#/dependencies/c","type":"string"}}}`,
+		target:         Draft2020_12,
+		expectedSchema: `{"$schema":"https://json-schema.org/draft/2020-12/schema","dependentRequired":{"a":["b"]},"dependentSchemas":{"c":{"required":["d"]}},"properties":{"x":{"$ref":"#/dependentSchemas/c"}}}`,
+		expectedIssues: 1,
+	},
+	{
+		schema:         `{"$schema":"http://json-schema.org/draft-07/schema#","items":[{"$id":"#first"}],"allOf":[{"$ref":"#/items/0"},{"$ref":"other.json#/items/0"}]}`,
+		target:         Draft2020_12,
+		expectedSchema: `{"$schema":"https://json-schema.org/draft/2020-12/schema","prefixItems":[{"$anchor":"first"}],"allOf":[{"$ref":"#/prefixItems/0"},{"$ref":"other.json#/prefixItems/0"}]}`,
+		expectedIssues: 1,
+	},
+	{
+		schema:         `{"id":"http://example.com/","maximum":5,"exclusiveMaximum":true,"definitions":{"a":{}}}`,
+		target:         Draft07,
+		expectedSchema: `{"$schema":"http://json-schema.org/draft-07/schema#","$id":"http://example.com/","exclusiveMaximum":5,"definitions":{"a":{}}}`,
+		expectedIssues: 1,
+	},
+	{
+		schema:         `{"$schema":"http://json-schema.org/draft-07/schema#","properties":{"a":{"$ref":"#/definitions/a"},"b":{"$ref":"#/definitions/b"}},"definitions":{"a":{"type":"string"},"b":{"type":"integer"}},"$defs":{"a":{"type":"boolean"}}}`,
+		target:         Draft2019_09,
+		expectedSchema: `{"$schema":"https://json-schema.org/draft/2019-09/schema","properties":{"a":{"$ref":"#/definitions/a"},"b":{"$ref":"#/$defs/b"}},"definitions":{"a":{"type":"string"}},"$defs":{"a":{"type":"boolean"},"b":{"type":"integer"}}}`,
+		expectedIssues: 1,
+	},
+	{
+		schema:         `{"$schema":"https://json-schema.org/draft/2019-09/schema","$recursiveAnchor":true,"properties":{"child":{"$recursiveRef":"#"}}}`,
+		target:         Draft2019_09,
+		expectedSchema: `{"$schema":"https://json-schema.org/draft/2019-09/schema","$recursiveAnchor":true,"properties":{"child":{"$recursiveRef":"#"}}}`,
+	},
+	{
+		schema:         `{"$schema":"http://json-schema.org/draft-07/schema#","properties":{"child":{"$recursiveRef":"#"}}}`,
+		target:         Draft2019_09,
+		expectedSchema: `{"$schema":"https://json-schema.org/draft/2019-09/schema","properties":{"child":{"$recursiveRef":"#"}}}`,
+		expectedIssues: 1,
+	},
+}
+
+func TestMigrate(t *testing.T) {
+	for i, tt := range migrateTests {
+		migrated, issues, err := Migrate([]byte(tt.schema), tt.target)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if string(migrated) != tt.expectedSchema {
+			t.Fatalf("test #%d: expected migrated schema to match:\n%s\ngot:\n%s\n", i+1, tt.expectedSchema, string(migrated))
+		}
+
+		if len(issues) != tt.expectedIssues {
+			t.Fatalf("test #%d: expected %d issues, got: %v", i+1, tt.expectedIssues, issues)
+		}
+
+		// The migrated schema must still be parseable
+		if _, err := New(migrated); err != nil {
+			t.Fatalf("test #%d: %s", i+1, err.Error())
+		}
+	}
+}
+
+func TestMigrateDowngrade(t *testing.T) {
+	_, _, err := Migrate([]byte(`{"$schema":"http://json-schema.org/draft-07/schema#"}`), Draft04)
+	if err == nil {
+		t.Fatal("expected an error when migrating to an older draft")
+	}
+}
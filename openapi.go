@@ -0,0 +1,391 @@
+package jsonschema
+
+import (
+	"errors"
+	"fmt"
+	"mime"
+	"strconv"
+	"strings"
+
+	"github.com/buger/jsonparser"
+)
+
+// OpenAPIDialect is the default $schema of OpenAPI 3.1 schemas: draft 2020-12 with the OpenAPI
+// base vocabulary, which only adds annotations, i.e. discriminator, example, externalDocs and xml.
+const OpenAPIDialect = "https://spec.openapis.org/oas/3.1/dialect/base"
+
+// OpenAPI holds the schemas of an OpenAPI 3.0 or 3.1 document.
+// The schemas in #/components/schemas are registered, so $refs like #/components/schemas/User
+// are resolved by ResolveRef.
+type OpenAPI struct {
+	// Version is the value of the openapi field, e.g. 3.0.3
+	Version string
+
+	// Dialect is the draft the schemas are validated with.
+	// OpenAPI 3.0 schemas are converted to draft 4, which their semantics are based on.
+	Dialect Draft
+
+	doc  *node
+	root *Schema
+
+	// schemas holds every schema parsed so far, by its JSON Pointer in the document
+	schemas map[string]*Schema
+}
+
+// NewOpenAPI parses an OpenAPI 3.0 or 3.1 document.
+// OpenAPI 3.0 schemas are converted to JSON Schema first, i.e. nullable adds null to type
+// and enum, example becomes examples, and a discriminator makes oneOf and anyOf pick the
+// schema by the value of the discriminator property. exclusiveMinimum and exclusiveMaximum
+// are booleans, as in draft 4.
+func NewOpenAPI(doc []byte) (*OpenAPI, error) {
+	root, err := parseNode(doc)
+	if err != nil {
+		return nil, err
+	}
+	if root.typ != jsonparser.Object {
+		return nil, errors.New("an OpenAPI document must be an object")
+	}
+
+	o := &OpenAPI{doc: root, schemas: map[string]*Schema{}}
+	o.Version, _ = root.get("openapi").str()
+
+	switch {
+	case strings.HasPrefix(o.Version, "3.0."):
+		o.Dialect = Draft04
+		convertOpenAPI30(root)
+	case strings.HasPrefix(o.Version, "3.1."):
+		o.Dialect = Draft2020_12
+		if dialect, ok := root.get("jsonSchemaDialect").str(); ok && dialect != OpenAPIDialect {
+			if o.Dialect = DraftFromURI(dialect); o.Dialect == DraftUnknown {
+				return nil, fmt.Errorf("unsupported jsonSchemaDialect: %s", dialect)
+			}
+		}
+	default:
+		return nil, fmt.Errorf("unsupported OpenAPI version: %q", o.Version)
+	}
+
+	// The document is the root of every schema, so $refs into it can be resolved
+	root.set("$schema", newStringNode(o.Dialect.URI()))
+	raw, err := root.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+	o.root, err = New(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	if schemas := root.get("components").get("schemas"); schemas != nil && schemas.typ == jsonparser.Object {
+		for _, m := range schemas.members {
+			if _, err := o.parseSchema(m.value, "#/components/schemas/"+escapePointerToken(m.key)); err != nil {
+				return nil, fmt.Errorf("#/components/schemas/%s: %w", m.key, err)
+			}
+		}
+	}
+
+	return o, nil
+}
+
+// Schema returns the schema named name in #/components/schemas
+func (o *OpenAPI) Schema(name string) (*Schema, error) {
+	schema, ok := o.schemas["#/components/schemas/"+escapePointerToken(name)]
+	if !ok {
+		return nil, fmt.Errorf("unable to find schema: %s", name)
+	}
+	return schema, nil
+}
+
+// RequestSchema returns the schema of the request body of an operation.
+// path is either a path template, e.g. /users/{id}, or a request path, e.g. /users/42.
+// contentType defaults to application/json, and may match e.g. application/* in the document.
+func (o *OpenAPI) RequestSchema(path, method, contentType string) (*Schema, error) {
+	op, ptr, err := o.operation(path, method)
+	if err != nil {
+		return nil, err
+	}
+
+	body, ptr := o.deref(op.get("requestBody"), ptr+"/requestBody")
+	if body == nil {
+		return nil, fmt.Errorf("%s %s has no request body", strings.ToUpper(method), path)
+	}
+
+	return o.contentSchema(body, ptr, contentType)
+}
+
+// ResponseSchema returns the schema of the response body of an operation, for the status code.
+// The response is found by the exact status code, then by its range, e.g. 2XX, and finally default.
+func (o *OpenAPI) ResponseSchema(path, method string, status int, contentType string) (*Schema, error) {
+	op, ptr, err := o.operation(path, method)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := op.get("responses")
+	code := strconv.Itoa(status)
+	for _, key := range []string{code, code[:1] + "XX", code[:1] + "xx", "default"} {
+		if res := responses.get(key); res != nil {
+			res, resPtr := o.deref(res, ptr+"/responses/"+key)
+			return o.contentSchema(res, resPtr, contentType)
+		}
+	}
+
+	return nil, fmt.Errorf("%s %s has no response for status %d", strings.ToUpper(method), path, status)
+}
+
+// operation finds the operation object for the method and path
+func (o *OpenAPI) operation(path, method string) (*node, string, error) {
+	paths := o.doc.get("paths")
+	if paths == nil || paths.typ != jsonparser.Object {
+		return nil, "", errors.New("the document has no paths")
+	}
+
+	var item *node
+	var itemPtr string
+	if exact := paths.get(path); exact != nil {
+		item, itemPtr = exact, "#/paths/"+escapePointerToken(path)
+	} else {
+		for _, m := range paths.members {
+			if matchPathTemplate(m.key, path) {
+				item, itemPtr = m.value, "#/paths/"+escapePointerToken(m.key)
+				break
+			}
+		}
+	}
+	if item == nil {
+		return nil, "", fmt.Errorf("unable to find path: %s", path)
+	}
+
+	item, itemPtr = o.deref(item, itemPtr)
+	method = strings.ToLower(method)
+	op := item.get(method)
+	if op == nil || op.typ != jsonparser.Object {
+		return nil, "", fmt.Errorf("%s has no %s operation", path, strings.ToUpper(method))
+	}
+
+	return op, itemPtr + "/" + method, nil
+}
+
+// contentSchema returns the schema for the content type, from a request body or response object
+func (o *OpenAPI) contentSchema(n *node, ptr, contentType string) (*Schema, error) {
+	if contentType == "" {
+		contentType = "application/json"
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return nil, err
+	}
+
+	content := n.get("content")
+	if content == nil {
+		return nil, fmt.Errorf("%s has no content", ptr)
+	}
+
+	slash := strings.Index(mediaType, "/")
+	candidates := []string{mediaType, mediaType[:slash+1] + "*", "*/*"}
+	if slash < 0 {
+		candidates = []string{mediaType, "*/*"}
+	}
+	for _, candidate := range candidates {
+		for _, m := range content.members {
+			key, _, err := mime.ParseMediaType(m.key)
+			if err != nil || key != candidate {
+				continue
+			}
+			schemaPtr := ptr + "/content/" + escapePointerToken(m.key) + "/schema"
+			schema := m.value.get("schema")
+			if schema == nil {
+				return nil, fmt.Errorf("%s has no schema", schemaPtr)
+			}
+			return o.parseSchema(schema, schemaPtr)
+		}
+	}
+
+	return nil, fmt.Errorf("%s has no content for %s", ptr, mediaType)
+}
+
+// parseSchema parses a schema in the document, and registers it under its pointer
+func (o *OpenAPI) parseSchema(n *node, ptr string) (*Schema, error) {
+	if schema, ok := o.schemas[ptr]; ok {
+		return schema, nil
+	}
+
+	raw, err := n.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+	schema, err := o.root.Parse(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	o.schemas[ptr] = schema
+	o.root.setPointer(ptr, schema)
+	return schema, nil
+}
+
+// deref follows local $refs between the objects of the document, e.g. #/components/responses/NotFound
+func (o *OpenAPI) deref(n *node, ptr string) (*node, string) {
+	for i := 0; i < 32 && n != nil; i++ {
+		ref, ok := n.get("$ref").str()
+		if !ok || !strings.HasPrefix(ref, "#") {
+			break
+		}
+		n, ptr = nodeAtPointer(o.doc, ref[1:]), ref
+	}
+	return n, ptr
+}
+
+// nodeAtPointer returns the node at the JSON Pointer ptr, or nil
+func nodeAtPointer(n *node, ptr string) *node {
+	if ptr == "" {
+		return n
+	}
+	for _, token := range strings.Split(strings.TrimPrefix(ptr, "/"), "/") {
+		token = unescapePointerToken(token)
+		if n != nil && n.typ == jsonparser.Array {
+			n = nodeIndex(n, token)
+		} else {
+			n = n.get(token)
+		}
+	}
+	return n
+}
+
+// matchPathTemplate reports whether path matches the template, e.g. /users/{id} matches /users/42
+func matchPathTemplate(template, path string) bool {
+	templateParts := strings.Split(strings.Trim(template, "/"), "/")
+	pathParts := strings.Split(strings.Trim(path, "/"), "/")
+	if len(templateParts) != len(pathParts) {
+		return false
+	}
+
+	for i, part := range templateParts {
+		if strings.HasPrefix(part, "{") && strings.HasSuffix(part, "}") && pathParts[i] != "" {
+			continue
+		}
+		if part != pathParts[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// convertOpenAPI30 converts every schema in an OpenAPI 3.0 document to JSON Schema
+func convertOpenAPI30(doc *node) {
+	components := doc.get("components")
+
+	var walk func(n *node)
+	walk = func(n *node) {
+		switch n.typ {
+		case jsonparser.Object:
+			for _, m := range n.members {
+				switch m.key {
+				case "schema":
+					convertOpenAPI30Schema(m.value)
+				case "example", "examples":
+					// Examples are data, not schemas
+				case "schemas":
+					if n != components {
+						walk(m.value)
+					}
+				default:
+					walk(m.value)
+				}
+			}
+		case jsonparser.Array:
+			for _, item := range n.items {
+				walk(item)
+			}
+		}
+	}
+	walk(doc)
+
+	if schemas := components.get("schemas"); schemas != nil && schemas.typ == jsonparser.Object {
+		for _, m := range schemas.members {
+			convertOpenAPI30Schema(m.value)
+		}
+	}
+}
+
+func convertOpenAPI30Schema(n *node) {
+	if n == nil || n.typ != jsonparser.Object {
+		return
+	}
+
+	eachSubSchema(n, "", func(sub *node, _ string) {
+		convertOpenAPI30Schema(sub)
+	})
+
+	if nullable, _ := n.get("nullable").boolean(); nullable {
+		if typ := n.get("type"); typ != nil && typ.typ == jsonparser.String {
+			n.set("type", &node{typ: jsonparser.Array, items: []*node{typ, {typ: jsonparser.Null, raw: nullLiteral}}})
+		}
+		if enum := n.get("enum"); enum != nil && enum.typ == jsonparser.Array {
+			hasNull := false
+			for _, item := range enum.items {
+				hasNull = hasNull || item.typ == jsonparser.Null
+			}
+			if !hasNull {
+				enum.items = append(enum.items, &node{typ: jsonparser.Null, raw: nullLiteral})
+			}
+		}
+	}
+	n.del("nullable")
+
+	if example := n.get("example"); example != nil && !n.has("examples") {
+		n.rename("example", "examples")
+		n.set("examples", &node{typ: jsonparser.Array, items: []*node{example}})
+	}
+
+	convertDiscriminator(n)
+}
+
+// convertDiscriminator makes each $ref of oneOf or anyOf require its discriminator value, so the
+// discriminator property decides which schema the value is validated against.
+// The values are the keys of the mapping pointing to the schema, or the name of the schema.
+func convertDiscriminator(n *node) {
+	discriminator := n.get("discriminator")
+	property, ok := discriminator.get("propertyName").str()
+	if !ok {
+		return
+	}
+
+	for _, kw := range []string{"oneOf", "anyOf"} {
+		branches := n.get(kw)
+		if branches == nil || branches.typ != jsonparser.Array {
+			continue
+		}
+
+		for i, branch := range branches.items {
+			ref, ok := branch.get("$ref").str()
+			if !ok {
+				continue
+			}
+
+			name := ref[strings.LastIndex(ref, "/")+1:]
+			values := &node{typ: jsonparser.Array, items: []*node{}}
+			if mapping := discriminator.get("mapping"); mapping != nil {
+				for _, m := range mapping.members {
+					if target, _ := m.value.str(); target == ref || target == name {
+						values.items = append(values.items, newStringNode(m.key))
+					}
+				}
+			}
+			if len(values.items) == 0 {
+				values.items = append(values.items, newStringNode(unescapePointerToken(name)))
+			}
+
+			propSchema := newObjectNode()
+			propSchema.set("enum", values)
+			props := newObjectNode()
+			props.set(property, propSchema)
+			match := newObjectNode()
+			match.set("required", &node{typ: jsonparser.Array, items: []*node{newStringNode(property)}})
+			match.set("properties", props)
+
+			wrapped := newObjectNode()
+			wrapped.set("allOf", &node{typ: jsonparser.Array, items: []*node{match, branch}})
+			branches.items[i] = wrapped
+		}
+	}
+}
@@ -0,0 +1,173 @@
+package jsonschema
+
+import (
+	"net/http"
+	"testing"
+)
+
+var openAPI30Doc = `{
+	"openapi": "3.0.3",
+	"info": {"title": "Pets", "version": "1.0.0"},
+	"paths": {
+		"/pets/{id}": {
+			"put": {
+				"parameters": [{"name": "id", "in": "path", "required": true, "schema": {"type": "integer"}}],
+				"requestBody": {"$ref": "#/components/requestBodies/Pet"},
+				"responses": {
+					"200": {"description": "OK", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Pet"}}}},
+					"4XX": {"description": "Error", "content": {"application/problem+json": {"schema": {"$ref": "#/components/schemas/Error"}}}}
+				}
+			}
+		}
+	},
+	"components": {
+		"requestBodies": {
+			"Pet": {"required": true, "content": {"application/json; charset=utf-8": {"schema": {"$ref": "#/components/schemas/Pet"}}}}
+		},
+		"schemas": {
+			"Pet": {
+				"oneOf": [{"$ref": "#/components/schemas/Cat"}, {"$ref": "#/components/schemas/Dog"}],
+				"discriminator": {"propertyName": "kind", "mapping": {"cat": "#/components/schemas/Cat"}}
+			},
+			"Cat": {
+				"type": "object",
+				"required": ["kind"],
+				"properties": {"kind": {"type": "string"}, "lives": {"type": "integer", "minimum": 0, "maximum": 9, "exclusiveMaximum": true}}
+			},
+			"Dog": {
+				"type": "object",
+				"required": ["kind"],
+				"properties": {"kind": {"type": "string"}, "owner": {"type": "string", "nullable": true, "example": "Jane"}}
+			},
+			"Error": {"type": "object", "properties": {"title": {"type": "string"}}}
+		}
+	}
+}`
+
+var openAPI30Tests = []struct {
+	doc   string
+	valid bool
+}{
+	{doc: `{"kind":"cat","lives":8}`, valid: true},
+	{doc: `{"kind":"cat","lives":9}`, valid: false},
+	{doc: `{"kind":"Dog","owner":null}`, valid: true},
+	{doc: `{"kind":"Dog","owner":1}`, valid: false},
+	{doc: `{"kind":"Cat"}`, valid: false},
+	{doc: `{"kind":"dog"}`, valid: false},
+}
+
+func TestOpenAPI30(t *testing.T) {
+	api, err := NewOpenAPI([]byte(openAPI30Doc))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if api.Dialect != Draft04 {
+		t.Fatalf("expected draft 4, got: %s", api.Dialect)
+	}
+
+	request, err := api.RequestSchema("/pets/42", http.MethodPut, "application/json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	pet, err := api.Schema("Pet")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i, tt := range openAPI30Tests {
+		for _, schema := range []*Schema{request, pet} {
+			valid, err := schema.Validate([]byte(tt.doc))
+			if valid != tt.valid {
+				t.Fatalf("test #%d: expected valid to be %t, got: %t (%v)", i+1, tt.valid, valid, err)
+			}
+		}
+	}
+
+	dog, err := api.Schema("Dog")
+	if err != nil {
+		t.Fatal(err)
+	}
+	example, err := Example(dog)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(example) != `{"kind":"string","owner":"Jane"}` {
+		t.Fatalf("expected the example to be used, got: %s", example)
+	}
+
+	response, err := api.ResponseSchema("/pets/{id}", http.MethodPut, http.StatusNotFound, "application/problem+json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if valid, _ := response.Validate([]byte(`{"title":1}`)); valid {
+		t.Fatal("expected the error response to be invalid")
+	}
+
+	if _, err := api.ResponseSchema("/pets/{id}", http.MethodPut, http.StatusInternalServerError, ""); err == nil {
+		t.Fatal("expected an error for an undocumented status")
+	}
+	if _, err := api.RequestSchema("/pets/42", http.MethodGet, ""); err == nil {
+		t.Fatal("expected an error for an undocumented method")
+	}
+}
+
+func TestOpenAPI31(t *testing.T) {
+	api, err := NewOpenAPI([]byte(`{
+		"openapi": "3.1.0",
+		"info": {"title": "Users", "version": "1.0.0"},
+		"paths": {
+			"/users": {
+				"post": {
+					"requestBody": {"content": {"application/*": {"schema": {"$ref": "#/components/schemas/User"}}}},
+					"responses": {"default": {"description": "OK"}}
+				}
+			}
+		},
+		"components": {
+			"schemas": {
+				"User": {"type": "object", "required": ["name"], "properties": {"name": {"type": ["string", "null"]}, "age": {"exclusiveMinimum": 0}}}
+			}
+		}
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if api.Dialect != Draft2020_12 {
+		t.Fatalf("expected draft 2020-12, got: %s", api.Dialect)
+	}
+
+	schema, err := api.RequestSchema("/users", http.MethodPost, "application/json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if valid, err := schema.Validate([]byte(`{"name":null,"age":1}`)); !valid {
+		t.Fatal(err)
+	}
+	if valid, _ := schema.Validate([]byte(`{"name":"Jane","age":0}`)); valid {
+		t.Fatal("expected exclusiveMinimum to be a number")
+	}
+
+	// The schema is registered, so $refs from other schemas resolve to the same instance
+	user, err := api.Schema("User")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ref := "#/components/schemas/User"
+	resolved, err := schema.ResolveRef(&Ref{String: &ref})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resolved != user {
+		t.Fatal("expected $ref to resolve to the registered schema")
+	}
+
+	if _, err := api.ResponseSchema("/users", http.MethodPost, http.StatusOK, ""); err == nil {
+		t.Fatal("expected an error for a response without content")
+	}
+}
+
+func TestOpenAPIUnsupportedVersion(t *testing.T) {
+	if _, err := NewOpenAPI([]byte(`{"swagger":"2.0"}`)); err == nil {
+		t.Fatal("expected an error for Swagger 2.0")
+	}
+}
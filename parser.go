@@ -133,6 +133,9 @@ func (s *Schema) Parse(jsonSchema []byte) (*Schema, error) {
 		case PropDefinitions:
 			schema.Definitions, err = NewProperties(value, vt, schema)
 			errs = addError(err, errs)
+		case PropDefs:
+			schema.Defs, err = NewProperties(value, vt, schema)
+			errs = addError(err, errs)
 		case PropIf:
 			schema.If, err = schema.Parse(value)
 			errs = addError(err, errs)
@@ -312,6 +315,7 @@ func (s *Schema) setRef(ref *Ref) {
 //       e.g. by excluding everything else if $ref is set
 func (s *Schema) setupValidators() {
 	s.validators = []validatorFunc{}
+	s.plan = compilePlan(s)
 
 	// Always start by validating the value
 	s.validators = append(s.validators, validateValue)
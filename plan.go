@@ -0,0 +1,226 @@
+package jsonschema
+
+import (
+	"math"
+	"math/big"
+	"regexp"
+
+	"github.com/buger/jsonparser"
+)
+
+// plan holds the constraints of a schema, parsed into the form the validators use,
+// so they don't have to be parsed again for every value that is validated.
+// It is compiled together with the validators and must not be changed afterwards.
+type plan struct {
+	// properties is Properties by name
+	properties map[string]*Schema
+
+	// patternProperties is PatternProperties with the compiled regexps, in schema order
+	patternProperties []patternProperty
+
+	// enum and constant hold the canonical forms of enum and const
+	enum     canonicalSet
+	constant []byte
+
+	maximum, exclusiveMaximum *bound
+	minimum, exclusiveMinimum *bound
+	multipleOf                *multiple
+}
+
+type patternProperty struct {
+	re     *regexp.Regexp
+	schema *Schema
+}
+
+// bound is a numeric limit, e.g. maximum
+type bound struct {
+	raw string
+	rat *big.Rat
+
+	// float is the limit as a float64 and exact tells whether that is the exact limit
+	float float64
+	exact bool
+}
+
+// multiple is the value of multipleOf
+type multiple struct {
+	raw string
+	rat *big.Rat
+
+	// decimal is multipleOf as decimal / 10^scale, when it fits in an int64
+	decimal int64
+	scale   int
+}
+
+// compilePlan compiles the plan of the schema
+func compilePlan(s *Schema) *plan {
+	p := &plan{}
+
+	if s.Properties != nil {
+		p.properties = make(map[string]*Schema, len(*s.Properties))
+		for _, prop := range *s.Properties {
+			// The first property wins, just like GetProperty
+			if _, ok := p.properties[prop.Name]; !ok {
+				p.properties[prop.Name] = prop.Property
+				if prop.Property != nil {
+					prop.Property.name = prop.Name
+				}
+			}
+		}
+	}
+
+	if s.PatternProperties != nil && s.patternPropertiesRegexps != nil {
+		for _, prop := range *s.PatternProperties {
+			if re, ok := (*s.patternPropertiesRegexps)[prop.Name]; ok && prop.Property != nil {
+				p.patternProperties = append(p.patternProperties, patternProperty{re: re, schema: prop.Property})
+			}
+		}
+	}
+
+	if s.Enum != nil {
+		p.enum = canonicalSet{}
+		for _, v := range *s.Enum {
+			if canonical, err := v.canonical(); err == nil {
+				p.enum.add(canonical)
+			}
+		}
+	}
+	if s.Const != nil {
+		// A const, that can't be canonicalized, can't match anything
+		p.constant, _ = s.Const.canonical()
+	}
+
+	p.maximum = newBound(s.Maximum)
+	p.exclusiveMaximum = newBound(s.ExclusiveMaximum)
+	p.minimum = newBound(s.Minimum)
+	p.exclusiveMinimum = newBound(s.ExclusiveMinimum)
+
+	if s.MultipleOf != nil {
+		if rat, ok := new(big.Rat).SetString(string(*s.MultipleOf)); ok && rat.Sign() != 0 {
+			p.multipleOf = &multiple{raw: string(*s.MultipleOf), rat: rat}
+			if decimal, scale, ok := parseDecimal([]byte(*s.MultipleOf)); ok {
+				p.multipleOf.decimal, p.multipleOf.scale = decimal, scale
+			}
+		}
+	}
+
+	return p
+}
+
+// newBound returns the bound of a numeric value, or nil if it isn't a number,
+// e.g. the boolean exclusiveMaximum of draft 4
+func newBound(v *Value) *bound {
+	if v == nil || (v.valueType != Number && v.valueType != Integer) {
+		return nil
+	}
+	raw := string(v.Raw())
+	rat, ok := new(big.Rat).SetString(raw)
+	if !ok {
+		return nil
+	}
+	f, exact := rat.Float64()
+	return &bound{raw: raw, rat: rat, float: f, exact: exact}
+}
+
+// compare compares the number value with the bound, like big.Rat.Cmp
+func (b *bound) compare(value []byte) int {
+	if f, exact, ok := parseFloat(value); ok {
+		// Rounding to float64 never changes the order of two numbers, only makes some equal
+		if f < b.float {
+			return -1
+		} else if f > b.float {
+			return 1
+		} else if exact && b.exact {
+			return 0
+		}
+	}
+
+	rat, ok := new(big.Rat).SetString(string(value))
+	if !ok {
+		return 0
+	}
+	return rat.Cmp(b.rat)
+}
+
+// isMultiple tells whether the number value is a multiple
+func (m *multiple) isMultiple(value []byte) bool {
+	if m.decimal != 0 {
+		if a, scale, ok := parseDecimal(value); ok {
+			// Scale both to the same number of decimals, unless that overflows
+			b := m.decimal
+			for ; ok && scale < m.scale; scale++ {
+				a, ok = times10(a)
+			}
+			for bScale := m.scale; ok && bScale < scale; bScale++ {
+				b, ok = times10(b)
+			}
+			if ok {
+				return a%b == 0
+			}
+		}
+	}
+
+	rat, ok := new(big.Rat).SetString(string(value))
+	if !ok {
+		return false
+	}
+	return rat.Quo(rat, m.rat).IsInt()
+}
+
+// maxExactInt is the largest integer, that every smaller integer can be represented exactly by a float64
+const maxExactInt = 1 << 53
+
+// parseFloat parses a JSON number into a float64, and tells whether that is the exact number
+func parseFloat(value []byte) (f float64, exact bool, ok bool) {
+	// jsonparser parses without converting to a string, which would allocate
+	if i, err := jsonparser.ParseInt(value); err == nil {
+		return float64(i), -maxExactInt <= i && i <= maxExactInt, true
+	}
+	f, err := jsonparser.ParseFloat(value)
+	if err != nil || math.IsInf(f, 0) {
+		return 0, false, false
+	}
+	return f, false, true
+}
+
+func times10(i int64) (int64, bool) {
+	if i > math.MaxInt64/10 || i < math.MinInt64/10 {
+		return 0, false
+	}
+	return i * 10, true
+}
+
+// parseDecimal parses a JSON number without an exponent, into decimal / 10^scale,
+// if decimal fits in an int64
+func parseDecimal(value []byte) (decimal int64, scale int, ok bool) {
+	negative := len(value) > 0 && value[0] == '-'
+	if negative {
+		value = value[1:]
+	}
+	if len(value) == 0 {
+		return 0, 0, false
+	}
+
+	point := false
+	for _, c := range value {
+		switch {
+		case c == '.' && !point:
+			point = true
+		case c >= '0' && c <= '9':
+			if decimal > (math.MaxInt64-int64(c-'0'))/10 {
+				return 0, 0, false
+			}
+			decimal = decimal*10 + int64(c-'0')
+			if point {
+				scale++
+			}
+		default:
+			return 0, 0, false
+		}
+	}
+
+	if negative {
+		decimal = -decimal
+	}
+	return decimal, scale, true
+}
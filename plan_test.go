@@ -0,0 +1,153 @@
+package jsonschema
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+var planBoundTests = []struct {
+	schema string
+	doc    string
+	valid  bool
+}{
+	{schema: `{"maximum": 10}`, doc: `10`, valid: true},
+	{schema: `{"maximum": 10}`, doc: `10.0000000000000000001`},
+	{schema: `{"maximum": 0.1}`, doc: `0.1`, valid: true},
+	{schema: `{"maximum": 0.1}`, doc: `0.10000000000000000001`},
+	{schema: `{"minimum": 0.1}`, doc: `0.09999999999999999999`},
+	{schema: `{"exclusiveMaximum": 9007199254740993}`, doc: `9007199254740992`, valid: true},
+	{schema: `{"exclusiveMaximum": 9007199254740993}`, doc: `9007199254740993`},
+	{schema: `{"minimum": -9007199254740993}`, doc: `-9007199254740994`},
+	{schema: `{"maximum": 1e308}`, doc: `1e400`},
+	{schema: `{"minimum": 1e-400}`, doc: `0`},
+	{schema: `{"multipleOf": 3}`, doc: `9223372036854775806`, valid: true},
+	{schema: `{"multipleOf": 3}`, doc: `9223372036854775808`},
+	{schema: `{"multipleOf": 3}`, doc: `9223372036854775809`, valid: true},
+	{schema: `{"multipleOf": 0.1}`, doc: `0.3`, valid: true},
+	{schema: `{"multipleOf": 2}`, doc: `4.0`, valid: true},
+	{schema: `{"multipleOf": 2}`, doc: `-7`},
+	{schema: `{"multipleOf": 0.25}`, doc: `-1.75`, valid: true},
+	{schema: `{"multipleOf": 0.25}`, doc: `1.7`},
+	{schema: `{"multipleOf": 1.5}`, doc: `4.50000000000000000000001`},
+	{schema: `{"multipleOf": 0.0001}`, doc: `922337203685477.5807`, valid: true},
+	{schema: `{"multipleOf": 0.01}`, doc: `1e-2`, valid: true},
+}
+
+func TestPlanBounds(t *testing.T) {
+	for i, tt := range planBoundTests {
+		schema, err := NewFromString(tt.schema)
+		if err != nil {
+			t.Fatalf("test #%d: %s", i+1, err)
+		}
+		valid, err := schema.Validate([]byte(tt.doc))
+		if valid != tt.valid {
+			t.Fatalf("test #%d: expected %s to be valid against %s: %t, got: %t (%v)", i+1, tt.doc, tt.schema, tt.valid, valid, err)
+		}
+	}
+}
+
+func TestPlanProperties(t *testing.T) {
+	schema, err := NewFromString(`{
+		"properties": {"a": {"type": "string"}},
+		"patternProperties": {"^a": {"maxLength": 2}, "^b": {"type": "integer"}},
+		"additionalProperties": false
+	}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	docs := map[string]bool{
+		`{"a": "x", "ab": "xy", "b1": 1}`: true,
+		`{"a": 1}`:                        false,
+		`{"abc": "xyz"}`:                  false,
+		`{"b": "x"}`:                      false,
+		`{"c": 1}`:                        false,
+	}
+	for doc, expected := range docs {
+		if valid, err := schema.Validate([]byte(doc)); valid != expected {
+			t.Fatalf("expected %s to be valid: %t, got: %t (%v)", doc, expected, valid, err)
+		}
+	}
+}
+
+var benchmarkSchema = `{
+	"type": "object",
+	"required": ["id", "timestamp", "values"],
+	"properties": {
+		"id": {"type": "string", "maxLength": 64},
+		"source": {"type": "string"},
+		"region": {"type": "string"},
+		"host": {"type": "string"},
+		"service": {"type": "string"},
+		"version": {"type": "string"},
+		"level": {"enum": ["debug", "info", "warning", "error"]},
+		"timestamp": {"type": "integer", "minimum": 0},
+		"latency": {"type": "number", "minimum": 0, "maximum": 60000},
+		"values": {
+			"type": "array",
+			"maxItems": 1000,
+			"items": {"type": "number", "exclusiveMinimum": -1000000, "exclusiveMaximum": 1000000, "multipleOf": 0.5}
+		},
+		"counts": {
+			"type": "array",
+			"items": {"type": "integer", "minimum": 0, "maximum": 4294967295, "multipleOf": 2}
+		}
+	}
+}`
+
+func benchmarkDocument() []byte {
+	values := make([]string, 100)
+	counts := make([]string, 100)
+	for i := range values {
+		values[i] = fmt.Sprintf("%d.5", i*37-1000)
+		counts[i] = fmt.Sprint(i * 2000)
+	}
+	return []byte(fmt.Sprintf(`{
+		"id": "0c5b8e3c-5f6b-4cd8-9e1a-3f0c7a6b2d11",
+		"source": "ingest", "region": "eu-west-1", "host": "ingest-7", "service": "api", "version": "1.2.3",
+		"level": "info",
+		"timestamp": 1700000000000,
+		"latency": 12.75,
+		"values": [%s],
+		"counts": [%s]
+	}`, strings.Join(values, ","), strings.Join(counts, ",")))
+}
+
+func BenchmarkValidateNumbers(b *testing.B) {
+	schema, err := NewFromString(benchmarkSchema)
+	if err != nil {
+		b.Fatal(err)
+	}
+	doc := benchmarkDocument()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if valid, err := schema.Validate(doc); !valid {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkValidateProperties(b *testing.B) {
+	props := make([]string, 200)
+	members := make([]string, 200)
+	for i := range props {
+		props[i] = fmt.Sprintf(`"field%d": {"type": "string"}`, i)
+		members[i] = fmt.Sprintf(`"field%d": "value"`, i)
+	}
+	schema, err := NewFromString(`{"properties": {` + strings.Join(props, ",") + `}}`)
+	if err != nil {
+		b.Fatal(err)
+	}
+	doc := []byte(`{` + strings.Join(members, ",") + `}`)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if valid, err := schema.Validate(doc); !valid {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,192 @@
+package jsonschema
+
+import (
+	"regexp"
+)
+
+// Recompile rebuilds everything Parse derives from the keywords of a schema, for the whole tree
+// the schema is part of: validators, compiled patterns, the links between schemas, the ($)id
+// pointer tables and the $refs.
+// It must be called after changing the keywords of a parsed schema, e.g.:
+//
+//	maxLength := int64(10)
+//	prop, _ := schema.Properties.GetProperty("name")
+//	prop.Property.MaxLength = &maxLength
+//	err := schema.Recompile()
+//
+// Resolved $refs are forgotten, so they are resolved again, against the changed schemas.
+// Schemas added with AddSchema or loaded by the Loader are kept.
+// If a pattern doesn't compile, the error is returned and the schema validates as it did before.
+func (s *Schema) Recompile() error {
+	if s == nil {
+		return nil
+	}
+
+	root := s
+	if s.root != nil {
+		root = s.root
+	}
+
+	// Collect the tree depth first, with each schema after its parent
+	type entry struct {
+		schema *Schema
+		parent *Schema
+	}
+	entries := []entry{}
+	inTree := map[*Schema]bool{}
+	Walk(root, func(ptr string, sub, parent *Schema) error {
+		entries = append(entries, entry{schema: sub, parent: parent})
+		inTree[sub] = true
+		return nil
+	})
+
+	// The patterns are compiled before anything is changed, so an invalid pattern leaves
+	// the tree as it was, with validators that match its compiled patterns
+	type regexps struct {
+		pattern           *regexp.Regexp
+		patternProperties *map[string]*regexp.Regexp
+	}
+	compiled := map[*Schema]regexps{}
+	var errs error
+	for _, e := range entries {
+		schema := e.schema
+		if schema.boolean != nil {
+			continue
+		}
+
+		res := regexps{}
+		if schema.Pattern != nil {
+			re, err := regexp.Compile(convertRegexp(*schema.Pattern))
+			if err != nil {
+				errs = addError(err, errs)
+			} else {
+				res.pattern = re
+			}
+		}
+		if schema.PatternProperties != nil {
+			res.patternProperties = &map[string]*regexp.Regexp{}
+			for _, prop := range *schema.PatternProperties {
+				re, err := regexp.Compile(convertRegexp(prop.Name))
+				if err != nil {
+					errs = addError(err, errs)
+				} else {
+					(*res.patternProperties)[prop.Name] = re
+				}
+			}
+		}
+		compiled[schema] = res
+	}
+	if errs != nil {
+		return errs
+	}
+
+	// Keep the pointers and refs of schemas outside the tree, e.g. added with AddSchema
+	keepPointers := func(p *pointers) *pointers {
+		kept := pointers{}
+		if p != nil {
+			for key, schema := range *p {
+				if !inTree[schema] {
+					kept[key] = schema
+				}
+			}
+		}
+		return &kept
+	}
+	keptRefs := refs{}
+	if root.refs != nil {
+		for _, ref := range *root.refs {
+			if !inTree[ref.parent] {
+				keptRefs = append(keptRefs, ref)
+			}
+		}
+	}
+
+	for _, e := range entries {
+		schema, parent := e.schema, e.parent
+
+		schema.parent = parent
+		schema.subSchemas = nil
+		if parent == nil {
+			schema.root = nil
+			schema.base = nil
+			schema.pointers = keepPointers(schema.pointers)
+			schema.refs = &keptRefs
+		} else {
+			if parent.baseURI != nil || parent.base == nil {
+				schema.base = parent
+			} else {
+				schema.base = parent.base
+			}
+			if parent.root != nil {
+				schema.root = parent.root
+			} else {
+				schema.root = parent
+			}
+			// Only schemas with an absolute ($)id have pointer tables of their own
+			if schema.pointers != nil {
+				schema.pointers = keepPointers(schema.pointers)
+				if len(*schema.pointers) == 0 {
+					schema.pointers = nil
+				}
+			}
+		}
+
+		if schema.boolean != nil {
+			schema.setupValidators()
+			continue
+		}
+
+		if schema.Ref != nil {
+			schema.Ref.parent = schema
+			schema.Ref.Schema = nil
+			schema.Ref.marshalled = 0
+			schema.setRef(schema.Ref)
+		}
+
+		schema.baseURI = nil
+		if id := schema.GetID(); id != "" && schema.Ref == nil {
+			if id[:1] == "#" {
+				if parent != nil {
+					parent.setPointer(id, schema)
+				}
+			} else {
+				baseURI, err := parent.ExpandURI(id)
+				if err != nil {
+					errs = addError(err, errs)
+				} else {
+					schema.baseURI = baseURI
+					if schema.pointers == nil {
+						schema.pointers = &pointers{}
+					}
+					(*schema.pointers)["#"] = schema
+					if parent != nil {
+						parent.setPointer(baseURI.String(), schema)
+					} else {
+						schema.setPointer(baseURI.String(), schema)
+					}
+				}
+			}
+		}
+
+		schema.patternRegexp = compiled[schema].pattern
+		schema.patternPropertiesRegexps = compiled[schema].patternProperties
+
+		schema.setupValidators()
+	}
+
+	// The raw JSON is used to resolve pointers into unknown keywords and to marshal $refs,
+	// so it is rebuilt from the sub schemas up, keeping what hasn't changed as it was
+	for i := len(entries) - 1; i >= 0; i-- {
+		schema := entries[i].schema
+		n, err := losslessNode(schema)
+		if err != nil {
+			errs = addError(err, errs)
+			continue
+		}
+		schema.raw, _ = n.MarshalJSON()
+	}
+
+	root.parseRefTargets()
+
+	return errs
+}
@@ -0,0 +1,124 @@
+package jsonschema
+
+import (
+	"testing"
+)
+
+func TestRecompile(t *testing.T) {
+	schema, err := NewFromString(`{
+		"$id": "http://example.com/root.json",
+		"definitions": {"code": {"type": "string"}},
+		"properties": {
+			"name": {"type": "string"},
+			"code": {"$ref": "#/definitions/code"}
+		}
+	}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	doc := []byte(`{"name": "a long name", "code": "abc"}`)
+	if valid, err := schema.Validate(doc); !valid {
+		t.Fatalf("expected the document to be valid, got: %s", err)
+	}
+
+	// Change an existing keyword, add new keywords and a new sub schema
+	maxLength := int64(5)
+	name, _ := schema.Properties.GetProperty("name")
+	name.Property.MaxLength = &maxLength
+
+	required := Strings{NewStringPtr([]byte("name"))}
+	schema.Required = &required
+
+	pattern := "^[0-9]+$"
+	code, _ := schema.Definitions.GetProperty("code")
+	code.Property.Pattern = &pattern
+
+	typ := Type{String: NewStringPtr([]byte("integer"))}
+	*schema.Properties = append(*schema.Properties, &NamedProperty{Name: "age", Property: &Schema{Type: &typ}})
+
+	if err := schema.Recompile(); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		doc   string
+		valid bool
+	}{
+		{doc: `{"name": "short", "code": "123", "age": 1}`, valid: true},
+		{doc: `{"name": "a long name"}`},
+		{doc: `{"code": "123"}`},
+		{doc: `{"name": "short", "code": "abc"}`},
+		{doc: `{"name": "short", "age": "1"}`},
+	}
+	for _, test := range tests {
+		if valid, err := schema.Validate([]byte(test.doc)); valid != test.valid {
+			t.Errorf("expected %s to be valid: %t, got: %t (%v)", test.doc, test.valid, valid, err)
+		}
+	}
+
+	if schema.getPointer("http://example.com/root.json") != schema {
+		t.Errorf("expected the $id of the root to be registered again")
+	}
+
+	expected := `{"$id":"http://example.com/root.json","definitions":{"code":{"type":"string","pattern":"^[0-9]+$"}},` +
+		`"properties":{"name":{"type":"string","maxLength":5},"code":{"$ref":"#/definitions/code"},"age":{"type":"integer"}},` +
+		`"required":["name"]}`
+	if str := schema.String(); str != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, str)
+	}
+}
+
+func TestRecompileKeepsAddedSchemas(t *testing.T) {
+	schema, err := NewFromString(`{"properties": {"item": {"$ref": "http://example.com/item"}}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := schema.AddSchemaString(`{"$id": "http://example.com/item", "type": "string"}`); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := schema.Recompile(); err != nil {
+		t.Fatal(err)
+	}
+	if valid, _ := schema.Validate([]byte(`{"item": 1}`)); valid {
+		t.Errorf("expected the added schema to still be used")
+	}
+}
+
+func TestRecompileInvalidPattern(t *testing.T) {
+	schema, err := NewFromString(`{"type": "string"}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pattern := "(unclosed"
+	schema.Pattern = &pattern
+	if err := schema.Recompile(); err == nil {
+		t.Errorf("expected an invalid pattern to fail")
+	}
+
+	// The schema still validates as it did before the change
+	if valid, err := schema.Validate([]byte(`"x"`)); !valid {
+		t.Errorf("expected a string to be valid, got: %v", err)
+	}
+	if valid, _ := schema.Validate([]byte(`1`)); valid {
+		t.Errorf("expected a number to be invalid")
+	}
+
+	// It's the same for an invalid pattern property, next to a valid pattern
+	schema, err = NewFromString(`{"pattern": "^a", "properties": {"b": {"patternProperties": {"^c": {"type": "string"}}}}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	prop, _ := schema.Properties.GetProperty("b")
+	(*prop.Property.PatternProperties)[0].Name = "(unclosed"
+	if err := schema.Recompile(); err == nil {
+		t.Errorf("expected an invalid pattern property to fail")
+	}
+	if valid, _ := schema.Validate([]byte(`"b"`)); valid {
+		t.Errorf("expected the pattern to still be checked")
+	}
+	if valid, err := schema.Validate([]byte(`"a"`)); !valid {
+		t.Errorf("expected a matching string to be valid, got: %v", err)
+	}
+}
@@ -0,0 +1,521 @@
+package jsonschema
+
+import (
+	"encoding"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/buger/jsonparser"
+)
+
+var (
+	timeType          = reflect.TypeOf(time.Time{})
+	rawMessageType    = reflect.TypeOf(json.RawMessage{})
+	bigIntType        = reflect.TypeOf(big.Int{})
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// numericTagKeys are the keywords in jsonschema tags, that take a number
+var numericTagKeys = map[string]struct{}{
+	"minLength": {}, "maxLength": {}, "minimum": {}, "maximum": {}, "exclusiveMinimum": {}, "exclusiveMaximum": {},
+	"multipleOf": {}, "minItems": {}, "maxItems": {}, "minProperties": {}, "maxProperties": {},
+}
+
+// valueTagKeys are the keywords in jsonschema tags, that take a value of the same type as the field
+var valueTagKeys = map[string]struct{}{"default": {}, "const": {}}
+
+// stringTagKeys are the keywords in jsonschema tags, that take a string
+var stringTagKeys = map[string]struct{}{"title": {}, "description": {}, "format": {}, "pattern": {}}
+
+type reflector struct {
+	root reflect.Type
+
+	// names holds the name in $defs of each named struct type
+	names map[reflect.Type]string
+	used  map[string]struct{}
+	defs  *node
+}
+
+// Reflect creates a (draft 2020-12) schema from the Go type of v, matching what encoding/json
+// produces when marshalling v.
+// Property names are read from json tags and fields without omitempty are required.
+// Pointers, slices and maps are marshalled as null when nil, so they allow null, unless they're omitted.
+// Fields of embedded structs follow the rules of encoding/json, where outer and tagged fields win,
+// and fields of embedded pointers are optional.
+// Named struct types, other than the type of v itself, are added to $defs and referenced with $ref.
+// Constraints are set with a jsonschema tag, containing comma separated keyword=value pairs, e.g.:
+//
+//	Email string `json:"email" jsonschema:"minLength=1,format=email"`
+//	Kind  string `json:"kind" jsonschema:"enum=a|b"`
+//
+// The supported keywords are title, description, format, pattern, enum, const, default,
+// minLength, maxLength, minimum, maximum, exclusiveMinimum, exclusiveMaximum, multipleOf,
+// minItems, maxItems, uniqueItems, minProperties and maxProperties.
+// Values can't contain commas, and enum values are separated by |.
+func Reflect(v interface{}) (*Schema, error) {
+	if v == nil {
+		return nil, errors.New("unable to reflect nil")
+	}
+
+	t := reflect.TypeOf(v)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	r := &reflector{
+		root:  t,
+		names: map[reflect.Type]string{},
+		used:  map[string]struct{}{},
+		defs:  newObjectNode(),
+	}
+
+	var n *node
+	var err error
+	if t.Kind() == reflect.Struct {
+		n, err = r.reflectStruct(t)
+	} else {
+		n, err = r.reflectType(t)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	n.members = append([]*member{{key: "$schema", value: newStringNode(Draft2020_12.URI())}}, n.members...)
+	if len(r.defs.members) > 0 {
+		n.set("$defs", r.defs)
+	}
+
+	b, err := n.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+
+	return New(b)
+}
+
+// reflectType returns the schema for values of type t
+func (r *reflector) reflectType(t reflect.Type) (*node, error) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	n := newObjectNode()
+
+	switch t {
+	case timeType:
+		n.set("type", newStringNode("string"))
+		n.set("format", newStringNode("date-time"))
+		return n, nil
+	case rawMessageType:
+		return n, nil
+	case bigIntType:
+		n.set("type", newStringNode("integer"))
+		return n, nil
+	}
+
+	// Types with their own marshalling can't be described
+	if t.Implements(jsonMarshalerType) || reflect.PtrTo(t).Implements(jsonMarshalerType) {
+		return n, nil
+	}
+	if t.Implements(textMarshalerType) || reflect.PtrTo(t).Implements(textMarshalerType) {
+		n.set("type", newStringNode("string"))
+		return n, nil
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		n.set("type", newStringNode("boolean"))
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n.set("type", newStringNode("integer"))
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		n.set("type", newStringNode("integer"))
+		n.set("minimum", &node{typ: jsonparser.Number, raw: []byte("0")})
+
+	case reflect.Float32, reflect.Float64:
+		n.set("type", newStringNode("number"))
+
+	case reflect.String:
+		n.set("type", newStringNode("string"))
+
+	case reflect.Slice, reflect.Array:
+		// encoding/json writes []byte as a base64 encoded string
+		if t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8 {
+			n.set("type", newStringNode("string"))
+			n.set("contentEncoding", newStringNode("base64"))
+			return n, nil
+		}
+
+		items, err := r.reflectType(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		if isNilable(t.Elem()) {
+			items = nullable(items)
+		}
+		n.set("type", newStringNode("array"))
+		n.set("items", items)
+		if t.Kind() == reflect.Array {
+			length := &node{typ: jsonparser.Number, raw: []byte(strconv.Itoa(t.Len()))}
+			n.set("minItems", length)
+			n.set("maxItems", length)
+		}
+
+	case reflect.Map:
+		switch t.Key().Kind() {
+		case reflect.String, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		default:
+			if !t.Key().Implements(textMarshalerType) {
+				return nil, fmt.Errorf("unsupported map key type: %s", t.Key())
+			}
+		}
+
+		values, err := r.reflectType(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		if isNilable(t.Elem()) {
+			values = nullable(values)
+		}
+		n.set("type", newStringNode("object"))
+		if len(values.members) > 0 {
+			n.set("additionalProperties", values)
+		}
+
+	case reflect.Struct:
+		if t == r.root {
+			ref := newObjectNode()
+			ref.set("$ref", newStringNode("#"))
+			return ref, nil
+		}
+		if t.Name() == "" {
+			return r.reflectStruct(t)
+		}
+		return r.reflectNamed(t)
+
+	case reflect.Interface:
+		// Anything goes
+
+	default:
+		return nil, fmt.Errorf("unsupported type: %s", t)
+	}
+
+	return n, nil
+}
+
+// reflectNamed adds the named struct type t to $defs, if it's not there already, and returns a $ref to it
+func (r *reflector) reflectNamed(t reflect.Type) (*node, error) {
+	name, ok := r.names[t]
+	if !ok {
+		name = t.Name()
+		for i := 2; ; i++ {
+			if _, ok := r.used[name]; !ok {
+				break
+			}
+			name = t.Name() + strconv.Itoa(i)
+		}
+		r.used[name] = struct{}{}
+		r.names[t] = name
+
+		// Reserve the position before reflecting, as the type may reference itself
+		def := newObjectNode()
+		r.defs.set(name, def)
+
+		n, err := r.reflectStruct(t)
+		if err != nil {
+			return nil, err
+		}
+		*def = *n
+	}
+
+	ref := newObjectNode()
+	ref.set("$ref", newStringNode("#/$defs/"+escapePointerToken(name)))
+	return ref, nil
+}
+
+// reflectStruct returns the schema for the struct type t
+func (r *reflector) reflectStruct(t reflect.Type) (*node, error) {
+	n := newObjectNode()
+	props := newObjectNode()
+	required := []*node{}
+
+	fields, err := r.reflectFields(t, 0, map[reflect.Type]bool{})
+	if err != nil {
+		return nil, err
+	}
+	for i, field := range fields {
+		if dominantField(fields, field.name) != i {
+			continue
+		}
+		props.set(field.name, field.prop)
+		if field.required {
+			required = append(required, newStringNode(field.name))
+		}
+	}
+
+	n.set("type", newStringNode("object"))
+	n.set("properties", props)
+	if len(required) > 0 {
+		n.set("required", &node{typ: jsonparser.Array, items: required})
+	}
+	n.set("additionalProperties", newBoolNode(false))
+
+	return n, nil
+}
+
+// reflectedField is a field of a struct, or of a struct embedded in it, as marshalled by encoding/json
+type reflectedField struct {
+	name     string
+	prop     *node
+	required bool
+
+	// depth is the number of embedded structs the field is in, and tagged tells whether the
+	// name is from the json tag, which decide the field used, when more fields have the same name
+	depth  int
+	tagged bool
+}
+
+// reflectFields returns the fields of t, including fields of embedded structs, in the order encoding/json marshals them.
+// visited holds the embedded structs t is in, which are skipped, if they are embedded again.
+func (r *reflector) reflectFields(t reflect.Type, depth int, visited map[reflect.Type]bool) ([]reflectedField, error) {
+	visited[t] = true
+	defer delete(visited, t)
+
+	fields := []reflectedField{}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+
+		name, opts := tag, ""
+		if idx := strings.Index(tag, ","); idx >= 0 {
+			name, opts = tag[:idx], tag[idx+1:]
+		}
+
+		fieldType := field.Type
+		for fieldType.Kind() == reflect.Ptr {
+			fieldType = fieldType.Elem()
+		}
+
+		if field.Anonymous && name == "" && fieldType.Kind() == reflect.Struct {
+			if visited[fieldType] {
+				continue
+			}
+			embedded, err := r.reflectFields(fieldType, depth+1, visited)
+			if err != nil {
+				return nil, err
+			}
+			if field.Type.Kind() == reflect.Ptr {
+				// The fields of a nil embedded struct are left out
+				for i := range embedded {
+					embedded[i].required = false
+				}
+			}
+			fields = append(fields, embedded...)
+			continue
+		}
+		if field.PkgPath != "" {
+			// Unexported
+			continue
+		}
+		tagged := name != ""
+		if name == "" {
+			name = field.Name
+		}
+
+		prop, err := r.reflectType(field.Type)
+		if err != nil {
+			return nil, fmt.Errorf("%s.%s: %w", t.Name(), field.Name, err)
+		}
+
+		omitEmpty := false
+		for _, opt := range strings.Split(opts, ",") {
+			switch opt {
+			case "omitempty":
+				omitEmpty = true
+			case "string":
+				switch fieldType.Kind() {
+				case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+					reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+					reflect.Float32, reflect.Float64:
+					prop = newObjectNode()
+					prop.set("type", newStringNode("string"))
+				}
+			}
+		}
+
+		if schemaTag, ok := field.Tag.Lookup("jsonschema"); ok {
+			prop, err = applySchemaTag(prop, schemaTag, fieldType)
+			if err != nil {
+				return nil, fmt.Errorf("%s.%s: %w", t.Name(), field.Name, err)
+			}
+		}
+
+		if !omitEmpty && isNilable(field.Type) {
+			prop = nullable(prop)
+		}
+		fields = append(fields, reflectedField{name: name, prop: prop, required: !omitEmpty, depth: depth, tagged: tagged})
+	}
+
+	return fields, nil
+}
+
+// dominantField returns the index of the field encoding/json marshals for name, or -1 if none is.
+// Like encoding/json, the least embedded field wins, then a field named by its json tag,
+// and if that leaves more fields, they are all left out.
+func dominantField(fields []reflectedField, name string) int {
+	dominant, ambiguous := -1, false
+	for i, field := range fields {
+		if field.name != name {
+			continue
+		}
+		switch {
+		case dominant < 0 || field.depth < fields[dominant].depth:
+			dominant, ambiguous = i, false
+		case field.depth > fields[dominant].depth:
+		case field.tagged && !fields[dominant].tagged:
+			dominant, ambiguous = i, false
+		case field.tagged == fields[dominant].tagged:
+			ambiguous = true
+		}
+	}
+	if ambiguous {
+		return -1
+	}
+	return dominant
+}
+
+// isNilable tells whether encoding/json marshals the zero value of t as null
+func isNilable(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Ptr, reflect.Slice, reflect.Map:
+		return true
+	}
+	return false
+}
+
+// nullable returns the schema n, that also allows null
+func nullable(n *node) *node {
+	if len(n.members) == 0 {
+		// Allows anything already
+		return n
+	}
+
+	if typ, ok := n.get("type").str(); ok && !n.has("enum") && !n.has("const") {
+		n.set("type", &node{typ: jsonparser.Array, items: []*node{newStringNode(typ), newStringNode("null")}})
+		return n
+	}
+
+	null := newObjectNode()
+	null.set("type", newStringNode("null"))
+	anyOf := newObjectNode()
+	anyOf.set("anyOf", &node{typ: jsonparser.Array, items: []*node{n, null}})
+	return anyOf
+}
+
+// applySchemaTag adds the constraints from a jsonschema tag to prop
+func applySchemaTag(prop *node, tag string, t reflect.Type) (*node, error) {
+	constraints := newObjectNode()
+
+	for _, pair := range strings.Split(tag, ",") {
+		if pair == "" {
+			continue
+		}
+
+		key, value := pair, ""
+		if idx := strings.Index(pair, "="); idx >= 0 {
+			key, value = pair[:idx], pair[idx+1:]
+		}
+
+		_, isNumeric := numericTagKeys[key]
+		_, isValue := valueTagKeys[key]
+		_, isString := stringTagKeys[key]
+
+		switch {
+		case isNumeric:
+			if _, err := strconv.ParseFloat(value, 64); err != nil {
+				return nil, fmt.Errorf("invalid value for %s: %s", key, value)
+			}
+			constraints.set(key, &node{typ: jsonparser.Number, raw: []byte(value)})
+
+		case isValue:
+			val, err := tagValue(value, t)
+			if err != nil {
+				return nil, fmt.Errorf("invalid value for %s: %w", key, err)
+			}
+			constraints.set(key, val)
+
+		case isString:
+			if key == "pattern" {
+				if _, err := regexp.Compile(value); err != nil {
+					return nil, fmt.Errorf("invalid pattern: %w", err)
+				}
+			}
+			constraints.set(key, newStringNode(value))
+
+		case key == "enum":
+			enum := &node{typ: jsonparser.Array, items: []*node{}}
+			for _, str := range strings.Split(value, "|") {
+				val, err := tagValue(str, t)
+				if err != nil {
+					return nil, fmt.Errorf("invalid value for enum: %w", err)
+				}
+				enum.items = append(enum.items, val)
+			}
+			constraints.set(key, enum)
+
+		case key == "uniqueItems":
+			constraints.set(key, newBoolNode(value == "" || value == "true"))
+
+		default:
+			return nil, fmt.Errorf("unsupported keyword in jsonschema tag: %s", key)
+		}
+	}
+
+	// Keywords next to $ref are ignored by earlier drafts, so they're combined with allOf instead
+	if prop.has("$ref") {
+		allOf := &node{typ: jsonparser.Array, items: []*node{prop}}
+		constraints.members = append([]*member{{key: "allOf", value: allOf}}, constraints.members...)
+		return constraints, nil
+	}
+
+	for _, m := range constraints.members {
+		prop.set(m.key, m.value)
+	}
+	return prop, nil
+}
+
+// tagValue converts a value from a jsonschema tag, to a value of the same JSON type as t
+func tagValue(value string, t reflect.Type) (*node, error) {
+	switch t.Kind() {
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, err
+		}
+		return newBoolNode(b), nil
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		if _, err := strconv.ParseFloat(value, 64); err != nil {
+			return nil, err
+		}
+		return &node{typ: jsonparser.Number, raw: []byte(value)}, nil
+	}
+
+	return newStringNode(value), nil
+}
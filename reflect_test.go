@@ -0,0 +1,186 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+	"time"
+)
+
+type reflectAddress struct {
+	Street string          `json:"street" jsonschema:"minLength=1"`
+	Next   *reflectAddress `json:"next,omitempty"`
+}
+
+type reflectBase struct {
+	ID int64 `json:"id"`
+}
+
+type reflectUser struct {
+	reflectBase
+	Email    string           `json:"email" jsonschema:"format=email"`
+	Kind     string           `json:"kind,omitempty" jsonschema:"enum=admin|user"`
+	Age      uint8            `json:"age,omitempty" jsonschema:"maximum=150"`
+	Created  time.Time        `json:"created"`
+	Extra    json.RawMessage  `json:"extra,omitempty"`
+	Balance  *big.Int         `json:"balance,omitempty"`
+	Home     reflectAddress   `json:"home" jsonschema:"description=Where they live"`
+	Previous []reflectAddress `json:"previous,omitempty"`
+	Labels   map[string]int   `json:"labels,omitempty"`
+	Ignored  string           `json:"-"`
+	internal string
+}
+
+func TestReflect(t *testing.T) {
+	s, err := Reflect(&reflectUser{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `{"$schema":"https://json-schema.org/draft/2020-12/schema","type":"object","$defs":{"reflectAddress":{"type":"object","properties":{"street":{"type":"string","minLength":1},"next":{"$ref":"#/$defs/reflectAddress"}},"required":["street"],"additionalProperties":false}},"properties":{"id":{"type":"integer"},"email":{"type":"string","format":"email"},"kind":{"type":"string","enum":["admin","user"]},"age":{"type":"integer","maximum":150,"minimum":0},"created":{"type":"string","format":"date-time"},"extra":{},"balance":{"type":"integer"},"home":{"description":"Where they live","allOf":[{"$ref":"#/$defs/reflectAddress"}]},"previous":{"type":"array","items":{"$ref":"#/$defs/reflectAddress"}},"labels":{"type":"object","additionalProperties":{"type":"integer"}}},"required":["id","email","created","home"],"additionalProperties":false}`
+	if s.String() != expected {
+		t.Fatalf("expected reflected schema to match:\n%s\ngot:\n%s\n", expected, s.String())
+	}
+
+	valid, err := json.Marshal(reflectUser{
+		Email:   "a@example.com",
+		Kind:    "admin",
+		Balance: big.NewInt(10),
+		Home:    reflectAddress{Street: "Main St", Next: &reflectAddress{Street: "Side St"}},
+		Labels:  map[string]int{"a": 1},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Validate(valid); err != nil {
+		t.Fatalf("expected %s to be valid, got: %s", valid, err)
+	}
+
+	invalid := []string{
+		`{"id":1,"email":"a@example.com","created":"2020-01-01T00:00:00Z","home":{"street":""}}`,
+		`{"id":1,"email":"a@example.com","created":"2020-01-01T00:00:00Z","home":{"street":"a"},"kind":"guest"}`,
+		`{"id":1,"email":"a@example.com","created":"2020-01-01T00:00:00Z"}`,
+		`{"id":1,"email":"a@example.com","created":"2020-01-01T00:00:00Z","home":{"street":"a"},"unknown":true}`,
+	}
+	for _, doc := range invalid {
+		if _, err := s.Validate([]byte(doc)); err == nil {
+			t.Fatalf("expected %s to be invalid", doc)
+		}
+	}
+}
+
+type reflectNilable struct {
+	Amount   *big.Float              `json:"amount"`
+	Count    *int                    `json:"count"`
+	Kind     *string                 `json:"kind" jsonschema:"enum=a|b"`
+	Tags     []string                `json:"tags"`
+	Data     []byte                  `json:"data"`
+	Labels   map[string]*int         `json:"labels"`
+	Scores   []*float64              `json:"scores"`
+	Home     *reflectAddress         `json:"home"`
+	Parent   *reflectNilable         `json:"parent"`
+	Children map[string]interface{}  `json:"children"`
+	Optional *reflectAddress         `json:"optional,omitempty"`
+	Created  time.Time               `json:"created"`
+	Nested   struct{ Value *string } `json:"nested"`
+}
+
+func TestReflectMarshalledValues(t *testing.T) {
+	s, err := Reflect(reflectNilable{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	one, kind, half := 1, "a", 0.5
+	values := []reflectNilable{
+		{},
+		{
+			Amount:   big.NewFloat(1.5),
+			Count:    &one,
+			Kind:     &kind,
+			Tags:     []string{},
+			Data:     []byte("abc"),
+			Labels:   map[string]*int{"a": &one, "b": nil},
+			Scores:   []*float64{&half, nil},
+			Home:     &reflectAddress{Street: "Main St"},
+			Parent:   &reflectNilable{},
+			Children: map[string]interface{}{"a": nil},
+		},
+	}
+
+	for _, v := range values {
+		doc, err := json.Marshal(&v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := s.Validate(doc); err != nil {
+			t.Errorf("expected %s to be valid, got: %s", doc, err)
+		}
+	}
+
+	// *big.Float is marshalled as a string by encoding/json
+	amount, _ := s.Properties.GetProperty("amount")
+	if typ, _ := json.Marshal(amount.Property.Type); string(typ) != `["string","null"]` {
+		t.Errorf(`expected amount to have the type ["string","null"], got: %s`, typ)
+	}
+}
+
+type reflectShadowBase struct {
+	ID    int    `json:"id"`
+	Name  string `json:"name"`
+	Title string
+}
+
+type reflectShadowOther struct {
+	Name  string `json:"name"`
+	Title string `json:"Title"`
+}
+
+type reflectShadow struct {
+	reflectShadowBase
+	*reflectShadowOther
+	ID string `json:"id"`
+}
+
+func TestReflectShadowedFields(t *testing.T) {
+	s, err := Reflect(reflectShadow{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The outer id shadows the embedded one, the two names at the same depth cancel each other out,
+	// and the tagged Title wins over the untagged one, but is left out when the pointer is nil
+	expected := `{"$schema":"https://json-schema.org/draft/2020-12/schema","type":"object","properties":{"Title":{"type":"string"},"id":{"type":"string"}},"required":["id"],"additionalProperties":false}`
+	if s.String() != expected {
+		t.Fatalf("expected reflected schema to match:\n%s\ngot:\n%s\n", expected, s.String())
+	}
+
+	for _, v := range []reflectShadow{{ID: "x"}, {reflectShadowOther: &reflectShadowOther{Title: "a"}, ID: "x"}} {
+		doc, err := json.Marshal(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := s.Validate(doc); err != nil {
+			t.Errorf("expected %s to be valid, got: %s", doc, err)
+		}
+	}
+	if _, err := s.Validate([]byte(`{"id":1}`)); err == nil {
+		t.Errorf("expected the embedded id to be shadowed")
+	}
+}
+
+func TestReflectInvalidTag(t *testing.T) {
+	type withTag struct {
+		Name string `json:"name" jsonschema:"minLength=short"`
+	}
+	if _, err := Reflect(withTag{}); err == nil {
+		t.Fatal("expected an error for an invalid minLength")
+	}
+
+	type withUnknown struct {
+		Name string `json:"name" jsonschema:"unknown=1"`
+	}
+	if _, err := Reflect(withUnknown{}); err == nil {
+		t.Fatal("expected an error for an unknown keyword")
+	}
+}
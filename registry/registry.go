@@ -0,0 +1,266 @@
+// Package registry is a schema registry, that keeps versions of schemas in a directory.
+//
+// New versions must be valid against their meta-schema, where one is bundled, as well as compatible
+// with the latest version of the subject. Schemas can be fetched by subject and version, by $id or by
+// the path of their $id, so the registry can serve $refs, e.g. as the host of http://localhost:8080/person.json.
+package registry
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/flowstack/go-jsonschema"
+)
+
+// maxSchemaSize limits the size of uploaded schemas
+const maxSchemaSize = 1 << 20
+
+// Registry stores versions of schemas in a directory and serves them over HTTP
+type Registry struct {
+	store *store
+
+	// compatibility is the rule new versions must follow, or 0 if any change is accepted
+	compatibility jsonschema.CompatibilityMode
+}
+
+// Open opens the registry stored in dir, creating dir if it doesn't exist.
+// New versions must have the compatibility given with the latest version, or 0 to accept any change.
+func Open(dir string, compatibility jsonschema.CompatibilityMode) (*Registry, error) {
+	st, err := openStore(dir)
+	if err != nil {
+		return nil, err
+	}
+	return &Registry{store: st, compatibility: compatibility}, nil
+}
+
+// ParseCompatibility parses the name of a compatibility rule: none, backward, forward or full
+func ParseCompatibility(name string) (jsonschema.CompatibilityMode, error) {
+	switch name {
+	case "none":
+		return 0, nil
+	case "backward":
+		return jsonschema.Backward, nil
+	case "forward":
+		return jsonschema.Forward, nil
+	case "full":
+		return jsonschema.Full, nil
+	default:
+		return 0, fmt.Errorf("unknown compatibility: %s", name)
+	}
+}
+
+type errorResponse struct {
+	Error  string   `json:"error"`
+	Issues []string `json:"issues,omitempty"`
+}
+
+// ServeHTTP routes the requests:
+//
+//	GET /subjects                           list subjects
+//	GET /subjects/{subject}/versions        list the versions of a subject
+//	GET /subjects/{subject}/versions/{n}    get a version, n can be latest
+//	PUT /subjects/{subject}                 add a new version
+//	GET /schemas?id={$id}                   get a schema by $id
+//	GET /{path}                             get the schema, which $id has the path
+func (reg *Registry) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+
+	switch {
+	case r.URL.Path == "/subjects" || r.URL.Path == "/subjects/":
+		if !allowMethods(w, r, http.MethodGet) {
+			return
+		}
+		writeJSON(w, http.StatusOK, reg.store.subjects())
+
+	case parts[0] == "subjects" && len(parts) == 2:
+		if !allowMethods(w, r, http.MethodPut) {
+			return
+		}
+		reg.put(w, r, parts[1])
+
+	case parts[0] == "subjects" && len(parts) == 3 && parts[2] == "versions":
+		if !allowMethods(w, r, http.MethodGet) {
+			return
+		}
+		versions, err := reg.store.list(parts[1])
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, versions)
+
+	case parts[0] == "subjects" && len(parts) == 4 && parts[2] == "versions":
+		if !allowMethods(w, r, http.MethodGet) {
+			return
+		}
+		n := 0
+		if parts[3] != "latest" {
+			var err error
+			n, err = strconv.Atoi(parts[3])
+			if err != nil || n < 1 {
+				writeJSON(w, http.StatusBadRequest, errorResponse{Error: "invalid version: " + parts[3]})
+				return
+			}
+		}
+		data, n, err := reg.store.get(parts[1], n)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		writeSchema(w, data, Version{Subject: parts[1], Version: n})
+
+	case r.URL.Path == "/schemas":
+		if !allowMethods(w, r, http.MethodGet) {
+			return
+		}
+		data, v, err := reg.store.byID(r.URL.Query().Get("id"))
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		writeSchema(w, data, v)
+
+	default:
+		if !allowMethods(w, r, http.MethodGet) {
+			return
+		}
+		data, v, err := reg.store.byPath(r.URL.Path)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		writeSchema(w, data, v)
+	}
+}
+
+func (reg *Registry) put(w http.ResponseWriter, r *http.Request, subject string) {
+	data, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, maxSchemaSize))
+	if err != nil {
+		writeJSON(w, http.StatusRequestEntityTooLarge, errorResponse{Error: err.Error()})
+		return
+	}
+
+	v, created, err := reg.Add(subject, data)
+
+	var incompatible *IncompatibleError
+	var invalid *requestError
+	switch {
+	case errors.As(err, &incompatible):
+		issues := []string{}
+		for _, issue := range incompatible.Issues {
+			issues = append(issues, issue.String())
+		}
+		writeJSON(w, http.StatusConflict, errorResponse{Error: incompatible.Error(), Issues: issues})
+	case errors.As(err, &invalid):
+		writeJSON(w, invalid.status, errorResponse{Error: invalid.Error()})
+	case err != nil:
+		writeError(w, err)
+	case created:
+		log.Printf("added %s version %d", v.Subject, v.Version)
+		writeJSON(w, http.StatusCreated, v)
+	default:
+		writeJSON(w, http.StatusOK, v)
+	}
+}
+
+// Add stores the schema as the next version of subject, unless it's identical to the latest version,
+// in which case that version is returned and created is false.
+// The schema must be valid against the meta-schema of its draft, if there is one, see jsonschema.MetaSchema,
+// and compatible with the latest version, otherwise an *IncompatibleError is returned.
+func (reg *Registry) Add(subject string, data []byte) (v Version, created bool, err error) {
+	schema, err := jsonschema.New(data)
+	if err != nil {
+		return Version{}, false, &requestError{status: http.StatusBadRequest, msg: "invalid schema: " + err.Error()}
+	}
+	meta, err := jsonschema.MetaSchema(schema.Draft())
+	if err != nil {
+		log.Printf("%s: %s, so the schema isn't checked against it", subject, err)
+	} else if _, err := meta.Validate(data); err != nil {
+		return Version{}, false, &requestError{
+			status: http.StatusUnprocessableEntity,
+			msg:    "schema is not valid against its meta-schema: " + err.Error(),
+		}
+	}
+
+	return reg.store.add(subject, data, func(latest []byte) error {
+		return reg.checkCompatibility(latest, schema)
+	})
+}
+
+// IncompatibleError is returned when a new version isn't compatible with the latest version
+type IncompatibleError struct {
+	Mode   jsonschema.CompatibilityMode
+	Issues []jsonschema.Incompatibility
+}
+
+func (e *IncompatibleError) Error() string {
+	return fmt.Sprintf("schema is not %s compatible with the latest version", e.Mode)
+}
+
+// checkCompatibility checks the new schema against the latest version, using the configured rule
+func (reg *Registry) checkCompatibility(latest []byte, schema *jsonschema.Schema) error {
+	if latest == nil || reg.compatibility == 0 {
+		return nil
+	}
+
+	old, err := jsonschema.New(latest)
+	if err != nil {
+		return err
+	}
+
+	issues, err := jsonschema.CheckCompatibility(old, schema, reg.compatibility)
+	if err != nil {
+		return err
+	}
+	if len(issues) > 0 {
+		return &IncompatibleError{Mode: reg.compatibility, Issues: issues}
+	}
+	return nil
+}
+
+func allowMethods(w http.ResponseWriter, r *http.Request, methods ...string) bool {
+	for _, method := range methods {
+		if r.Method == method || (r.Method == http.MethodHead && method == http.MethodGet) {
+			return true
+		}
+	}
+	w.Header().Set("Allow", strings.Join(methods, ", "))
+	writeJSON(w, http.StatusMethodNotAllowed, errorResponse{Error: "method not allowed"})
+	return false
+}
+
+func writeSchema(w http.ResponseWriter, data []byte, v Version) {
+	w.Header().Set("Content-Type", "application/schema+json")
+	w.Header().Set("X-Schema-Subject", v.Subject)
+	w.Header().Set("X-Schema-Version", strconv.Itoa(v.Version))
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write(data); err != nil {
+		log.Println(err)
+	}
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	if err == errNotFound {
+		writeJSON(w, http.StatusNotFound, errorResponse{Error: "not found"})
+		return
+	}
+	log.Println(err)
+	writeJSON(w, http.StatusInternalServerError, errorResponse{Error: "internal server error"})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		log.Println(err)
+	}
+}
@@ -0,0 +1,155 @@
+package registry
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/flowstack/go-jsonschema"
+)
+
+func request(t *testing.T, handler http.Handler, method, path, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(method, path, strings.NewReader(body)))
+	return rec
+}
+
+func TestRegistry(t *testing.T) {
+	dir := t.TempDir()
+	reg, err := Open(dir, jsonschema.Backward)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	person := `{"$id":"http://localhost:8080/person.json","properties":{"name":{"type":"string"}}}`
+	personV2 := `{"$id":"http://localhost:8080/person.json","title":"Person","properties":{"name":{"type":"string"}}}`
+
+	tests := []struct {
+		method   string
+		path     string
+		body     string
+		status   int
+		expected string
+	}{
+		{method: "PUT", path: "/subjects/person", body: person, status: http.StatusCreated, expected: `{"subject":"person","version":1,"id":"http://localhost:8080/person.json"}`},
+		{method: "PUT", path: "/subjects/person", body: person, status: http.StatusOK, expected: `{"subject":"person","version":1,"id":"http://localhost:8080/person.json"}`},
+		{method: "PUT", path: "/subjects/person", body: personV2, status: http.StatusCreated, expected: `{"subject":"person","version":2,"id":"http://localhost:8080/person.json"}`},
+		{method: "PUT", path: "/subjects/person", body: `{"$id":"http://localhost:8080/person.json","properties":{"name":{"type":"integer"}}}`, status: http.StatusConflict},
+		{method: "PUT", path: "/subjects/other", body: person, status: http.StatusUnprocessableEntity},
+		{method: "PUT", path: "/subjects/other", body: `{"type":12}`, status: http.StatusUnprocessableEntity},
+		{method: "PUT", path: "/subjects/new", body: `{"$schema":"https://json-schema.org/draft/2020-12/schema","$defs":{"a":{"type":"string"}}}`, status: http.StatusCreated},
+		{method: "GET", path: "/subjects", status: http.StatusOK, expected: `["new","person"]`},
+		{method: "GET", path: "/subjects/person/versions", status: http.StatusOK, expected: `[1,2]`},
+		{method: "GET", path: "/subjects/person/versions/1", status: http.StatusOK, expected: person},
+		{method: "GET", path: "/subjects/person/versions/latest", status: http.StatusOK, expected: personV2},
+		{method: "GET", path: "/subjects/person/versions/3", status: http.StatusNotFound},
+		{method: "GET", path: "/subjects/person/versions/first", status: http.StatusBadRequest},
+		{method: "GET", path: "/schemas?id=http://localhost:8080/person.json", status: http.StatusOK, expected: personV2},
+		{method: "GET", path: "/person.json", status: http.StatusOK, expected: personV2},
+		{method: "GET", path: "/missing.json", status: http.StatusNotFound},
+		{method: "DELETE", path: "/subjects/person/versions/1", status: http.StatusMethodNotAllowed},
+	}
+
+	for _, tt := range tests {
+		rec := request(t, reg, tt.method, tt.path, tt.body)
+		if rec.Code != tt.status {
+			t.Fatalf("%s %s: expected status %d, got: %d %s", tt.method, tt.path, tt.status, rec.Code, rec.Body)
+		}
+		if tt.expected != "" && strings.TrimSpace(rec.Body.String()) != tt.expected {
+			t.Fatalf("%s %s: expected:\n%s\ngot:\n%s", tt.method, tt.path, tt.expected, rec.Body)
+		}
+	}
+
+	// The versions are read from the directory, when it's opened again
+	reg, err = Open(dir, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rec := request(t, reg, "GET", "/person.json", ""); strings.TrimSpace(rec.Body.String()) != personV2 {
+		t.Errorf("expected the latest version after reopening, got: %s", rec.Body)
+	}
+}
+
+func TestRegistryIncompatibleIssues(t *testing.T) {
+	reg, err := Open(t.TempDir(), jsonschema.Backward)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := reg.Add("a", []byte(`{"type":"string"}`)); err != nil {
+		t.Fatal(err)
+	}
+
+	rec := request(t, reg, "PUT", "/subjects/a", `{"type":"integer"}`)
+	if rec.Code != http.StatusConflict {
+		t.Fatalf("expected status %d, got: %d", http.StatusConflict, rec.Code)
+	}
+	res := errorResponse{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &res); err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Issues) == 0 {
+		t.Errorf("expected the incompatibilities to be listed, got: %s", rec.Body)
+	}
+}
+
+func TestRegistrySubjectTraversal(t *testing.T) {
+	parent := t.TempDir()
+	dir := filepath.Join(parent, "store")
+	reg, err := Open(dir, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, subject := range []string{"..", ".", "...", ".hidden", "a/..", "%2e%2e", "..%2f..", "-"} {
+		rec := request(t, reg, "PUT", "/subjects/"+subject, `{"type":"string"}`)
+		if rec.Code != http.StatusBadRequest && rec.Code != http.StatusNotFound && rec.Code != http.StatusMethodNotAllowed {
+			t.Errorf("%s: expected the subject to be rejected, got: %d %s", subject, rec.Code, rec.Body)
+		}
+	}
+
+	if _, _, err := reg.Add("..", []byte(`{"type":"string"}`)); err == nil {
+		t.Errorf("expected .. to be rejected")
+	}
+
+	if _, err := os.Stat(filepath.Join(parent, "1.json")); !os.IsNotExist(err) {
+		t.Errorf("expected nothing to be written outside the store, got: %v", err)
+	}
+	if subjects := reg.store.subjects(); len(subjects) != 0 {
+		t.Errorf("expected no subjects, got: %v", subjects)
+	}
+}
+
+func TestRegistryStoreError(t *testing.T) {
+	dir := t.TempDir()
+	reg, err := Open(dir, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A file, where the directory of the subject should be, makes storing fail
+	if err := ioutil.WriteFile(filepath.Join(dir, "blocked"), []byte("x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if rec := request(t, reg, "PUT", "/subjects/blocked", `{"type":"string"}`); rec.Code != http.StatusInternalServerError {
+		t.Errorf("expected status %d, got: %d %s", http.StatusInternalServerError, rec.Code, rec.Body)
+	}
+}
+
+func TestParseCompatibility(t *testing.T) {
+	for name, expected := range map[string]jsonschema.CompatibilityMode{
+		"none": 0, "backward": jsonschema.Backward, "forward": jsonschema.Forward, "full": jsonschema.Full,
+	} {
+		if mode, err := ParseCompatibility(name); err != nil || mode != expected {
+			t.Errorf("%s: expected %v, got: %v (%v)", name, expected, mode, err)
+		}
+	}
+	if _, err := ParseCompatibility("sideways"); err == nil {
+		t.Errorf("expected an error for an unknown compatibility")
+	}
+}
@@ -0,0 +1,268 @@
+package registry
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/buger/jsonparser"
+)
+
+// subjectPattern matches valid subjects. The first character can't be a dot, so subjects like .. can't
+// point outside the store.
+var subjectPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+var errNotFound = errors.New("not found")
+
+// Version identifies a stored schema
+type Version struct {
+	Subject string `json:"subject"`
+	Version int    `json:"version"`
+	ID      string `json:"id,omitempty"`
+}
+
+// requestError is an error caused by the schema or subject given, rather than by the store
+type requestError struct {
+	status int
+	msg    string
+}
+
+func (e *requestError) Error() string {
+	return e.msg
+}
+
+// store keeps every version of every subject as dir/<subject>/<version>.json.
+// Versions are never changed or deleted, once they're stored.
+type store struct {
+	dir string
+
+	mu       sync.RWMutex
+	versions map[string][]int
+	ids      map[string]Version
+}
+
+func openStore(dir string) (*store, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	st := &store{
+		dir:      dir,
+		versions: map[string][]int{},
+		ids:      map[string]Version{},
+	}
+
+	subjects, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	for _, subject := range subjects {
+		if !subject.IsDir() || !subjectPattern.MatchString(subject.Name()) {
+			continue
+		}
+
+		files, err := ioutil.ReadDir(filepath.Join(dir, subject.Name()))
+		if err != nil {
+			return nil, err
+		}
+		for _, file := range files {
+			n, err := strconv.Atoi(strings.TrimSuffix(file.Name(), ".json"))
+			if err != nil || n < 1 || file.IsDir() || filepath.Ext(file.Name()) != ".json" {
+				continue
+			}
+			st.versions[subject.Name()] = append(st.versions[subject.Name()], n)
+
+			data, err := st.read(subject.Name(), n)
+			if err != nil {
+				return nil, err
+			}
+			st.index(data, Version{Subject: subject.Name(), Version: n})
+		}
+		sort.Ints(st.versions[subject.Name()])
+	}
+
+	return st, nil
+}
+
+// index makes the schema available by its $id, if it has one
+func (st *store) index(data []byte, v Version) {
+	id := schemaID(data)
+	if id == "" {
+		return
+	}
+	v.ID = id
+	st.ids[id] = v
+}
+
+func (st *store) subjects() []string {
+	st.mu.RLock()
+	defer st.mu.RUnlock()
+
+	subjects := []string{}
+	for subject := range st.versions {
+		subjects = append(subjects, subject)
+	}
+	sort.Strings(subjects)
+	return subjects
+}
+
+func (st *store) list(subject string) ([]int, error) {
+	st.mu.RLock()
+	defer st.mu.RUnlock()
+
+	versions, ok := st.versions[subject]
+	if !ok {
+		return nil, errNotFound
+	}
+	return append([]int{}, versions...), nil
+}
+
+// get returns the schema stored as subject and version. Version 0 is the latest version.
+func (st *store) get(subject string, n int) ([]byte, int, error) {
+	st.mu.RLock()
+	defer st.mu.RUnlock()
+
+	return st.getLocked(subject, n)
+}
+
+func (st *store) getLocked(subject string, n int) ([]byte, int, error) {
+	versions, ok := st.versions[subject]
+	if !ok || len(versions) == 0 {
+		return nil, 0, errNotFound
+	}
+	if n == 0 {
+		n = versions[len(versions)-1]
+	}
+
+	data, err := st.read(subject, n)
+	if os.IsNotExist(err) {
+		return nil, 0, errNotFound
+	}
+	return data, n, err
+}
+
+// byID returns the schema with the $id
+func (st *store) byID(id string) ([]byte, Version, error) {
+	st.mu.RLock()
+	defer st.mu.RUnlock()
+
+	v, ok := st.ids[strings.TrimSuffix(id, "#")]
+	if !ok {
+		return nil, Version{}, errNotFound
+	}
+	data, err := st.read(v.Subject, v.Version)
+	return data, v, err
+}
+
+// byPath returns the schema, which $id has the path, so the registry can be used
+// as the host of the $ids, e.g. http://localhost:8080/person.json
+func (st *store) byPath(path string) ([]byte, Version, error) {
+	st.mu.RLock()
+	defer st.mu.RUnlock()
+
+	ids := []string{}
+	for id := range st.ids {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	for _, id := range ids {
+		u, err := url.Parse(id)
+		if err != nil || u.Path != path {
+			continue
+		}
+		v := st.ids[id]
+		data, err := st.read(v.Subject, v.Version)
+		return data, v, err
+	}
+	return nil, Version{}, errNotFound
+}
+
+// add stores data as the next version of subject, after check has accepted it.
+// check gets the latest version, or nil if this is the first version.
+// If data is identical to the latest version, that version is returned and nothing is stored.
+func (st *store) add(subject string, data []byte, check func(latest []byte) error) (Version, bool, error) {
+	dir, err := st.subjectDir(subject)
+	if err != nil {
+		return Version{}, false, err
+	}
+
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	latest, n, err := st.getLocked(subject, 0)
+	if err != nil && err != errNotFound {
+		return Version{}, false, err
+	}
+	if latest != nil && string(latest) == string(data) {
+		return Version{Subject: subject, Version: n, ID: schemaID(data)}, false, nil
+	}
+
+	if id := schemaID(data); id != "" {
+		if other, ok := st.ids[id]; ok && other.Subject != subject {
+			return Version{}, false, &requestError{
+				status: http.StatusUnprocessableEntity,
+				msg:    fmt.Sprintf("$id %s is already used by %s", id, other.Subject),
+			}
+		}
+	}
+
+	if err := check(latest); err != nil {
+		return Version{}, false, err
+	}
+
+	v := Version{Subject: subject, Version: n + 1}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return Version{}, false, err
+	}
+	if err := ioutil.WriteFile(st.filename(subject, v.Version), data, 0o644); err != nil {
+		return Version{}, false, err
+	}
+
+	st.versions[subject] = append(st.versions[subject], v.Version)
+	st.index(data, v)
+	v.ID = schemaID(data)
+
+	return v, true, nil
+}
+
+// subjectDir returns the directory of subject, making sure it's a directory directly in the store
+func (st *store) subjectDir(subject string) (string, error) {
+	invalid := &requestError{status: http.StatusBadRequest, msg: fmt.Sprintf("invalid subject: %q", subject)}
+	if !subjectPattern.MatchString(subject) {
+		return "", invalid
+	}
+
+	dir := filepath.Join(st.dir, subject)
+	if filepath.Dir(dir) != filepath.Clean(st.dir) || filepath.Base(dir) != subject {
+		return "", invalid
+	}
+	return dir, nil
+}
+
+func (st *store) read(subject string, n int) ([]byte, error) {
+	return ioutil.ReadFile(st.filename(subject, n))
+}
+
+func (st *store) filename(subject string, n int) string {
+	return filepath.Join(st.dir, subject, strconv.Itoa(n)+".json")
+}
+
+// schemaID returns the $id of the schema, or id for draft 4 schemas
+func schemaID(data []byte) string {
+	for _, key := range []string{"$id", "id"} {
+		if id, err := jsonparser.GetString(data, key); err == nil && id != "" {
+			return strings.TrimSuffix(id, "#")
+		}
+	}
+	return ""
+}
@@ -0,0 +1,77 @@
+package jsonschema_test
+
+import (
+	"io/ioutil"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/buger/jsonparser"
+	"github.com/flowstack/go-jsonschema/registry"
+)
+
+// remotesURL is where the test suite expects the remote schemas in testdata/remotes
+const remotesURL = "http://localhost:1234/"
+
+// TestMain serves the remote schemas of the test suite from a schema registry
+func TestMain(m *testing.M) {
+	dir, err := ioutil.TempDir("", "remotes")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	reg, err := registry.Open(dir, 0)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := addRemotes(reg, filepath.Join("testdata", "remotes")); err != nil {
+		log.Fatal(err)
+	}
+
+	listener, err := net.Listen("tcp", ":1234")
+	if err != nil {
+		log.Fatal(err)
+	}
+	go http.Serve(listener, reg)
+
+	code := m.Run()
+	listener.Close()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+// addRemotes adds every schema in dir to the registry, with an $id matching its path,
+// so the registry serves it at the same URL as a file server would
+func addRemotes(reg *registry.Registry, dir string) error {
+	return filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() || filepath.Ext(path) != ".json" {
+			return err
+		}
+
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		if _, _, _, err := jsonparser.Get(data, "$id"); err == jsonparser.KeyPathNotFoundError {
+			data, err = jsonparser.Set(data, []byte(strconv.Quote(remotesURL+rel)), "$id")
+			if err != nil {
+				return err
+			}
+		}
+
+		subject := strings.NewReplacer("/", "_", ".json", "").Replace(rel)
+		_, _, err = reg.Add(subject, data)
+		return err
+	})
+}
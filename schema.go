@@ -13,7 +13,12 @@ import (
 
 func New(schema []byte) (*Schema, error) {
 	var nilSchema *Schema
-	return nilSchema.Parse(schema)
+	s, err := nilSchema.Parse(schema)
+	if err != nil {
+		return s, err
+	}
+	s.parseRefTargets()
+	return s, nil
 	// return new(Schema).Parse(schema)
 }
 
@@ -51,6 +56,11 @@ type Schema struct {
 	// pointers holds references to schemas with ($)id, collected during parsing - the map key is ($)id
 	pointers *pointers
 
+	// subSchemas holds schemas parsed from keywords, that aren't parsed into the schema tree,
+	// e.g. unknown keywords, when a $ref points into them - the map key is the JSON Pointer from this schema.
+	// The targets of $refs in the document are parsed with the schema, so Validate only reads it.
+	subSchemas map[string]*Schema
+
 	// refs holds pointers to $ref objects to make de-ref'ing easier.
 	// These should only be present on the root schema.
 	refs *refs
@@ -59,11 +69,17 @@ type Schema struct {
 	// Should only be set on root
 	circularThreshold int
 
+	// formats holds the formats added with RegisterFormat, which are only set on the root
+	formats map[string]func(value string) error
+
 	// Not sure this is the way to go
 	// Array of validator functions.
 	// These are added after checking for all possible constraints
 	validators []validatorFunc
 
+	// plan holds the constraints parsed for the validators
+	plan *plan
+
 	// This is to make it easier to deal with true / false schemas
 	boolean *bool
 
@@ -96,6 +112,9 @@ type Schema struct {
 	ReadOnly    *bool       `json:"readOnly,omitempty"`
 	WriteOnly   *bool       `json:"writeOnly,omitempty"`
 	Definitions *Properties `json:"definitions,omitempty"`
+	// Draft 2019-09
+	// Same as definitions, which it replaces
+	Defs *Properties `json:"$defs,omitempty"`
 	// If schemas should look something like (const being the important part):
 	//  { "if": { "properties": { "propertyX": { "const": "ValueX" } }, "required": ["propertyX"] } }
 	If *Schema `json:"if,omitempty"`
@@ -178,6 +197,19 @@ type Schema struct {
 	ExclusiveMinimum *Value `json:"exclusiveMinimum,omitempty"` // bool in draft 4
 }
 
+// RegisterFormat adds a format, that isn't part of JSON Schema, to the schema and the rest of its tree.
+// check returns why a string isn't valid in the format. Formats must be registered before the schema is used.
+func (s *Schema) RegisterFormat(name string, check func(value string) error) {
+	root := s
+	if s.root != nil {
+		root = s.root
+	}
+	if root.formats == nil {
+		root.formats = map[string]func(value string) error{}
+	}
+	root.formats[name] = check
+}
+
 func (s *Schema) SetUnknown(name string, val *Value) error {
 	for i, up := range s.unknownProps {
 		if up.Name == name {
@@ -252,6 +284,11 @@ func (s Schema) MarshalJSON() ([]byte, error) {
 		return json.Marshal(tmpSchema(*newSchema))
 	}
 
+	return s.marshalKeywords()
+}
+
+// marshalKeywords marshals the keywords and unknown properties of the schema, without resolving $ref
+func (s Schema) marshalKeywords() ([]byte, error) {
 	b, err := json.Marshal(tmpSchema(s))
 
 	// Set unknown properties
@@ -378,6 +415,7 @@ func (s Schema) IsEmpty() bool {
 		(s.ReadOnly == nil) &&
 		(s.WriteOnly == nil) &&
 		(s.Definitions == nil) &&
+		(s.Defs == nil) &&
 		(s.If == nil) &&
 		(s.Then == nil) &&
 		(s.Else == nil) &&
@@ -411,3 +449,36 @@ func (s Schema) IsEmpty() bool {
 		(s.Minimum == nil) &&
 		(s.ExclusiveMinimum == nil))
 }
+
+// schemaFields returns the fields of s, that holds a single sub schema, by keyword
+func schemaFields(s *Schema) map[string]**Schema {
+	return map[string]**Schema{
+		"if":                   &s.If,
+		"then":                 &s.Then,
+		"else":                 &s.Else,
+		"not":                  &s.Not,
+		"additionalProperties": &s.AdditionalProperties,
+		"propertyNames":        &s.PropertyNames,
+		"additionalItems":      &s.AdditionalItems,
+		"contains":             &s.Contains,
+	}
+}
+
+// propertiesFields returns the fields of s, that holds named sub schemas, by keyword
+func propertiesFields(s *Schema) map[string]**Properties {
+	return map[string]**Properties{
+		"definitions":       &s.Definitions,
+		"$defs":             &s.Defs,
+		"properties":        &s.Properties,
+		"patternProperties": &s.PatternProperties,
+	}
+}
+
+// schemasFields returns the fields of s, that holds a list of sub schemas, by keyword
+func schemasFields(s *Schema) map[string]**Schemas {
+	return map[string]**Schemas{
+		"allOf": &s.AllOf,
+		"anyOf": &s.AnyOf,
+		"oneOf": &s.OneOf,
+	}
+}
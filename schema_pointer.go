@@ -0,0 +1,64 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/flowstack/go-jsonschema/jsonpointer"
+)
+
+// ResolvePointerToken implements jsonpointer.Resolver, so schemas can be navigated with JSON Pointers.
+// Keywords with sub schemas resolve to *Schema, *Properties, *Schemas or *Dependencies,
+// and every other keyword resolves to its raw JSON.
+func (s *Schema) ResolvePointerToken(token string) (interface{}, error) {
+	if s == nil {
+		return nil, fmt.Errorf("%w: %s in an empty schema", jsonpointer.ErrNotFound, token)
+	}
+
+	if field, ok := schemaFields(s)[token]; ok && *field != nil {
+		return *field, nil
+	}
+	if field, ok := propertiesFields(s)[token]; ok && *field != nil {
+		return *field, nil
+	}
+	if field, ok := schemasFields(s)[token]; ok && *field != nil {
+		return *field, nil
+	}
+	if token == "items" && s.Items != nil {
+		if s.Items.Schema != nil {
+			return s.Items.Schema, nil
+		} else if s.Items.Schemas != nil {
+			return s.Items.Schemas, nil
+		}
+	}
+	if token == "dependencies" && s.Dependencies != nil {
+		return s.Dependencies, nil
+	}
+
+	raw, err := jsonpointer.New(token).GetJSON(s.raw)
+	if err != nil {
+		return nil, err
+	}
+	return json.RawMessage(raw), nil
+}
+
+// ResolvePointerToken implements jsonpointer.Resolver by the names of the properties
+func (p Properties) ResolvePointerToken(token string) (interface{}, error) {
+	prop, ok := p.GetProperty(token)
+	if !ok || prop.Property == nil {
+		return nil, fmt.Errorf("%w: %s", jsonpointer.ErrNotFound, token)
+	}
+	return prop.Property, nil
+}
+
+// ResolvePointerToken implements jsonpointer.Resolver, and resolves to the schema or
+// the required properties of a dependency
+func (d Dependencies) ResolvePointerToken(token string) (interface{}, error) {
+	dep, ok := d[token]
+	if ok && dep != nil && dep.Schema != nil {
+		return dep.Schema, nil
+	} else if ok && dep != nil && dep.Strings != nil {
+		return dep.Strings, nil
+	}
+	return nil, fmt.Errorf("%w: %s", jsonpointer.ErrNotFound, token)
+}
@@ -0,0 +1,71 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/flowstack/go-jsonschema/jsonpointer"
+)
+
+func TestSchemaPointer(t *testing.T) {
+	schema, err := NewFromString(`{
+		"definitions": {"a/b": {"type": "string"}},
+		"properties": {"deps": {"dependencies": {"x": ["y"], "z": {"minimum": 1}}}},
+		"items": [{"type": "integer"}, {"enum": [1, 2]}],
+		"x-custom": {"nested": {"type": "boolean"}}
+	}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]func(v interface{}) bool{
+		"/definitions/a~1b": func(v interface{}) bool {
+			s, ok := v.(*Schema)
+			prop, found := schema.Definitions.GetProperty("a/b")
+			return ok && found && s == prop.Property
+		},
+		"/items/1": func(v interface{}) bool {
+			s, ok := v.(*Schema)
+			return ok && s == (*schema.Items.Schemas)[1]
+		},
+		"/properties/deps/dependencies/z": func(v interface{}) bool {
+			s, ok := v.(*Schema)
+			return ok && s.Minimum != nil
+		},
+		"/properties/deps/dependencies/x/0": func(v interface{}) bool {
+			s, ok := v.(*string)
+			return ok && *s == "y"
+		},
+		"/items/1/enum/1": func(v interface{}) bool {
+			raw, ok := v.(json.RawMessage)
+			return ok && string(raw) == "2"
+		},
+		"/x-custom/nested": func(v interface{}) bool {
+			raw, ok := v.(json.RawMessage)
+			return ok && string(raw) == `{"type": "boolean"}`
+		},
+	}
+	for str, check := range tests {
+		ptr, err := jsonpointer.Parse(str)
+		if err != nil {
+			t.Fatal(err)
+		}
+		v, err := ptr.Get(schema)
+		if err != nil {
+			t.Errorf("unable to get %s: %s", str, err)
+			continue
+		}
+		if !check(v) {
+			t.Errorf("unexpected value at %s: %#v", str, v)
+		}
+	}
+
+	// $ref may point anywhere in the schema
+	schema, err = NewFromString(`{"x-custom": {"nested": {"type": "boolean"}}, "properties": {"a": {"$ref": "#/x-custom/nested"}}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if valid, _ := schema.Validate([]byte(`{"a": 1}`)); valid {
+		t.Errorf("expected the $ref to an unknown keyword to be resolved")
+	}
+}
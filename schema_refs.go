@@ -1,14 +1,14 @@
 package jsonschema
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
-	"io/ioutil"
-	"net/http"
 	"net/url"
 	"strings"
 
 	"github.com/buger/jsonparser"
+	"github.com/flowstack/go-jsonschema/jsonpointer"
 )
 
 // Relevant docs:
@@ -17,58 +17,6 @@ import (
 // Anything starting with # means:
 // Go to base schema -> find whatever is after #
 
-func unescapeRefPath(refPath string) string {
-	var err error
-	var ref, frag string
-
-	refParts := strings.Split(refPath, "#")
-	if len(refParts) == 1 {
-		frag = refParts[0]
-	} else if len(refParts) > 1 {
-		ref = refParts[0]
-		frag = strings.Join(refParts[1:], "#")
-	}
-
-	frag, err = url.QueryUnescape(frag)
-	if err != nil {
-		return ""
-	}
-	frag = strings.ReplaceAll(frag, "~0", "~")
-	frag = strings.ReplaceAll(frag, "~1", "/")
-
-	if ref != "" && frag != "" {
-		return ref + "#" + frag
-	}
-
-	return ref + frag
-}
-
-func escapeRefPath(refPath string) string {
-	var err error
-	var ref, frag string
-
-	refParts := strings.Split(refPath, "#")
-	if len(refParts) == 1 {
-		frag = refParts[0]
-	} else if len(refParts) > 1 {
-		ref = refParts[0]
-		frag = strings.Join(refParts[1:], "#")
-	}
-
-	frag, err = url.QueryUnescape(frag)
-	if err != nil {
-		return ""
-	}
-	frag = strings.ReplaceAll(frag, "~", "~0")
-	frag = strings.ReplaceAll(frag, "/", "~1")
-
-	if ref != "" && frag != "" {
-		return ref + "#" + frag
-	}
-
-	return ref + frag
-}
-
 // ExpandURI attempts to resolve a uri against the current Base URI
 func (s *Schema) ExpandURI(uri string) (*url.URL, error) {
 	// If uri is empty, it is seen as invalid
@@ -124,8 +72,12 @@ func (s *Schema) AddSchemaString(schemaString string) error {
 		return nil
 	}
 
-	_, err := s.Parse([]byte(schemaString))
-	return err
+	schema, err := s.Parse([]byte(schemaString))
+	if err != nil {
+		return err
+	}
+	schema.parseRefTargets()
+	return nil
 }
 
 func (s *Schema) DeRef() error {
@@ -179,6 +131,13 @@ func (s *Schema) ResolveRef(ref *Ref) (*Schema, error) {
 			return baseSchema, nil
 		}
 
+		// Schemas registered under a pointer in the root document, e.g. #/components/schemas/User
+		if baseSchema != nil && baseSchema.root == nil {
+			if refSchema := baseSchema.getPointer(refStr); refSchema != nil {
+				return refSchema, nil
+			}
+		}
+
 		if len(refStr) > 1 && refStr[:2] != "#/" {
 			refSchema := baseSchema.getPointer(refStr)
 			if refSchema != nil {
@@ -188,77 +147,19 @@ func (s *Schema) ResolveRef(ref *Ref) (*Schema, error) {
 			return nil, fmt.Errorf("unable to find ref: %s", refStr)
 		}
 
-		// Find the path
-		pathParts := strings.Split(strings.Trim(refStr[1:], "/"), "/")
-		if len(pathParts) == 0 {
-			return baseSchema, nil
+		if baseSchema == nil {
+			return nil, fmt.Errorf("unable to find ref: %s", refStr)
 		}
 
-		for i := 0; i < len(pathParts); i++ {
-			pathParts[i] = unescapeRefPath(pathParts[i])
-
-			switch pathParts[i] {
-			case "definitions":
-				if i >= (len(pathParts) - 1) {
-					return nil, errors.New("#/definitions is not a valid schema")
-				}
-				i++
-				pathParts[i] = unescapeRefPath(pathParts[i])
-				baseProp, ok := (*baseSchema.Definitions).GetProperty(pathParts[i])
-				if ok {
-					baseSchema = baseProp.Property
-				}
-
-			case "properties":
-				if i >= (len(pathParts) - 1) {
-					return nil, errors.New("#/properties is not a valid schema")
-				}
-				i++
-				pathParts[i] = unescapeRefPath(pathParts[i])
-				baseProp, ok := (*baseSchema.Properties).GetProperty(pathParts[i])
-				if ok {
-					baseSchema = baseProp.Property
-				}
-
-			case "items":
-				if baseSchema.Items.Boolean != nil {
-					baseSchema = &Schema{boolean: baseSchema.boolean}
-				} else if baseSchema.Items.Schema != nil {
-					baseSchema = baseSchema.Items.Schema
-				}
-
-				if i >= (len(pathParts) - 1) {
-					return nil, errors.New("#/items is not a valid schema")
-				}
-				i++
-
-				idx, err := jsonparser.ParseInt([]byte(pathParts[i]))
-				if err != nil {
-					return nil, errors.New("unable to parse item's index")
-				}
-				baseSchema = (*baseSchema.Items.Schemas)[idx]
-
-			default:
-				if isInteger([]byte(pathParts[i])) {
-					pathParts[i] = fmt.Sprintf("[%s]", pathParts[i])
-				} else {
-					pathParts[i] = unescapeRefPath(pathParts[i])
-				}
-
-				rawBase, _, _, err := jsonparser.Get(baseSchema.raw, pathParts[i])
-				if err != nil {
-					return nil, fmt.Errorf("unable to find schema at path: %s", *ref.String)
-				}
-
-				baseSchema, err = baseSchema.Parse(rawBase)
-				if err != nil {
-					return nil, fmt.Errorf("unable to find schema at path: %s", *ref.String)
-				}
-
-			}
+		ptr, err := jsonpointer.ParseFragment(refStr)
+		if err != nil {
+			return nil, err
 		}
-
-		return baseSchema, nil
+		refSchema, err := baseSchema.resolvePointer(ptr)
+		if err != nil {
+			return nil, fmt.Errorf("unable to find schema at path: %s: %w", refStr, err)
+		}
+		return refSchema, nil
 
 	} else {
 		refURI, err := baseSchema.ExpandURI(refStr)
@@ -305,15 +206,10 @@ func (s *Schema) ResolveRef(ref *Ref) (*Schema, error) {
 				refURI.Path,
 			)
 
-			res, err := http.Get(schemaURL)
+			body, err := Loader(schemaURL)
 			if err != nil {
 				return nil, err
 			}
-			body, err := ioutil.ReadAll(res.Body)
-			if err != nil {
-				return nil, err
-			}
-			res.Body.Close()
 
 			// Add the $id to the schema, if it does not exist
 			idKey := "$id"
@@ -336,11 +232,17 @@ func (s *Schema) ResolveRef(ref *Ref) (*Schema, error) {
 			if err != nil {
 				return nil, err
 			}
+			frag := refURI.Fragment
 			refURI.Fragment = ""
 			baseSchema.baseURI = refURI
 			// log.Println(baseSchema)
 			s.setPointer(refURI.String(), baseSchema)
 
+			if frag != "" {
+				frag = "#" + frag
+				return baseSchema.ResolveRef(&Ref{String: &frag})
+			}
+
 			return baseSchema, nil
 		}
 
@@ -356,3 +258,73 @@ func (s *Schema) ResolveRef(ref *Ref) (*Schema, error) {
 		return baseSchema, err
 	}
 }
+
+// parseRefTargets resolves the $refs to pointers in the same document, in the tree of s.
+// Pointers into keywords, that aren't parsed into schemas, are parsed and cached by resolvePointer,
+// which must happen before validation, as a parsed schema can be used concurrently.
+// $refs, that can't be resolved yet, are left to be resolved when they're used.
+func (s *Schema) parseRefTargets() {
+	root := s
+	if s.root != nil {
+		root = s.root
+	}
+	if root.refs == nil {
+		return
+	}
+
+	// Parsing the targets may add more $refs, which are resolved too
+	for i := 0; i < len(*root.refs); i++ {
+		ref := (*root.refs)[i]
+		if ref.parent != nil && ref.String != nil && strings.HasPrefix(*ref.String, "#/") {
+			ref.parent.ResolveRef(ref)
+		}
+	}
+}
+
+// resolvePointer resolves ptr in the parsed schema tree of s.
+// Values under keywords, that aren't parsed into schemas, e.g. unknown keywords, are parsed once
+// and cached on the nearest parsed schema, so every $ref to them gets the same *Schema,
+// with the $id scope of its surroundings.
+func (s *Schema) resolvePointer(ptr jsonpointer.Pointer) (*Schema, error) {
+	var current interface{} = s
+	owner, depth := s, 0
+	for i, token := range ptr {
+		next, err := jsonpointer.New(token).Get(current)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := next.(json.RawMessage); ok {
+			break
+		}
+		current = next
+		if subSchema, ok := next.(*Schema); ok {
+			owner, depth = subSchema, i+1
+		}
+	}
+
+	if depth == len(ptr) {
+		return owner, nil
+	}
+
+	rest := ptr[depth:]
+	key := rest.String()
+	if subSchema, ok := owner.subSchemas[key]; ok {
+		return subSchema, nil
+	}
+
+	raw, err := rest.GetJSON(owner.raw)
+	if err != nil {
+		return nil, err
+	}
+	subSchema, err := owner.Parse(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	if owner.subSchemas == nil {
+		owner.subSchemas = map[string]*Schema{}
+	}
+	owner.subSchemas[key] = subSchema
+
+	return subSchema, nil
+}
@@ -1,6 +1,7 @@
 package jsonschema
 
 import (
+	"sync"
 	"testing"
 )
 
@@ -47,3 +48,95 @@ func TestAddSchema(t *testing.T) {
 		t.Fatal(`expected document to be invalid`)
 	}
 }
+
+func TestResolveRefThroughKeywords(t *testing.T) {
+	schema, err := NewFromString(`{
+		"allOf": [{"minimum": 1}],
+		"patternProperties": {"^a": {"type": "string"}},
+		"dependencies": {"foo": {"required": ["bar"]}},
+		"additionalProperties": {"type": "boolean"},
+		"x-shared": {
+			"positive": {"exclusiveMinimum": 0},
+			"scoped": {"$id": "http://example.com/scoped", "definitions": {"s": {"type": "string"}}, "$ref": "#/definitions/s"}
+		}
+	}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	refs := map[string]*Schema{
+		"#/allOf/0":               (*schema.AllOf)[0],
+		"#/patternProperties/^a":  nil,
+		"#/dependencies/foo":      (*schema.Dependencies)["foo"].Schema,
+		"#/additionalProperties":  schema.AdditionalProperties,
+		"#/x-shared/positive":     nil,
+		"#/x-shared/scoped":       nil,
+		"#/x-shared/positive/not": nil,
+	}
+	if prop, ok := schema.PatternProperties.GetProperty("^a"); ok {
+		refs["#/patternProperties/^a"] = prop.Property
+	}
+
+	for refStr, expected := range refs {
+		refStr := refStr
+		first, err := schema.ResolveRef(&Ref{String: &refStr})
+		if refStr == "#/x-shared/positive/not" {
+			if err == nil {
+				t.Errorf("expected %s not to be found", refStr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("unable to resolve %s: %s", refStr, err)
+			continue
+		}
+		if expected != nil && first != expected {
+			t.Errorf("expected %s to resolve to the parsed schema", refStr)
+		}
+		second, err := schema.ResolveRef(&Ref{String: &refStr})
+		if err != nil || second != first {
+			t.Errorf("expected %s to resolve to the same schema every time", refStr)
+		}
+	}
+
+	// Schemas under unknown keywords keep their $id scope
+	refStr := "#/x-shared/scoped"
+	scoped, _ := schema.ResolveRef(&Ref{String: &refStr})
+	if valid, _ := scoped.Validate([]byte(`1`)); valid {
+		t.Errorf("expected the $ref inside the scoped schema to be resolved against its $id")
+	}
+	refStr = "#/x-shared/positive"
+	positive, _ := schema.ResolveRef(&Ref{String: &refStr})
+	if valid, _ := positive.Validate([]byte(`0`)); valid {
+		t.Errorf("expected 0 to be invalid")
+	}
+}
+
+func TestResolveRefThroughKeywordsConcurrently(t *testing.T) {
+	// The targets of the $refs are parsed with the schema, so validating doesn't change it,
+	// and the first validations of a new schema can run at the same time
+	for i := 0; i < 20; i++ {
+		schema, err := NewFromString(`{"properties":{"a":{"$ref":"#/x-foo/bar"},"b":{"$ref":"#/x-foo/baz"}},"x-foo":{"bar":{"type":"integer","minimum":1},"baz":{"$ref":"#/x-foo/bar"}}}`)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		start := make(chan struct{})
+		wg := sync.WaitGroup{}
+		for j := 0; j < 4; j++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				<-start
+				if valid, err := schema.Validate([]byte(`{"a":1,"b":2}`)); !valid {
+					t.Errorf("expected a valid document to be valid, got: %v", err)
+				}
+				if valid, _ := schema.Validate([]byte(`{"a":1,"b":0}`)); valid {
+					t.Errorf("expected an invalid document to be invalid")
+				}
+			}()
+		}
+		close(start)
+		wg.Wait()
+	}
+}
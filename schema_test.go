@@ -120,3 +120,22 @@ func TestUnknowns(t *testing.T) {
 		t.Fatalf("expected schemas to be equal, but got:\nexpected:\n%s\nactual:\n%s \n", testSchema, string(newSchema))
 	}
 }
+
+func TestMetaSchema(t *testing.T) {
+	for draft, expected := range map[Draft]*Schema{
+		Draft04:      Draft04Schema,
+		Draft06:      Draft06Schema,
+		Draft07:      Draft07Schema,
+		DraftUnknown: Draft07Schema,
+	} {
+		if schema, err := MetaSchema(draft); err != nil || schema != expected {
+			t.Errorf("%s: expected the bundled meta-schema, got: %v", draft, err)
+		}
+	}
+
+	for _, draft := range []Draft{Draft2019_09, Draft2020_12} {
+		if _, err := MetaSchema(draft); err == nil {
+			t.Errorf("%s: expected an error, as there is no bundled meta-schema", draft)
+		}
+	}
+}
@@ -125,6 +125,7 @@ const (
 	PropReadOnly
 	PropWriteOnly
 	PropDefinitions
+	PropDefs
 	PropIf
 	PropThen
 	PropElse
@@ -176,6 +177,7 @@ var propNames = [][]string{
 	PropReadOnly:             {"readOnly"},
 	PropWriteOnly:            {"writeOnly"},
 	PropDefinitions:          {"definitions"},
+	PropDefs:                 {"$defs"},
 	PropIf:                   {"if"},
 	PropThen:                 {"then"},
 	PropElse:                 {"else"},
@@ -227,6 +229,7 @@ var nameToProp = map[string]SchemaProp{
 	"readOnly":             PropReadOnly,
 	"writeOnly":            PropWriteOnly,
 	"definitions":          PropDefinitions,
+	"$defs":                PropDefs,
 	"if":                   PropIf,
 	"then":                 PropThen,
 	"else":                 PropElse,
@@ -6,7 +6,6 @@ import (
 	"errors"
 	"fmt"
 	"math/big"
-	"reflect"
 
 	"github.com/buger/jsonparser"
 )
@@ -297,8 +296,6 @@ func NewValue(jsonVal []byte, vt jsonparser.ValueType) (*Value, error) {
 		null := true
 		val.Null = &null
 	case jsonparser.Object:
-		val.raw = sortObject(val.raw)
-
 		tmpObject := map[string]*Value{}
 
 		err := jsonparser.ObjectEach(jsonVal, func(key []byte, value []byte, dataType jsonparser.ValueType, offset int) error {
@@ -349,33 +346,26 @@ func NewValue(jsonVal []byte, vt jsonparser.ValueType) (*Value, error) {
 	return &val, nil
 }
 
+// Equal tells whether the values are equal by JSON Schema semantics, e.g. 1 and 1.0 are equal
 func (v *Value) Equal(val *Value) bool {
-	if v == nil && val == nil {
-		return true
+	if v == nil || val == nil {
+		return v == val
 	}
-	if v != nil && val == nil {
+
+	canonical, err := v.canonical()
+	if err != nil {
 		return false
 	}
-	if v.String != nil && val.String != nil {
-		return (*v.String == *val.String)
-	}
-	if v.Number != nil && val.Number != nil {
-		return v.Number.Cmp(val.Number) == 0
-	}
-	if v.Boolean != nil && val.Boolean != nil {
-		return (*v.Boolean == *val.Boolean)
-	}
-	if v.Null != nil && val.Null != nil {
-		return (*v.Null == *val.Null)
-	}
-	if v.Object != nil && val.Object != nil {
-		return reflect.DeepEqual(*v.Object, *val.Object)
-	}
-	if v.Array != nil && val.Array != nil {
-		return reflect.DeepEqual(*v.Array, *val.Array)
+	other, err := val.canonical()
+	if err != nil {
+		return false
 	}
+	return bytes.Equal(canonical, other)
+}
 
-	return false
+// canonical returns the canonical form of the value
+func (v *Value) canonical() ([]byte, error) {
+	return canonicalJSON(v.raw, v.valueType.ParserValueType())
 }
 
 type NamedValue struct {
@@ -48,3 +48,19 @@ func init() {
 		panic(fmt.Errorf("can't start without schemas/draft-07.json\n%s", err.Error()))
 	}
 }
+
+// MetaSchema returns the bundled meta-schema for draft.
+// Schemas without a known $schema are checked against draft 7, like Validate does.
+// 2019-09 and 2020-12 have no bundled meta-schema, so an error is returned for them.
+func MetaSchema(draft Draft) (*Schema, error) {
+	switch draft {
+	case Draft04:
+		return Draft04Schema, nil
+	case Draft06:
+		return Draft06Schema, nil
+	case Draft07, DraftUnknown:
+		return Draft07Schema, nil
+	default:
+		return nil, fmt.Errorf("no meta-schema is available for %s", draft)
+	}
+}
@@ -34,6 +34,10 @@ func (s *Schema) Validate(jsonDoc []byte) (bool, error) {
 		return false, errors.New("invalid schema")
 	}
 
+	// Errors are positioned in the document as it was given
+	doc := jsonDoc
+	start := len(jsonDoc) - len(bytes.TrimLeft(jsonDoc, " \r\n"))
+
 	// Ensure that datectors work (though it slows things down a bit)
 	jsonDoc = bytes.Trim(jsonDoc, " \r\n")
 
@@ -56,6 +60,7 @@ func (s *Schema) Validate(jsonDoc []byte) (bool, error) {
 
 	err = validate(jsonDoc, typ, s)
 	if err != nil {
+		positionErrors(err, doc, start)
 		return false, err
 	}
 	return true, nil
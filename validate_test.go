@@ -5,11 +5,9 @@ import (
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
-	"net/http"
 	"os"
 	"path"
 	"testing"
-	"time"
 
 	"github.com/flowstack/go-jsonschema/testtools"
 	"github.com/xeipuuv/gojsonschema"
@@ -81,22 +79,6 @@ var ignoreDraft2020_12TestFiles = map[string]struct{}{
 
 var testDataPath = "testdata"
 
-func TestMain(m *testing.M) {
-	// Start a server for the remote test schema
-	remoteSchemasPath := path.Join(testDataPath, "remotes")
-	go func() {
-		err := http.ListenAndServe(":1234", http.FileServer(http.Dir(remoteSchemasPath)))
-		if err != nil {
-			panic(err)
-		}
-	}()
-
-	// Give the HTTP server a second to get started
-	time.Sleep(time.Second)
-
-	os.Exit(m.Run())
-}
-
 func TestValidateEmptyDocWithSchema(t *testing.T) {
 	schema, err := NewFromString("{}")
 	if err != nil {
@@ -2,11 +2,9 @@ package jsonschema
 
 import (
 	"bytes"
-	"encoding/json"
 	"errors"
 	"fmt"
 	"log"
-	"math/big"
 	"net"
 	"net/mail"
 	"net/url"
@@ -16,6 +14,7 @@ import (
 	"unicode/utf8"
 
 	"github.com/buger/jsonparser"
+	"github.com/flowstack/go-jsonschema/jsonpointer"
 	"golang.org/x/net/idna"
 )
 
@@ -45,7 +44,7 @@ func validate(value []byte, vt ValueType, schema *Schema) error {
 	for _, validator := range schema.validators {
 		err = validator(value, vt, schema)
 		if err != nil {
-			return err
+			return asValidationError(err)
 		}
 	}
 
@@ -56,7 +55,7 @@ func validateValue(value []byte, vt ValueType, schema *Schema) error {
 	// If the we have an empty value and the schema is not boolean (false), then the doc is invalid
 	// if len(value) == 0 && schema.boolean != nil && !*schema.boolean {
 	if len(value) == 0 && schema.IsEmpty() {
-		return errors.New(`empty document is not valid against any other schemas than "false"`)
+		return newValidationError("empty", nil)
 	}
 	return nil
 }
@@ -69,14 +68,14 @@ func validateBooleanSchema(value []byte, vt ValueType, schema *Schema) error {
 			return nil
 		}
 		// If we do not have a boolean false schema, but have an empty value, then the doc is invalid
-		return errors.New("empty document does not validate against the schema")
+		return newValidationError("empty", nil)
 	}
 
 	// If we have a value and a boolean true schema then the value is valid
 	if *schema.boolean {
 		return nil
 	}
-	return errors.New("document does not match the false schema")
+	return newValidationError("false", nil)
 }
 
 func validateRef(value []byte, vt ValueType, schema *Schema) error {
@@ -112,13 +111,14 @@ func validateItems(value []byte, vt ValueType, schema *Schema) error {
 		} else if !*schema.Items.Boolean && len(value) <= 2 { // empty array matches boolean false schema
 			return nil
 		}
-		return errors.New("items doesn't match schema")
+		return newValidationError("items", nil)
 	}
 
 	idx := -1
 	var errs error
 	_, parseErr := jsonparser.ArrayEach(value, func(value []byte, dataType jsonparser.ValueType, offset int, parseErr error) {
 		idx++
+		offset = arrayValueStart(offset, dataType)
 
 		// Don't spent time validating, if we already have a parser error
 		if parseErr != nil {
@@ -128,7 +128,7 @@ func validateItems(value []byte, vt ValueType, schema *Schema) error {
 
 		if schema.UniqueItems != nil && *schema.UniqueItems {
 			if unique.Exists(value, dataType) {
-				errs = addError(errors.New("values are not unique"), errs)
+				errs = addError(errorAtIndex(newValidationError("uniqueItems", nil), idx, offset), errs)
 				return
 			}
 		}
@@ -146,11 +146,11 @@ func validateItems(value []byte, vt ValueType, schema *Schema) error {
 
 		} else if schema.Items.Schema != nil {
 			err := validate(value, ValueType(dataType), schema.Items.Schema)
-			errs = addError(err, errs)
+			errs = addError(errorAtIndex(err, idx, offset), errs)
 
 		} else if schema.Items.Schemas != nil && idx < len(*schema.Items.Schemas) {
 			err := validate(value, ValueType(dataType), (*schema.Items.Schemas)[idx])
-			errs = addError(err, errs)
+			errs = addError(errorAtIndex(err, idx, offset), errs)
 
 		} else if schema.AdditionalItems == nil {
 			// It's allowed to have more items than schemas
@@ -159,28 +159,28 @@ func validateItems(value []byte, vt ValueType, schema *Schema) error {
 		} else if schema.AdditionalItems != nil && (schema.IsDraft4() || len(*schema.Items.Schemas) > 0) {
 			// Only draft 4 allows addtionalItems without items as well
 			err := validate(value, ValueType(dataType), schema.AdditionalItems)
-			errs = addError(err, errs)
+			errs = addError(errorAtIndex(err, idx, offset), errs)
 
 		} else {
-			errs = addError(fmt.Errorf("index %d has no schema to match against", idx), errs)
+			errs = addError(errorAtIndex(newValidationError("additionalItems", Params{"index": idx}), idx, offset), errs)
 		}
 	})
 
 	if schema.Contains != nil && !contains {
-		errs = addError(errors.New("no values matched the contains schema"), errs)
+		errs = addError(newValidationError("contains", nil), errs)
 	}
 
 	count := int64(idx + 1)
 
 	if schema.MaxItems != nil {
 		if count > *schema.MaxItems {
-			errs = addError(errors.New("too many properties"), errs)
+			errs = addError(newValidationError("maxItems", Params{"maxItems": *schema.MaxItems}), errs)
 		}
 	}
 
 	if schema.MinItems != nil {
 		if count < *schema.MinItems {
-			errs = addError(errors.New("too few properties"), errs)
+			errs = addError(newValidationError("minItems", Params{"minItems": *schema.MinItems}), errs)
 		}
 	}
 
@@ -203,30 +203,21 @@ func validateProperties(value []byte, vt ValueType, schema *Schema) error {
 	var count int64
 	errs := jsonparser.ObjectEach(value, func(key []byte, value []byte, dataType jsonparser.ValueType, offset int) error {
 		count++
+		offset = objectValueStart(offset, value, dataType)
 
 		var hasSchema bool
 		var subSchema *Schema
 
-		if schema.Properties != nil {
-			var subProp *NamedProperty
-			subProp, hasSchema = schema.Properties.GetProperty(string(key))
-			if hasSchema {
-				subSchema = subProp.Property
-			}
+		if schema.plan.properties != nil {
+			subSchema, hasSchema = schema.plan.properties[string(key)]
 		}
 
-		if schema.PatternProperties != nil {
-			subSchemas := schema.findPatternProperties(key)
-			if len(subSchemas) > 0 {
+		for _, prop := range schema.plan.patternProperties {
+			if prop.re.Match(key) {
 				hasSchema = true
-				for _, subSchema := range subSchemas {
-					subSchema.name = string(key)
-					if subSchema != nil {
-						err := validate(value, ValueType(dataType), subSchema)
-						if err != nil {
-							return err
-						}
-					}
+				err := validate(value, ValueType(dataType), prop.schema)
+				if err != nil {
+					return errorAtKey(err, key, offset)
 				}
 			}
 		}
@@ -236,21 +227,20 @@ func validateProperties(value []byte, vt ValueType, schema *Schema) error {
 		}
 
 		if subSchema != nil {
-			subSchema.name = string(key)
-			return validate(value, ValueType(dataType), subSchema)
+			return errorAtKey(validate(value, ValueType(dataType), subSchema), key, offset)
 		}
 		return nil
 	})
 
 	if schema.MaxProperties != nil {
 		if count > *schema.MaxProperties {
-			errs = addError(errors.New("too many properties"), errs)
+			errs = addError(newValidationError("maxProperties", Params{"maxProperties": *schema.MaxProperties}), errs)
 		}
 	}
 
 	if schema.MinProperties != nil {
 		if count < *schema.MinProperties {
-			errs = addError(errors.New("too few properties"), errs)
+			errs = addError(newValidationError("minProperties", Params{"minProperties": *schema.MinProperties}), errs)
 		}
 	}
 
@@ -283,7 +273,7 @@ func validatePattern(value []byte, vt ValueType, schema *Schema) error {
 	}
 
 	if !schema.patternRegexp.Match(value) {
-		return errors.New("value did not match pattern")
+		return newValidationError("pattern", Params{"pattern": *schema.Pattern})
 	}
 
 	return nil
@@ -298,8 +288,11 @@ func validatePropertyNames(value []byte, vt ValueType, schema *Schema) error {
 		return nil
 	}
 
+	object := value
 	return jsonparser.ObjectEach(value, func(key []byte, value []byte, dataType jsonparser.ValueType, offset int) error {
-		return validate(key, String, schema.PropertyNames)
+		// The error is at the key, as that is what's invalid
+		offset = keyStart(object, objectValueStart(offset, value, dataType))
+		return errorAtKey(validate(key, String, schema.PropertyNames), key, offset)
 	})
 }
 
@@ -313,7 +306,7 @@ func validateType(value []byte, vt ValueType, schema *Schema) error {
 		if *schema.Type.String == "integer" && vt == Number && isInteger(value) {
 			// In Draft 4 the value 1.0 can NOT be an integer all other drafts allows this
 			if schema.IsDraft4() && strings.Contains(string(value), ".") {
-				return fmt.Errorf(`value "%s" is of type %s, but should be of type: %s`, value, vt, *schema.Type.String)
+				return typeError(value, vt, *schema.Type.String)
 			}
 			return nil
 		}
@@ -325,7 +318,7 @@ func validateType(value []byte, vt ValueType, schema *Schema) error {
 			return nil
 		}
 
-		return fmt.Errorf(`value "%s" is of type %s, but should be of type: %s`, value, vt, *schema.Type.String)
+		return typeError(value, vt, *schema.Type.String)
 
 	} else if schema.Type.Strings != nil {
 		for _, t := range *schema.Type.Strings {
@@ -342,12 +335,20 @@ func validateType(value []byte, vt ValueType, schema *Schema) error {
 			}
 		}
 
-		return fmt.Errorf(`value "%v" is of type %s, but should be of type: %v`, value, vt, *schema.Type.Strings)
+		types := make([]string, len(*schema.Type.Strings))
+		for i, t := range *schema.Type.Strings {
+			types[i] = *t
+		}
+		return typeError(value, vt, strings.Join(types, ", "))
 	}
 
 	return fmt.Errorf("unknown type")
 }
 
+func typeError(value []byte, vt ValueType, expected string) error {
+	return newValidationError("type", Params{"value": string(value), "type": vt, "expected": expected})
+}
+
 func validateRequired(value []byte, vt ValueType, schema *Schema) error {
 	// Ignore anything other than Objects
 	if vt != Object {
@@ -361,7 +362,7 @@ func validateRequired(value []byte, vt ValueType, schema *Schema) error {
 		paths = append(paths, []string{*str})
 	}
 
-	found := 0
+	found := map[string]bool{}
 	jsonparser.EachKey(value, func(idx int, value []byte, vt jsonparser.ValueType, parseErr error) {
 		// Don't spent time validating, if we already have a parser error
 		if parseErr != nil {
@@ -369,23 +370,22 @@ func validateRequired(value []byte, vt ValueType, schema *Schema) error {
 			return
 		}
 
-		if len(value) == 0 {
-			errs = addError(fmt.Errorf("required value not found"), errs)
-			return
-		}
-
-		found++
+		found[paths[idx][0]] = true
 	}, paths...)
 
 	if errs != nil {
 		return errs
 	}
 
-	if found != len(*schema.Required) {
-		return errors.New("not all required properties were found")
+	// Every missing property is reported, so they can all be fixed at once
+	for _, str := range *schema.Required {
+		if !found[*str] {
+			found[*str] = true
+			errs = addError(newValidationError("required", Params{"property": *str}), errs)
+		}
 	}
 
-	return nil
+	return errs
 }
 
 func validateDependencies(value []byte, vt ValueType, schema *Schema) error {
@@ -451,7 +451,7 @@ func validateAnyOf(value []byte, vt ValueType, schema *Schema) error {
 		}
 	}
 
-	return errors.New("value does not match any of the schemas")
+	return newValidationError("anyOf", nil)
 }
 
 func validateOneOf(value []byte, vt ValueType, schema *Schema) error {
@@ -463,7 +463,7 @@ func validateOneOf(value []byte, vt ValueType, schema *Schema) error {
 			if !valid {
 				valid = true
 			} else {
-				return errors.New("value matches more than one of the schemas")
+				return newValidationError("oneOfMultiple", nil)
 			}
 		}
 	}
@@ -472,13 +472,13 @@ func validateOneOf(value []byte, vt ValueType, schema *Schema) error {
 		return nil
 	}
 
-	return errors.New("value does not match one of the schemas")
+	return newValidationError("oneOf", nil)
 }
 
 func validateNot(value []byte, vt ValueType, schema *Schema) error {
 	err := validate(value, vt, schema.Not)
 	if err == nil {
-		return errors.New("value should NOT match schema")
+		return newValidationError("not", nil)
 	}
 	return nil
 }
@@ -489,11 +489,9 @@ func validateMultipleOf(value []byte, vt ValueType, schema *Schema) error {
 		return nil
 	}
 
-	floatVal, _ := new(big.Rat).SetString(string(value))
-	mul, _ := new(big.Rat).SetString(string(*schema.MultipleOf))
-
-	if q := new(big.Rat).Quo(floatVal, mul); !q.IsInt() {
-		return fmt.Errorf("value (%s) is not a multiple of %s", floatVal.String(), mul.String())
+	mul := schema.plan.multipleOf
+	if mul != nil && !mul.isMultiple(value) {
+		return newValidationError("multipleOf", Params{"value": string(value), "multipleOf": mul.raw})
 	}
 
 	return nil
@@ -505,28 +503,23 @@ func validateMaximum(value []byte, vt ValueType, schema *Schema) error {
 		return nil
 	}
 
-	floatVal, ok := new(big.Float).SetString(string(value))
-	if !ok {
-		return errors.New("invalid float value")
-	}
-
-	if schema.Maximum != nil && schema.Maximum.Number != nil {
+	if max := schema.plan.maximum; max != nil {
+		cmp := max.compare(value)
+		// Draft 4 makes maximum exclusive with a boolean exclusiveMaximum
 		if schema.ExclusiveMaximum != nil && schema.ExclusiveMaximum.Boolean != nil && *schema.ExclusiveMaximum.Boolean {
-			if floatVal.Cmp(schema.Maximum.Number) < 0 {
-				return nil
+			if cmp >= 0 {
+				return newValidationError("exclusiveMaximum", Params{"maximum": max.raw})
 			}
-		} else if floatVal.Cmp(schema.Maximum.Number) <= 0 {
-			return nil
+		} else if cmp > 0 {
+			return newValidationError("maximum", Params{"maximum": max.raw})
 		}
 	}
 
-	if schema.ExclusiveMaximum != nil && schema.ExclusiveMaximum.Number != nil {
-		if floatVal.Cmp(schema.ExclusiveMaximum.Number) < 0 {
-			return nil
-		}
+	if max := schema.plan.exclusiveMaximum; max != nil && max.compare(value) >= 0 {
+		return newValidationError("exclusiveMaximum", Params{"maximum": max.raw})
 	}
 
-	return errors.New("value is more than maximum")
+	return nil
 }
 
 func validateMinimum(value []byte, vt ValueType, schema *Schema) error {
@@ -535,28 +528,23 @@ func validateMinimum(value []byte, vt ValueType, schema *Schema) error {
 		return nil
 	}
 
-	floatVal, ok := new(big.Float).SetString(string(value))
-	if !ok {
-		return errors.New("invalid float value")
-	}
-
-	if schema.Minimum != nil && schema.Minimum.Number != nil {
+	if min := schema.plan.minimum; min != nil {
+		cmp := min.compare(value)
+		// Draft 4 makes minimum exclusive with a boolean exclusiveMinimum
 		if schema.ExclusiveMinimum != nil && schema.ExclusiveMinimum.Boolean != nil && *schema.ExclusiveMinimum.Boolean {
-			if floatVal.Cmp(schema.Minimum.Number) > 0 {
-				return nil
+			if cmp <= 0 {
+				return newValidationError("exclusiveMinimum", Params{"minimum": min.raw})
 			}
-		} else if floatVal.Cmp(schema.Minimum.Number) >= 0 {
-			return nil
+		} else if cmp < 0 {
+			return newValidationError("minimum", Params{"minimum": min.raw})
 		}
 	}
 
-	if schema.ExclusiveMinimum != nil && schema.ExclusiveMinimum.Number != nil {
-		if floatVal.Cmp(schema.ExclusiveMinimum.Number) > 0 {
-			return nil
-		}
+	if min := schema.plan.exclusiveMinimum; min != nil && min.compare(value) <= 0 {
+		return newValidationError("exclusiveMinimum", Params{"minimum": min.raw})
 	}
 
-	return errors.New("value is more than minimum")
+	return nil
 }
 
 func validateMaxLength(value []byte, vt ValueType, schema *Schema) error {
@@ -565,7 +553,7 @@ func validateMaxLength(value []byte, vt ValueType, schema *Schema) error {
 		return nil
 	}
 	if utf8.RuneCount(value) > int(*schema.MaxLength) {
-		return fmt.Errorf("length of value is more than %d", *schema.MaxLength)
+		return newValidationError("maxLength", Params{"maxLength": *schema.MaxLength})
 	}
 	return nil
 }
@@ -576,55 +564,33 @@ func validateMinLength(value []byte, vt ValueType, schema *Schema) error {
 		return nil
 	}
 	if utf8.RuneCount(value) < int(*schema.MinLength) {
-		return fmt.Errorf("length of value is less than %d", *schema.MinLength)
+		return newValidationError("minLength", Params{"minLength": *schema.MinLength})
 	}
 	return nil
 }
 
 func validateEnum(value []byte, vt ValueType, schema *Schema) error {
-	val, err := NewValue(value, vt.ParserValueType())
+	canonical, err := canonicalValue(value, vt)
 	if err != nil {
 		return err
 	}
 
-	for _, e := range *schema.Enum {
-		if e.Equal(val) {
-			return nil
-		}
+	if !schema.plan.enum.contains(canonical) {
+		return newValidationError("enum", nil)
 	}
-	return errors.New("value is not part of the enum set")
+	return nil
 }
 
 func validateConst(value []byte, vt ValueType, schema *Schema) error {
-	if vt == Integer || vt == Number {
-		if schema.Const.valueType == Integer || schema.Const.valueType == Number {
-			floatVal, _ := new(big.Float).SetString(string(value))
-			if schema.Const.Number != nil && floatVal.Cmp(schema.Const.Number) == 0 {
-				return nil
-			}
-		}
-	}
-
-	if vt != schema.Const.valueType {
-		return errors.New("value type doesn't match const value type in schema")
-	}
-
-	if vt == Object || vt == Array {
-		buf := &bytes.Buffer{}
-		json.Compact(buf, value)
-		value = buf.Bytes()
-		value = sortObject(value)
-
-	} else if vt == String {
-		if schema.Const.String != nil && *schema.Const.String == string(value) {
-			return nil
-		}
+	canonical, err := canonicalValue(value, vt)
+	if err != nil {
+		return err
 	}
 
-	if bytes.Equal(value, schema.Const.raw) {
-		return nil
+	if !bytes.Equal(canonical, schema.plan.constant) {
+		return newValidationError("const", nil)
 	}
-	return errors.New("values does not match const")
+	return nil
 }
 
 func validateIf(value []byte, vt ValueType, schema *Schema) error {
@@ -648,10 +614,10 @@ func validateIf(value []byte, vt ValueType, schema *Schema) error {
 }
 
 var reHostname = regexp.MustCompile(`^(?:[a-z0-9]{0,63}|[a-z0-9][a-z0-9\-]{0,61}[a-z0-9])(?:\.(?:[\pL\pN\-]{0,63}|[a-z0-9][a-z0-9\-]{0,61}[a-z0-9]))*?$`)
-var reNonEscapedJSONPointerChars = regexp.MustCompile(`~(?:[^0-1]|$)`)
 var reCurlyBracketsMatch = regexp.MustCompile(`(?:{\w+.*?})*`)
 var reDuration = regexp.MustCompile(`^P(?:\d+W|(?:\d+Y){0,1}(?:\d+M){0,1}(?:\d+D){0,1}(?:T(?:\d+H){0,1}(?:\d+M){0,1}(?:\d+S){0,1}){0,1})$`)
 var reUUID = regexp.MustCompile(`^[a-f0-9]{8}-[a-f0-9]{4}-[a-f0-9]{4}-[a-f0-9]{4}-[a-f0-9]{12}$`)
+var errUnknownFormat = errors.New("unknown format")
 
 func validateFormat(value []byte, vt ValueType, schema *Schema) error {
 	// Ignore anything that is not a string
@@ -659,11 +625,32 @@ func validateFormat(value []byte, vt ValueType, schema *Schema) error {
 		return nil
 	}
 
+	root := schema
+	if schema.root != nil {
+		root = schema.root
+	}
+
+	var err error
+	if check, ok := root.formats[*schema.Format]; ok {
+		err = check(string(value))
+	} else {
+		err = checkFormat(value, *schema.Format)
+	}
+	if err == errUnknownFormat {
+		return newValidationError("unknownFormat", Params{"format": *schema.Format})
+	} else if err != nil {
+		return newValidationError("format", Params{"format": *schema.Format})
+	}
+	return nil
+}
+
+// checkFormat returns why value isn't valid in the format
+func checkFormat(value []byte, format string) error {
 	// Parse takes a layout string, which defines the format by showing how the reference time,
 	// should be interpreted. The reference time is:
 	// Mon Jan 2 15:04:05 -0700 MST 2006
 
-	switch *schema.Format {
+	switch format {
 
 	case "date-time":
 		// Date and time together, for example, 2006-01-02T15:04:05-07:00.
@@ -827,23 +814,12 @@ func validateFormat(value []byte, vt ValueType, schema *Schema) error {
 
 	case "json-pointer":
 		// A JSON Pointer, according to RFC6901.
-		if len(value) > 0 && string(value[:1]) != "/" {
-			return errors.New("value should start with / or be empty")
-		}
-		if reNonEscapedJSONPointerChars.Match(value) {
-			return errors.New("value contains unescaped ~")
-		}
-		pointer := url.QueryEscape(string(value))
-		_, err := url.Parse(pointer)
+		_, err := jsonpointer.Parse(string(value))
 		return err
 
 	case "relative-json-pointer":
 		// A relative JSON pointer.
-		if len(value) > 0 && string(value[:1]) == "/" {
-			return errors.New("value should not start with /")
-		}
-		pointer := url.QueryEscape(string(value))
-		_, err := url.Parse(pointer)
+		_, err := jsonpointer.ParseRelative(string(value))
 		return err
 
 	case "regex":
@@ -852,6 +828,6 @@ func validateFormat(value []byte, vt ValueType, schema *Schema) error {
 		return err
 
 	default:
-		return fmt.Errorf("unknown format: %s", *schema.Format)
+		return errUnknownFormat
 	}
 }
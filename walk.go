@@ -0,0 +1,174 @@
+package jsonschema
+
+import (
+	"errors"
+	"sort"
+	"strconv"
+)
+
+// WalkFunc is called by Walk for every schema, with the JSON Pointer to the schema from the root,
+// e.g. /properties/name, and the schema it was found in. The parent of the root is nil.
+//
+// Returning SkipSchema skips the sub schemas of s, and any other error stops the walk.
+type WalkFunc func(ptr string, s *Schema, parent *Schema) error
+
+// SkipSchema is returned by a WalkFunc to skip the sub schemas of a schema
+var SkipSchema = errors.New("skip the sub schemas of this schema")
+
+// WalkOptions controls how Walk traverses a schema
+type WalkOptions struct {
+	// FollowRefs walks the schemas $refs point to, as if they were sub schemas under /$ref.
+	// A $ref back to a schema, that is already being walked, isn't followed again.
+	FollowRefs bool
+}
+
+// Walk calls fn for the schema and every sub schema in it, depth first and in the order of the
+// keywords in the schema struct. $refs aren't followed.
+func Walk(schema *Schema, fn WalkFunc) error {
+	return WalkWithOptions(schema, WalkOptions{}, fn)
+}
+
+// WalkWithOptions is Walk with options
+func WalkWithOptions(schema *Schema, opts WalkOptions, fn WalkFunc) error {
+	w := &walker{opts: opts, fn: fn, walking: map[*Schema]bool{}}
+	return w.walk("", schema, nil)
+}
+
+type walker struct {
+	opts WalkOptions
+	fn   WalkFunc
+
+	// walking holds the schemas, that are being walked, to not follow $refs in circles
+	walking map[*Schema]bool
+}
+
+func (w *walker) walk(ptr string, s, parent *Schema) error {
+	if s == nil {
+		return nil
+	}
+
+	if err := w.fn(ptr, s, parent); err == SkipSchema {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	w.walking[s] = true
+	defer delete(w.walking, s)
+
+	if s.Ref != nil && w.opts.FollowRefs {
+		target, err := s.ResolveRef(s.Ref)
+		if err != nil {
+			return err
+		}
+		if !w.walking[target] {
+			if err := w.walk(ptr+"/$ref", target, s); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, field := range []struct {
+		keyword string
+		props   *Properties
+	}{
+		{"definitions", s.Definitions},
+		{"$defs", s.Defs},
+		{"properties", s.Properties},
+		{"patternProperties", s.PatternProperties},
+	} {
+		if field.props == nil {
+			continue
+		}
+		for _, prop := range *field.props {
+			if err := w.walk(ptr+"/"+field.keyword+"/"+escapePointerToken(prop.Name), prop.Property, s); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := w.walk(ptr+"/additionalProperties", s.AdditionalProperties, s); err != nil {
+		return err
+	}
+
+	if s.Dependencies != nil {
+		keys := make([]string, 0, len(*s.Dependencies))
+		for key := range *s.Dependencies {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			if dep := (*s.Dependencies)[key]; dep != nil {
+				if err := w.walk(ptr+"/dependencies/"+escapePointerToken(key), dep.Schema, s); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	if err := w.walk(ptr+"/propertyNames", s.PropertyNames, s); err != nil {
+		return err
+	}
+
+	if s.Items != nil {
+		if err := w.walk(ptr+"/items", s.Items.Schema, s); err != nil {
+			return err
+		}
+		if err := w.walkSchemas(ptr+"/items", s.Items.Schemas, s); err != nil {
+			return err
+		}
+	}
+
+	for _, field := range []struct {
+		keyword string
+		schema  *Schema
+	}{
+		{"additionalItems", s.AdditionalItems},
+		{"contains", s.Contains},
+	} {
+		if err := w.walk(ptr+"/"+field.keyword, field.schema, s); err != nil {
+			return err
+		}
+	}
+
+	for _, field := range []struct {
+		keyword string
+		schemas *Schemas
+	}{
+		{"allOf", s.AllOf},
+		{"anyOf", s.AnyOf},
+		{"oneOf", s.OneOf},
+	} {
+		if err := w.walkSchemas(ptr+"/"+field.keyword, field.schemas, s); err != nil {
+			return err
+		}
+	}
+
+	for _, field := range []struct {
+		keyword string
+		schema  *Schema
+	}{
+		{"not", s.Not},
+		{"if", s.If},
+		{"then", s.Then},
+		{"else", s.Else},
+	} {
+		if err := w.walk(ptr+"/"+field.keyword, field.schema, s); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (w *walker) walkSchemas(ptr string, schemas *Schemas, parent *Schema) error {
+	if schemas == nil {
+		return nil
+	}
+	for i, sub := range *schemas {
+		if err := w.walk(ptr+"/"+strconv.Itoa(i), sub, parent); err != nil {
+			return err
+		}
+	}
+	return nil
+}
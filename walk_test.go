@@ -0,0 +1,132 @@
+package jsonschema
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+var walkSchema = `{
+	"definitions": {"node": {"properties": {"children": {"items": {"$ref": "#/definitions/node"}}}}},
+	"properties": {
+		"a/b": {"type": "string"},
+		"tree": {"$ref": "#/definitions/node"}
+	},
+	"patternProperties": {"^x-": true},
+	"additionalProperties": false,
+	"dependencies": {"b": {"required": ["c"]}, "a": ["b"]},
+	"propertyNames": {"maxLength": 10},
+	"items": [{"type": "integer"}, {"not": {"const": 1}}],
+	"additionalItems": {"type": "string"},
+	"contains": {"minimum": 1},
+	"anyOf": [{"if": {"minimum": 0}, "then": {"maximum": 5}, "else": {"maximum": 10}}]
+}`
+
+func TestWalk(t *testing.T) {
+	schema, err := NewFromString(walkSchema)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ptrs := []string{}
+	err = Walk(schema, func(ptr string, s, parent *Schema) error {
+		if ptr == "" && parent != nil {
+			t.Fatalf("expected the root to have no parent")
+		}
+		ptrs = append(ptrs, ptr)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{
+		"",
+		"/definitions/node",
+		"/definitions/node/properties/children",
+		"/definitions/node/properties/children/items",
+		"/properties/a~1b",
+		"/properties/tree",
+		"/patternProperties/^x-",
+		"/additionalProperties",
+		"/dependencies/b",
+		"/propertyNames",
+		"/items/0",
+		"/items/1",
+		"/items/1/not",
+		"/additionalItems",
+		"/contains",
+		"/anyOf/0",
+		"/anyOf/0/if",
+		"/anyOf/0/then",
+		"/anyOf/0/else",
+	}
+	if !reflect.DeepEqual(ptrs, expected) {
+		t.Fatalf("expected:\n%v\ngot:\n%v", expected, ptrs)
+	}
+}
+
+func TestWalkFollowRefs(t *testing.T) {
+	schema, err := NewFromString(`{
+		"definitions": {"node": {"properties": {"children": {"items": {"$ref": "#/definitions/node"}}}}},
+		"properties": {"tree": {"$ref": "#/definitions/node"}}
+	}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ptrs := []string{}
+	err = WalkWithOptions(schema, WalkOptions{FollowRefs: true}, func(ptr string, s, parent *Schema) error {
+		ptrs = append(ptrs, ptr)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{
+		"",
+		"/definitions/node",
+		"/definitions/node/properties/children",
+		"/definitions/node/properties/children/items",
+		"/properties/tree",
+		"/properties/tree/$ref",
+		"/properties/tree/$ref/properties/children",
+		"/properties/tree/$ref/properties/children/items",
+	}
+	if !reflect.DeepEqual(ptrs, expected) {
+		t.Fatalf("expected:\n%v\ngot:\n%v", expected, ptrs)
+	}
+}
+
+func TestWalkSkipAndStop(t *testing.T) {
+	schema, err := NewFromString(walkSchema)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	count := 0
+	err = Walk(schema, func(ptr string, s, parent *Schema) error {
+		count++
+		if ptr == "/definitions/node" || ptr == "/anyOf/0" {
+			return SkipSchema
+		}
+		return nil
+	})
+	if err != nil || count != 14 {
+		t.Fatalf("expected 14 schemas to be visited, got: %d (%v)", count, err)
+	}
+
+	stop := errors.New("stop")
+	count = 0
+	err = Walk(schema, func(ptr string, s, parent *Schema) error {
+		count++
+		if ptr == "/properties/tree" {
+			return stop
+		}
+		return nil
+	})
+	if err != stop || count != 6 {
+		t.Fatalf("expected the walk to stop after 6 schemas, got: %d (%v)", count, err)
+	}
+}
@@ -0,0 +1,295 @@
+package jsonschema
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// maxYAMLValues limits the number of values a YAML document may expand to through aliases
+const maxYAMLValues = 1000000
+
+var yamlDecimalInt = regexp.MustCompile(`^[-+]?[0-9]+$`)
+
+// yamlPosition is the position of a value in a YAML document
+type yamlPosition struct {
+	line, column int
+}
+
+type yamlConverter struct {
+	buf bytes.Buffer
+
+	// positions holds the position of every value, by its JSON Pointer
+	positions map[string]yamlPosition
+
+	// expanding holds the anchored nodes currently being converted, to detect cycles
+	expanding map[*yaml.Node]bool
+	values    int
+}
+
+// NewFromYAML parses a schema written in YAML
+func NewFromYAML(schema []byte) (*Schema, error) {
+	doc, _, err := yamlToJSON(schema)
+	if err != nil {
+		return nil, err
+	}
+	return New(doc)
+}
+
+// ValidateYAML validates a YAML document against the schema.
+// The document is converted to the JSON data model first, so it must only have string keys.
+// Errors are ValidationErrors with the line and column of the invalid values in the YAML document.
+func (s *Schema) ValidateYAML(doc []byte) (bool, error) {
+	jsonDoc, positions, err := yamlToJSON(doc)
+	if err != nil {
+		return false, err
+	}
+
+	valid, err := s.Validate(jsonDoc)
+	if err == nil {
+		return valid, nil
+	}
+
+	errs := Errors(err)
+	for _, e := range errs {
+		// The offset is in the document converted to JSON, which the caller doesn't have
+		e.Offset = -1
+		e.Line, e.Column = 0, 0
+
+		// Errors without a known position, e.g. a missing property, are reported at the parent
+		ptr := e.InstancePointer
+		for {
+			if pos, ok := positions[ptr]; ok {
+				e.Line, e.Column = pos.line, pos.column
+				break
+			}
+			idx := strings.LastIndex(ptr, "/")
+			if idx < 0 {
+				break
+			}
+			ptr = ptr[:idx]
+		}
+	}
+	if len(errs) == 1 {
+		return false, errs[0]
+	}
+	return false, errs
+}
+
+// yamlToJSON converts the first document in a YAML 1.2 stream to JSON, and returns the position
+// of every value by its JSON Pointer
+func yamlToJSON(data []byte) ([]byte, map[string]yamlPosition, error) {
+	root := &yaml.Node{}
+	if err := yaml.Unmarshal(data, root); err != nil {
+		return nil, nil, err
+	}
+
+	c := &yamlConverter{positions: map[string]yamlPosition{}, expanding: map[*yaml.Node]bool{}}
+	if root.Kind == 0 {
+		// An empty document
+		return []byte{}, c.positions, nil
+	}
+
+	if err := c.convert(root, ""); err != nil {
+		return nil, nil, err
+	}
+	return c.buf.Bytes(), c.positions, nil
+}
+
+func (c *yamlConverter) convert(n *yaml.Node, ptr string) error {
+	c.values++
+	if c.values > maxYAMLValues {
+		return errors.New("the YAML document expands to too many values")
+	}
+
+	if _, ok := c.positions[ptr]; !ok {
+		c.positions[ptr] = yamlPosition{line: n.Line, column: n.Column}
+	}
+
+	switch n.Kind {
+	case yaml.DocumentNode:
+		if len(n.Content) == 0 {
+			return nil
+		}
+		return c.convert(n.Content[0], ptr)
+
+	case yaml.AliasNode:
+		if c.expanding[n.Alias] {
+			return fmt.Errorf(positionFormat+"alias *%s creates a cycle", n.Line, n.Column, n.Value)
+		}
+		c.expanding[n.Alias] = true
+		defer delete(c.expanding, n.Alias)
+		return c.convert(n.Alias, ptr)
+
+	case yaml.SequenceNode:
+		if n.Anchor != "" {
+			c.expanding[n] = true
+			defer delete(c.expanding, n)
+		}
+		c.buf.WriteByte('[')
+		for i, item := range n.Content {
+			if i > 0 {
+				c.buf.WriteByte(',')
+			}
+			if err := c.convert(item, ptr+"/"+strconv.Itoa(i)); err != nil {
+				return err
+			}
+		}
+		c.buf.WriteByte(']')
+		return nil
+
+	case yaml.MappingNode:
+		if n.Anchor != "" {
+			c.expanding[n] = true
+			defer delete(c.expanding, n)
+		}
+		members, err := c.members(n)
+		if err != nil {
+			return err
+		}
+		c.buf.WriteByte('{')
+		for i, m := range members {
+			if i > 0 {
+				c.buf.WriteByte(',')
+			}
+			c.buf.WriteByte('"')
+			c.buf.Write(escapeString(m.key))
+			c.buf.WriteString(`":`)
+			if err := c.convert(m.value, ptr+"/"+escapePointerToken(m.key)); err != nil {
+				return err
+			}
+		}
+		c.buf.WriteByte('}')
+		return nil
+
+	case yaml.ScalarNode:
+		return c.scalar(n)
+
+	default:
+		return fmt.Errorf(positionFormat+"unsupported YAML node", n.Line, n.Column)
+	}
+}
+
+type yamlMember struct {
+	key   string
+	value *yaml.Node
+}
+
+// members returns the keys and values of a mapping, with merge keys (<<) expanded
+func (c *yamlConverter) members(n *yaml.Node) ([]yamlMember, error) {
+	members := []yamlMember{}
+	seen := map[string]bool{}
+	merged := []*yaml.Node{}
+
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		key, value := n.Content[i], n.Content[i+1]
+
+		if key.Kind == yaml.ScalarNode && key.ShortTag() == "!!merge" {
+			merged = append(merged, value)
+			continue
+		}
+		if key.Kind != yaml.ScalarNode || key.ShortTag() != "!!str" {
+			return nil, fmt.Errorf(positionFormat+"only string keys are supported, got: %s", key.Line, key.Column, key.Value)
+		}
+		if seen[key.Value] {
+			return nil, fmt.Errorf(positionFormat+"duplicate key: %s", key.Line, key.Column, key.Value)
+		}
+		seen[key.Value] = true
+		members = append(members, yamlMember{key: key.Value, value: value})
+	}
+
+	// Keys of the mapping itself override merged keys, and earlier merges override later ones
+	for _, value := range merged {
+		if value.Kind == yaml.AliasNode {
+			value = value.Alias
+		}
+		sources := []*yaml.Node{value}
+		if value.Kind == yaml.SequenceNode {
+			sources = value.Content
+		}
+		for _, source := range sources {
+			if source.Kind == yaml.AliasNode {
+				source = source.Alias
+			}
+			if source.Kind != yaml.MappingNode {
+				return nil, fmt.Errorf(positionFormat+"only mappings can be merged", source.Line, source.Column)
+			}
+			if c.expanding[source] {
+				return nil, fmt.Errorf(positionFormat+"merge creates a cycle", source.Line, source.Column)
+			}
+			sourceMembers, err := c.members(source)
+			if err != nil {
+				return nil, err
+			}
+			for _, m := range sourceMembers {
+				if !seen[m.key] {
+					seen[m.key] = true
+					members = append(members, m)
+				}
+			}
+		}
+	}
+
+	return members, nil
+}
+
+// scalar writes a scalar as JSON, using the YAML 1.2 core schema for untagged values
+func (c *yamlConverter) scalar(n *yaml.Node) error {
+	switch n.ShortTag() {
+	case "!!null":
+		c.buf.Write(nullLiteral)
+
+	case "!!bool":
+		var b bool
+		if err := n.Decode(&b); err != nil {
+			return fmt.Errorf(positionFormat+"%w", n.Line, n.Column, err)
+		}
+		c.buf.WriteString(strconv.FormatBool(b))
+
+	case "!!int":
+		value := strings.ReplaceAll(n.Value, "_", "")
+		i, ok := new(big.Int), false
+		if yamlDecimalInt.MatchString(value) {
+			// Leading zeros don't make a YAML 1.2 integer octal
+			_, ok = i.SetString(strings.TrimPrefix(value, "+"), 10)
+		} else {
+			_, ok = i.SetString(value, 0)
+		}
+		if !ok {
+			return fmt.Errorf(positionFormat+"invalid integer: %s", n.Line, n.Column, n.Value)
+		}
+		c.buf.WriteString(i.String())
+
+	case "!!float":
+		if num := strings.TrimPrefix(n.Value, "+"); isJSONNumber(num) {
+			c.buf.WriteString(num)
+			break
+		}
+		f, err := strconv.ParseFloat(strings.ReplaceAll(n.Value, "_", ""), 64)
+		if err != nil || math.IsNaN(f) || math.IsInf(f, 0) {
+			return fmt.Errorf(positionFormat+"%s can't be represented in JSON", n.Line, n.Column, n.Value)
+		}
+		c.buf.WriteString(strconv.FormatFloat(f, 'g', -1, 64))
+
+	default:
+		// Strings, timestamps, binary and custom tags are all kept as strings
+		c.buf.WriteByte('"')
+		c.buf.Write(escapeString(n.Value))
+		c.buf.WriteByte('"')
+	}
+
+	return nil
+}
+
+var jsonNumber = regexp.MustCompile(`^-?(0|[1-9][0-9]*)(\.[0-9]+)?([eE][-+]?[0-9]+)?$`)
+
+func isJSONNumber(str string) bool {
+	return jsonNumber.MatchString(str)
+}
@@ -0,0 +1,115 @@
+package jsonschema
+
+import (
+	"testing"
+)
+
+var yamlSchema = `
+type: object
+required: [name, ports]
+properties:
+  name:
+    type: string
+  replicas:
+    type: integer
+    minimum: 1
+  ports:
+    type: array
+    items:
+      type: integer
+      maximum: 65535
+`
+
+var validateYAMLTests = []struct {
+	doc      string
+	valid    bool
+	expected []string
+}{
+	{
+		doc:   "name: web\nreplicas: 3\nports: [80, 0x1bb]\n",
+		valid: true,
+	},
+	{
+		doc:      "name: web\nreplicas: 1\nports:\n  - 80\n  - 70000\n",
+		expected: []string{"line 5, column 5: value is more than the maximum of 65535"},
+	},
+	{
+		doc: "defaults: &defaults\n  name: 1\nservice:\n  <<: *defaults\n",
+		expected: []string{
+			"line 1, column 1: required property name is missing",
+			"line 1, column 1: required property ports is missing",
+		},
+	},
+}
+
+func TestValidateYAML(t *testing.T) {
+	schema, err := NewFromYAML([]byte(yamlSchema))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i, tt := range validateYAMLTests {
+		valid, err := schema.ValidateYAML([]byte(tt.doc))
+		if valid != tt.valid {
+			t.Fatalf("test #%d: expected valid to be %t, got: %t (%v)", i+1, tt.valid, valid, err)
+		}
+		if tt.valid {
+			continue
+		}
+
+		errs := Errors(err)
+		if len(errs) != len(tt.expected) {
+			t.Fatalf("test #%d: expected %d errors, got: %v", i+1, len(tt.expected), err)
+		}
+		for j, e := range errs {
+			if e.Error() != tt.expected[j] {
+				t.Fatalf("test #%d: expected error:\n%s\ngot:\n%s", i+1, tt.expected[j], e.Error())
+			}
+		}
+	}
+}
+
+func TestValidateYAMLPointers(t *testing.T) {
+	schema, err := NewFromYAML([]byte(yamlSchema))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = schema.ValidateYAML([]byte("name: web\nports: [80, 70000]\n"))
+	errs := Errors(err)
+	if len(errs) != 1 || errs[0].InstancePointer != "/ports/1" || errs[0].Line != 2 || errs[0].Column != 13 {
+		t.Fatalf("expected an error at /ports/1 on line 2, column 13, got: %#v", errs)
+	}
+}
+
+var yamlToJSONTests = []struct {
+	yaml     string
+	expected string
+}{
+	{yaml: "a: 0x1F\nb: 007\nc: .5\nd: +1.5e3\ne: yes\nf: ~\ng: 2001-12-14\nh: 1_000\n", expected: `{"a":31,"b":7,"c":0.5,"d":1.5e3,"e":"yes","f":null,"g":"2001-12-14","h":1000}`},
+	{yaml: "base: &b {x: 1, y: 2}\nc:\n  <<: *b\n  y: 3\n", expected: `{"base":{"x":1,"y":2},"c":{"y":3,"x":1}}`},
+	{yaml: "- 'quoted: \"string\"'\n- 12345678901234567890\n", expected: `["quoted: \"string\"",12345678901234567890]`},
+	{yaml: "1: a\n"},
+	{yaml: "a: 1\na: 2\n"},
+	{yaml: "a: &x [1, *x]\n"},
+	{yaml: "a: &x\n  b: *x\n"},
+	{yaml: "a: .nan\n"},
+}
+
+func TestYAMLToJSON(t *testing.T) {
+	for i, tt := range yamlToJSONTests {
+		doc, _, err := yamlToJSON([]byte(tt.yaml))
+		if tt.expected == "" {
+			if err == nil {
+				t.Fatalf("test #%d: expected an error, got: %s", i+1, doc)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("test #%d: %s", i+1, err)
+		}
+		if string(doc) != tt.expected {
+			t.Fatalf("test #%d: expected:\n%s\ngot:\n%s", i+1, tt.expected, doc)
+		}
+	}
+}